@@ -101,7 +101,7 @@ function Dashboard() {
       );
 
       const fetchedFeeds = feedsResponse.data || [];
-      const fetchedPosts = postsResponse.data || [];
+      const fetchedPosts = postsResponse.data?.posts || [];
       const starredFeedIds = (starredResponse.data || []).map(
         (feed) => feed.id
       );
@@ -153,7 +153,7 @@ function Dashboard() {
     return () => clearInterval(intervalId);
   }, [userSettings.autoRefresh, userSettings.refreshInterval]);
 
-  const handleSearch = (value) => {
+  const handleSearch = async (value = searchTerm) => {
     setSearchTerm(value);
 
     if (value.trim() === "") {
@@ -170,13 +170,24 @@ function Dashboard() {
       );
       setFilteredFeeds(matchingFeeds);
 
-      const matchingPosts = allPosts.filter(
-        (post) =>
-          post.title.toLowerCase().includes(lowercaseTerm) ||
-          (post.description &&
-            post.description.toLowerCase().includes(lowercaseTerm))
-      );
+      let matchingPosts;
+      try {
+        const searchResponse = await api.get("/search", {
+          params: { q: value, limit: 100 },
+        });
+        matchingPosts = searchResponse.data?.posts || [];
+      } catch (err) {
+        console.error("Search failed, filtering loaded posts instead", err);
+        matchingPosts = allPosts.filter(
+          (post) =>
+            post.title.toLowerCase().includes(lowercaseTerm) ||
+            (post.description &&
+              post.description.toLowerCase().includes(lowercaseTerm))
+        );
+      }
       setFilteredPosts(matchingPosts);
+      setTotalPosts(matchingPosts.length);
+      setCurrentPage(1);
       setPosts(matchingPosts.slice(0, postsPerPage));
     }
   };
@@ -59,18 +59,18 @@ function FeedDetail() {
         }
 
         const postsResponse = await api.get(`/posts?feed_id=${feedId}`);
-        let feedPosts = postsResponse.data;
+        let feedPosts = postsResponse.data?.posts;
         if (!feedPosts || feedPosts.length === 0 || !Array.isArray(feedPosts)) {
           console.log(
             "No posts returned or invalid response format, checking complete posts list"
           );
           const allPostsResponse = await api.get("/posts");
 
-          feedPosts = allPostsResponse.data.filter(
+          feedPosts = allPostsResponse.data.posts.filter(
             (post) => post.feed_id === feedId
           );
           console.log(
-            `Filtered ${allPostsResponse.data.length} total posts to ${feedPosts.length} for feed ${feedId}`
+            `Filtered ${allPostsResponse.data.posts.length} total posts to ${feedPosts.length} for feed ${feedId}`
           );
         }
 
@@ -242,7 +242,7 @@ function FeedDetail() {
                         api
                           .get(`/posts?feed_id=${feedId}`)
                           .then((res) => {
-                            const refreshedPosts = res.data.filter(
+                            const refreshedPosts = res.data.posts.filter(
                               (post) => post.feed_id === feedId
                             );
                             setAllPosts(refreshedPosts || []);
@@ -34,7 +34,7 @@ function UserProfile() {
 
         setStats({
           totalFeeds: feedsResponse.data?.length || 0,
-          totalPosts: postsResponse.data?.length || 0,
+          totalPosts: postsResponse.data?.posts?.length || 0,
         });
 
         setError(null);
@@ -109,7 +109,7 @@ export const checkForNewArticles = async () => {
     // Get new articles
     const now = Date.now();
     const postsResp = await api.get("/posts");
-    const posts = postsResp.data || [];
+    const posts = postsResp.data?.posts || [];
 
     // Filter by starred feeds and new articles since last check
     const newArticles = posts.filter((article) => {
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/Sreenesh123/rssagg/internal/secrets"
+)
+
+const (
+	BackfillPending = "pending"
+	BackfillRunning = "running"
+	BackfillDone    = "done"
+	BackfillFailed  = "failed"
+)
+
+// backfillBatchSize is how many backfills advance by one page per tick, so a
+// long archive never crowds out regular collection or hammers one host.
+const backfillBatchSize = 10
+
+// startBackfilling walks RFC 5005 paged and archived feeds back through their
+// history, one page per backfill per tick.
+func startBackfilling(db *database.Queries, credentials *secrets.Box, pipeline ItemPipeline, timeBetweenPages time.Duration) {
+	log.Printf("Backfilling feed archives every %s...", timeBetweenPages)
+	ticker := time.NewTicker(timeBetweenPages)
+
+	for ; ; <-ticker.C {
+		backfills, err := db.GetActiveFeedBackfills(context.Background(), backfillBatchSize)
+		if err != nil {
+			log.Println("Couldn't get feed backfills", err)
+			continue
+		}
+		for _, backfill := range backfills {
+			backfillPage(db, credentials, pipeline, backfill)
+		}
+	}
+}
+
+// backfillPage fetches the backfill's next page, stores its items and records
+// where to continue. The first page is the feed document itself, which is
+// only read for its links.
+func backfillPage(db *database.Queries, credentials *secrets.Box, pipeline ItemPipeline, backfill database.FeedBackfill) {
+	feed, err := db.GetFeed(context.Background(), backfill.FeedID)
+	if err != nil {
+		log.Printf("Couldn't load feed %s for backfill: %v", backfill.FeedID, err)
+		return
+	}
+	update := database.UpdateFeedBackfillProgressParams{
+		FeedID:       backfill.FeedID,
+		Status:       BackfillRunning,
+		PagesFetched: backfill.PagesFetched,
+		ItemsFound:   backfill.ItemsFound,
+	}
+	// Only the message is stored, since anyone who can see the feed can read
+	// it; the error is logged. Page URLs come from the feed document, so a
+	// fetch error could describe hosts its publisher pointed us at.
+	fail := func(message string, err error) {
+		log.Printf("Backfill of feed %s failed: %s: %v", feed.Name, message, err)
+		update.Status = BackfillFailed
+		update.Error = sql.NullString{String: message, Valid: true}
+		if err := db.UpdateFeedBackfillProgress(context.Background(), update); err != nil {
+			log.Printf("Couldn't record backfill progress for feed %s: %v", feed.Name, err)
+		}
+	}
+
+	creds, err := loadFeedCredentials(context.Background(), db, credentials, feed.ID)
+	if err != nil {
+		fail("couldn't load feed credentials", err)
+		return
+	}
+	pageURL := feed.Url
+	if backfill.NextUrl.Valid {
+		pageURL = backfill.NextUrl.String
+	}
+	page, err := fetchFeed(pageURL, pageCredentials(feed.Url, pageURL, creds))
+	if err != nil {
+		fail("couldn't fetch page", err)
+		return
+	}
+
+	if backfill.NextUrl.Valid {
+		channelLink := page.Channel.Link
+		if base, err := url.Parse(pageURL); err == nil {
+			if channel, err := base.Parse(strings.TrimSpace(channelLink)); err == nil {
+				channelLink = channel.String()
+			}
+		}
+		items, err := pipeline.process(db, feed, channelLink, page.Channel.Item)
+		if err != nil {
+			fail("couldn't process items", err)
+			return
+		}
+		update.PagesFetched++
+		// savePosts logs the posts it couldn't store; the rest still count.
+		saved, _ := savePosts(db, feed, items, false)
+		update.ItemsFound += int32(saved)
+	}
+	next := nextArchivePage(pageURL, page)
+	if next == "" || next == pageURL || update.PagesFetched >= backfill.MaxPages {
+		update.Status = BackfillDone
+	} else {
+		update.NextUrl = sql.NullString{String: next, Valid: true}
+	}
+	if err := db.UpdateFeedBackfillProgress(context.Background(), update); err != nil {
+		log.Printf("Couldn't record backfill progress for feed %s: %v", feed.Name, err)
+		return
+	}
+	if update.Status == BackfillDone {
+		log.Printf("Backfill of feed %s done, %v pages, %v new posts", feed.Name, update.PagesFetched, update.ItemsFound)
+	}
+}
+
+// pageCredentials returns the feed's credentials for an archive page on the
+// feed's own scheme and host, and none for a page anywhere else.
+func pageCredentials(feedURL, pageURL string, creds *FeedCredentials) *FeedCredentials {
+	feed, err := url.Parse(feedURL)
+	if err != nil {
+		return nil
+	}
+	page, err := url.Parse(pageURL)
+	if err != nil || page.Scheme != feed.Scheme || !strings.EqualFold(page.Host, feed.Host) {
+		return nil
+	}
+	return creds
+}
+
+// nextArchivePage returns the absolute URL of the page holding older items:
+// rel="next" for paged feeds, rel="prev-archive" for archived ones.
+func nextArchivePage(pageURL string, page *RSSFeed) string {
+	href := page.linkWithRel("next", "prev-archive")
+	if href == "" {
+		return ""
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	next, err := base.Parse(href)
+	if err != nil || (next.Scheme != "http" && next.Scheme != "https") {
+		return ""
+	}
+	return next.String()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"testing"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestNextArchivePage(t *testing.T) {
+	doc := []byte(`<?xml version="1.0"?>
+		<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+			<channel>
+				<title>Example</title>
+				<atom:link rel="self" href="https://example.com/feed.xml"/>
+				<link>https://example.com/</link>
+				<atom:link rel="prev-archive" href="/archive/2023.xml"/>
+				<item><title>One</title><link>https://example.com/one</link></item>
+			</channel>
+		</rss>`)
+
+	var feed RSSFeed
+	if err := xml.Unmarshal(doc, &feed); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if feed.Channel.Link != "https://example.com/" {
+		t.Errorf("Expected channel link to survive atom links, got '%s'", feed.Channel.Link)
+	}
+	if next := nextArchivePage("https://example.com/feed.xml", &feed); next != "https://example.com/archive/2023.xml" {
+		t.Errorf("Expected resolved prev-archive link, got '%s'", next)
+	}
+
+	feed.Channel.AtomLinks = append(feed.Channel.AtomLinks, AtomLink{Rel: "next", Href: "https://example.com/feed.xml?page=2"})
+	if next := nextArchivePage("https://example.com/feed.xml", &feed); next != "https://example.com/feed.xml?page=2" {
+		t.Errorf("Expected rel=next to win over prev-archive, got '%s'", next)
+	}
+
+	feed.Channel.AtomLinks = []AtomLink{{Rel: "next", Href: "javascript:alert(1)"}}
+	if next := nextArchivePage("https://example.com/feed.xml", &feed); next != "" {
+		t.Errorf("Expected non-HTTP link to be ignored, got '%s'", next)
+	}
+}
+
+func TestPageCredentials(t *testing.T) {
+	creds := &FeedCredentials{}
+	tests := []struct {
+		pageURL string
+		want    *FeedCredentials
+	}{
+		{"https://example.com/feed.xml?page=2", creds},
+		{"https://EXAMPLE.com/archive/2023.xml", creds},
+		{"https://other.example.com/feed.xml?page=2", nil},
+		{"https://example.com:8443/feed.xml", nil},
+		{"http://example.com/feed.xml?page=2", nil},
+	}
+	for _, tt := range tests {
+		if got := pageCredentials("https://example.com/feed.xml", tt.pageURL, creds); got != tt.want {
+			t.Errorf("pageCredentials(%q) = %v, want %v", tt.pageURL, got, tt.want)
+		}
+	}
+}
+
+func TestBackfillPageStoresGenericError(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Name: "Example", Url: "https://example.com/feed.xml", Kind: FeedKindRSS}
+	cfg, db := newTestConfig(t)
+	db.returns("GetFeed", feed)
+	db.returns("GetFeedCredentials")
+	db.affects("UpdateFeedBackfillProgress", 1)
+
+	// The next page points at an address the fetcher refuses.
+	backfillPage(cfg.DB, nil, ItemPipeline{}, database.FeedBackfill{
+		FeedID:   feed.ID,
+		NextUrl:  sql.NullString{String: "http://127.0.0.1:6379/", Valid: true},
+		MaxPages: 5,
+	})
+	updates := db.called("UpdateFeedBackfillProgress")
+	if len(updates) != 1 || updates[0].Args[1] != BackfillFailed || updates[0].Args[5] != "couldn't fetch page" {
+		t.Errorf("expected a failure without the fetch error, got %+v", updates)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// defaultTrackingParams are stripped from post URLs unless TRACKING_PARAMS
+// overrides them. A trailing * matches any parameter with that prefix.
+var defaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "msclkid", "yclid", "igshid",
+	"mc_cid", "mc_eid", "_hsenc", "_hsmi", "mkt_tok", "ref", "ref_src", "ref_url",
+}
+
+// parseTrackingParams reads a comma separated TRACKING_PARAMS value.
+func parseTrackingParams(value string) []string {
+	params := []string{}
+	for _, param := range strings.Split(value, ",") {
+		if param = strings.ToLower(strings.TrimSpace(param)); param != "" {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+func isTrackingParam(name string, trackingParams []string) bool {
+	name = strings.ToLower(name)
+	for _, param := range trackingParams {
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == param {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalizeURL resolves rawURL against base and normalises it so the
+// variants one story arrives under collapse to a single posts.url: tracking
+// parameters go, scheme and host are lowercased, default ports and trailing
+// slashes are dropped, and http is upgraded when base is https on the same
+// host. Non-web URLs are returned as they are.
+func canonicalizeURL(rawURL string, base *url.URL, trackingParams []string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return u.String()
+	}
+
+	host, port := u.Hostname(), u.Port()
+	host = strings.ToLower(host)
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if u.Scheme == "http" && base != nil && base.Scheme == "https" && strings.EqualFold(base.Hostname(), host) && port == "" {
+		u.Scheme = "https"
+	}
+	if port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	} else {
+		u.Host = host
+	}
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for name := range query {
+			if isTrackingParam(name, trackingParams) {
+				query.Del(name)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	u.ForceQuery = false
+
+	if trimmed := strings.TrimRight(u.Path, "/"); trimmed != "" {
+		u.Path = trimmed
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+	} else {
+		u.Path, u.RawPath = "/", ""
+	}
+	if u.Fragment == "" {
+		u.RawFragment = ""
+	}
+	return u.String()
+}
+
+// canonicalizeItems normalises item links, keeping what the feed gave us in
+// OriginalLink.
+type canonicalizeItems struct {
+	trackingParams []string
+}
+
+func (p canonicalizeItems) Process(ctx ItemContext, item *RSSItem) (bool, error) {
+	if item.OriginalLink == "" {
+		item.OriginalLink = strings.TrimSpace(item.Link)
+	}
+	item.Link = canonicalizeURL(item.Link, ctx.BaseURL, p.trackingParams)
+	return true, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCanonicalizeURL(t *testing.T) {
+	base, _ := url.Parse("https://Example.com/blog/")
+
+	cases := []struct {
+		in, want string
+	}{
+		{"https://example.com/post/?utm_source=rss&utm_medium=feed&id=7", "https://example.com/post?id=7"},
+		{"http://EXAMPLE.com:80/post?fbclid=abc", "https://example.com/post"},
+		{"http://other.example.org/post/", "http://other.example.org/post"},
+		{"/2024/03/hello/?ref=homepage", "https://example.com/2024/03/hello"},
+		{"hello", "https://example.com/blog/hello"},
+		{"https://example.com:8443/a?b=2&a=1#top", "https://example.com:8443/a?a=1&b=2#top"},
+		{"https://example.com", "https://example.com/"},
+		{"mid:abc@mail#feed", "mid:abc@mail#feed"},
+	}
+	for _, c := range cases {
+		if got := canonicalizeURL(c.in, base, defaultTrackingParams); got != c.want {
+			t.Errorf("canonicalizeURL(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestCanonicalizeItemsKeepsOriginal(t *testing.T) {
+	base, _ := url.Parse("https://example.com/")
+	items := ProcessorChain{canonicalizeItems{trackingParams: parseTrackingParams("utm_*, Ref")}}.Run(
+		ItemContext{BaseURL: base},
+		[]RSSItem{{Title: "One", Link: " /one/?REF=x&utm_campaign=y "}},
+	)
+	if items[0].Link != "https://example.com/one" {
+		t.Errorf("Expected canonical link, got '%s'", items[0].Link)
+	}
+	if items[0].OriginalLink != "/one/?REF=x&utm_campaign=y" {
+		t.Errorf("Expected original link to be kept, got '%s'", items[0].OriginalLink)
+	}
+}
+
+func TestExtractFullContent(t *testing.T) {
+	page := []byte(`<html><head>
+		<link rel="canonical" href="/articles/42">
+		</head><body>
+		<nav>Menu</nav>
+		<article><h1>Title</h1><p onclick="x()">Body <script>alert(1)</script>text</p></article>
+		</body></html>`)
+
+	content, canonical, err := extractFullContent("https://example.com/articles/42?utm_source=rss", page, "")
+	if err != nil {
+		t.Fatalf("extractFullContent: %v", err)
+	}
+	if canonical != "https://example.com/articles/42" {
+		t.Errorf("Expected resolved canonical URL, got '%s'", canonical)
+	}
+	if content != "<article><h1>Title</h1><p>Body text</p></article>" {
+		t.Errorf("Expected sanitised article, got '%s'", content)
+	}
+
+	home := []byte(`<html><head><link rel="canonical" href="https://example.com/"></head><body></body></html>`)
+	if _, canonical, _ := extractFullContent("https://example.com/articles/42", home, ""); canonical != "" {
+		t.Errorf("Expected a home page canonical to be ignored, got '%s'", canonical)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// clusterWindow is how far apart two posts can be published and still
+	// cover the same story.
+	clusterWindow            = 48 * time.Hour
+	clusterBatchSize         = 200
+	titleSimilarityThreshold = 0.5
+	// minTitleWords keeps short, generic titles like "Weekly update" from
+	// matching each other.
+	minTitleWords = 4
+)
+
+var titleStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "has": true, "in": true, "is": true, "it": true,
+	"its": true, "of": true, "on": true, "or": true, "that": true, "the": true, "to": true,
+	"was": true, "with": true,
+}
+
+// startClustering groups new posts from different feeds that cover the same
+// story, so timelines can collapse them.
+func startClustering(db *database.Queries, timeBetweenRuns time.Duration) {
+	log.Printf("Clustering posts every %s...", timeBetweenRuns)
+	ticker := time.NewTicker(timeBetweenRuns)
+
+	var after database.Post
+	for ; ; <-ticker.C {
+		after = clusterBatch(db, after)
+	}
+}
+
+// clusterBatch clusters the unclustered posts that come after the post
+// after, oldest first, and returns where the next batch starts. Posts that
+// fail to cluster are passed over rather than fetched again at the head of
+// every batch; once a batch comes back short the next one starts over, so
+// they are retried once per pass.
+func clusterBatch(db *database.Queries, after database.Post) database.Post {
+	params := database.GetUnclusteredPostsParams{Limit: clusterBatchSize}
+	if after.ID != uuid.Nil {
+		params.AfterTime = sql.NullTime{Time: after.CreatedAt, Valid: true}
+		params.AfterID = uuid.NullUUID{UUID: after.ID, Valid: true}
+	}
+	posts, err := db.GetUnclusteredPosts(context.Background(), params)
+	if err != nil {
+		log.Println("Couldn't get posts to cluster", err)
+		return after
+	}
+	clustered, joined := 0, 0
+	for _, post := range posts {
+		clusterID, err := clusterPost(db, post)
+		if err != nil {
+			log.Printf("Couldn't cluster post %s: %v", post.ID, err)
+			continue
+		}
+		clustered++
+		if clusterID != post.ID {
+			joined++
+		}
+	}
+	if len(posts) > 0 {
+		log.Printf("Clustered %v of %v posts, %v joined an existing story", clustered, len(posts), joined)
+	}
+	if len(posts) < clusterBatchSize {
+		return database.Post{}
+	}
+	return posts[len(posts)-1]
+}
+
+// clusterPost puts post in the cluster of the closest matching post from
+// another feed, or starts a new cluster named after itself. Matching on the
+// URL key wins over title similarity.
+func clusterPost(db *database.Queries, post database.Post) (uuid.UUID, error) {
+	key := urlKey(post.Url)
+	shingles := titleShingles(post.Title)
+	published := post.CreatedAt
+	if post.PublishedAt.Valid {
+		published = post.PublishedAt.Time
+	}
+
+	candidates, err := db.GetClusterCandidates(context.Background(), database.GetClusterCandidatesParams{
+		FeedID:        post.FeedID,
+		WindowStart:   published.Add(-clusterWindow),
+		WindowEnd:     published.Add(clusterWindow),
+		UrlKey:        key,
+		TitleShingles: shingles,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	clusterID := post.ID
+	best := titleSimilarityThreshold
+	for _, candidate := range candidates {
+		if candidate.UrlKey == key {
+			clusterID = candidate.ClusterID
+			break
+		}
+		if similarity := jaccard(shingles, candidate.TitleShingles); similarity >= best {
+			best = similarity
+			clusterID = candidate.ClusterID
+		}
+	}
+
+	err = db.CreatePostCluster(context.Background(), database.CreatePostClusterParams{
+		PostID:        post.ID,
+		ClusterID:     clusterID,
+		CreatedAt:     time.Now().UTC(),
+		UrlKey:        key,
+		TitleShingles: shingles,
+	})
+	return clusterID, err
+}
+
+// urlKey is a post URL with the differences that don't change the page
+// removed: scheme, a leading www. and a trailing slash.
+func urlKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return rawURL
+	}
+	key := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") + strings.TrimRight(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+// titleShingles hashes a title's significant words and adjacent word pairs.
+// Titles too short to compare yield no shingles.
+func titleShingles(title string) []int64 {
+	words := []string{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !titleStopWords[word] {
+			words = append(words, word)
+		}
+	}
+	if len(words) < minTitleWords {
+		return []int64{}
+	}
+
+	seen := map[int64]bool{}
+	for i, word := range words {
+		seen[shingleHash(word)] = true
+		if i > 0 {
+			seen[shingleHash(words[i-1]+" "+word)] = true
+		}
+	}
+	shingles := make([]int64, 0, len(seen))
+	for shingle := range seen {
+		shingles = append(shingles, shingle)
+	}
+	sort.Slice(shingles, func(i, j int) bool { return shingles[i] < shingles[j] })
+	return shingles
+}
+
+func shingleHash(s string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return int64(h.Sum64())
+}
+
+// jaccard compares two sorted shingle sets.
+func jaccard(a, b []int64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			shared++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
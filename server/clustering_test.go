@@ -0,0 +1,88 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestTitleSimilarity(t *testing.T) {
+	same := jaccard(
+		titleShingles("Apple announces the new iPhone 16 at September event"),
+		titleShingles("Apple announces iPhone 16 at its September event"),
+	)
+	if same < titleSimilarityThreshold {
+		t.Errorf("Expected reworded headlines to cluster, similarity %.2f", same)
+	}
+
+	different := jaccard(
+		titleShingles("Apple announces the new iPhone 16 at September event"),
+		titleShingles("Google announces Pixel 9 at its August event"),
+	)
+	if different >= titleSimilarityThreshold {
+		t.Errorf("Expected different stories not to cluster, similarity %.2f", different)
+	}
+
+	if shingles := titleShingles("Weekly update"); len(shingles) != 0 {
+		t.Errorf("Expected short titles to have no shingles, got %d", len(shingles))
+	}
+}
+
+func TestURLKey(t *testing.T) {
+	a := urlKey("https://www.Example.com/story/")
+	b := urlKey("http://example.com/story")
+	if a != b {
+		t.Errorf("Expected scheme, www. and trailing slash to be ignored, got '%s' and '%s'", a, b)
+	}
+	if urlKey("https://example.com/story?id=1") == urlKey("https://example.com/story?id=2") {
+		t.Error("Expected query strings to distinguish URLs")
+	}
+}
+
+func TestClusterBatchPassesFailedPosts(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	posts := make([]any, clusterBatchSize)
+	for i := range posts {
+		posts[i] = database.Post{ID: uuid.New(), FeedID: uuid.New(), Title: "Post", Url: "https://example.com/post", CreatedAt: start.Add(time.Duration(i) * time.Second)}
+	}
+	failing := posts[0].(database.Post)
+	last := posts[len(posts)-1].(database.Post)
+
+	cfg, db := newTestConfig(t)
+	db.returns("GetUnclusteredPosts", posts...)
+	db.on("GetClusterCandidates", func(args []driver.Value) fakeResult {
+		if argUUID(t, args[0]) == failing.FeedID {
+			return fakeResult{Err: errors.New("boom")}
+		}
+		return fakeResult{}
+	})
+	db.affects("CreatePostCluster", 1)
+
+	// The first run starts at the oldest post and carries on after its
+	// batch, failed post and all.
+	after := clusterBatch(cfg.DB, database.Post{})
+	if calls := db.called("GetUnclusteredPosts"); calls[0].Args[1] != nil || calls[0].Args[2] != nil {
+		t.Errorf("expected the first batch to start at the oldest post, got %+v", calls[0].Args)
+	}
+	if after.ID != last.ID {
+		t.Fatalf("expected the next batch after %s, got %s", last.ID, after.ID)
+	}
+	if n := len(db.called("CreatePostCluster")); n != clusterBatchSize-1 {
+		t.Errorf("expected %d posts clustered, got %d", clusterBatchSize-1, n)
+	}
+
+	// A short batch ends the pass; the next starts over to retry the failure.
+	db.returns("GetUnclusteredPosts", failing)
+	next := clusterBatch(cfg.DB, after)
+	calls := db.called("GetUnclusteredPosts")
+	if args := calls[1].Args; args[1] != last.CreatedAt || argUUID(t, args[2]) != last.ID {
+		t.Errorf("expected the batch after %s, got %+v", last.ID, args)
+	}
+	if next.ID != uuid.Nil {
+		t.Errorf("expected the next pass to start over, got %s", next.ID)
+	}
+}
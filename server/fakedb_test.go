@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// fakeDB is a database/sql driver for handler tests. Queries are told apart
+// by sqlc's "-- name:" header; each answers with whatever its handler
+// returns, and every call is recorded. A query without a handler fails.
+type fakeDB struct {
+	mu        sync.Mutex
+	handlers  map[string]func(args []driver.Value) fakeResult
+	calls     []fakeCall
+	commits   int
+	rollbacks int
+}
+
+type fakeCall struct {
+	Name  string
+	Query string
+	Args  []driver.Value
+}
+
+// fakeResult is one query's answer: rows for queries, rows affected for
+// execs, or an error for either.
+type fakeResult struct {
+	Rows         [][]driver.Value
+	RowsAffected int64
+	Err          error
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+}
+
+// newTestConfig returns an apiConfig backed by a fresh fakeDB.
+func newTestConfig(t *testing.T) (*apiConfig, *fakeDB) {
+	t.Helper()
+	fake := &fakeDB{handlers: map[string]func([]driver.Value) fakeResult{}}
+	dsn := uuid.NewString()
+	fakeDBsMu.Lock()
+	fakeDBs[dsn] = fake
+	fakeDBsMu.Unlock()
+	conn, err := sql.Open("fakedb", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		fakeDBsMu.Lock()
+		delete(fakeDBs, dsn)
+		fakeDBsMu.Unlock()
+	})
+	return &apiConfig{DB: database.New(conn), DBConn: conn}, fake
+}
+
+// on answers the named query with fn.
+func (db *fakeDB) on(name string, fn func(args []driver.Value) fakeResult) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.handlers[name] = fn
+}
+
+// returns answers the named query with one row per value. Structs become
+// one column per field, in order, as sqlc scans them; anything else is a
+// single column.
+func (db *fakeDB) returns(name string, values ...any) {
+	rows := make([][]driver.Value, len(values))
+	for i, v := range values {
+		rows[i] = fakeRow(v)
+	}
+	db.on(name, func([]driver.Value) fakeResult { return fakeResult{Rows: rows} })
+}
+
+// affects answers the named exec as having changed n rows.
+func (db *fakeDB) affects(name string, n int64) {
+	db.on(name, func([]driver.Value) fakeResult { return fakeResult{RowsAffected: n} })
+}
+
+// called returns the recorded calls of the named query.
+func (db *fakeDB) called(name string) []fakeCall {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var calls []fakeCall
+	for _, call := range db.calls {
+		if call.Name == name {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+var queryNamePattern = regexp.MustCompile(`^-- name: (\w+)`)
+
+func (db *fakeDB) run(query string, args []driver.NamedValue) fakeResult {
+	name := "?"
+	if m := queryNamePattern.FindStringSubmatch(query); m != nil {
+		name = m[1]
+	}
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	db.mu.Lock()
+	db.calls = append(db.calls, fakeCall{Name: name, Query: query, Args: values})
+	fn := db.handlers[name]
+	db.mu.Unlock()
+	if fn == nil {
+		return fakeResult{Err: fmt.Errorf("fakedb: unexpected query %s", name)}
+	}
+	return fn(values)
+}
+
+func fakeRow(v any) []driver.Value {
+	rv := reflect.ValueOf(v)
+	if _, ok := v.(driver.Valuer); ok || rv.Kind() != reflect.Struct || rv.Type() == reflect.TypeOf(time.Time{}) {
+		return []driver.Value{fakeValue(v)}
+	}
+	row := make([]driver.Value, rv.NumField())
+	for i := range row {
+		row[i] = fakeValue(rv.Field(i).Interface())
+	}
+	return row
+}
+
+func fakeValue(v any) driver.Value {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []string:
+		value, _ := pq.Array(v).Value()
+		return value
+	case driver.Valuer:
+		value, err := v.Value()
+		if err != nil {
+			panic(err)
+		}
+		return value
+	}
+	value, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	db := fakeDBs[dsn]
+	if db == nil {
+		return nil, fmt.Errorf("fakedb: unknown database %s", dsn)
+	}
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fakedb: prepared statements aren't supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{c.db}, nil }
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{c.db}, nil
+}
+
+// CheckNamedValue passes arguments through as database/sql converts them,
+// so Valuers such as uuid.UUID and pq.Array arrive as driver values.
+func (c *fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	nv.Value = value
+	return nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result := c.db.run(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	columns := 0
+	if len(result.Rows) > 0 {
+		columns = len(result.Rows[0])
+	}
+	return &fakeRows{columns: columns, rows: result.Rows}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result := c.db.run(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return driver.RowsAffected(result.RowsAffected), nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.commits++
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.rollbacks++
+	return nil
+}
+
+type fakeRows struct {
+	columns int
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	columns := make([]string, r.columns)
+	for i := range columns {
+		columns[i] = fmt.Sprintf("c%d", i)
+	}
+	return columns
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// serveAuthed calls an authenticated handler as user, with chi URL params
+// filled in from params.
+func serveAuthed(handler authedHandler, user database.User, method, target, body string, params map[string]string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler(rec, newParamRequest(method, target, body, params), user)
+	return rec
+}
+
+// servePublic calls a handler that needs no authentication.
+func servePublic(handler http.HandlerFunc, method, target, body string, params map[string]string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler(rec, newParamRequest(method, target, body, params))
+	return rec
+}
+
+func newParamRequest(method, target, body string, params map[string]string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	routeCtx := chi.NewRouteContext()
+	for key, value := range params {
+		routeCtx.URLParams.Add(key, value)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
+
+// argUUID reads a uuid argument as the driver received it.
+func argUUID(t *testing.T, v driver.Value) uuid.UUID {
+	t.Helper()
+	var id uuid.UUID
+	if err := id.Scan(v); err != nil {
+		t.Fatalf("argument %v isn't a uuid: %v", v, err)
+	}
+	return id
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	outputFormatRSS  = "rss"
+	outputFormatAtom = "atom"
+	outputFormatJSON = "json"
+)
+
+// outputFeedSize is how many of the newest posts a served feed carries.
+const outputFeedSize = 50
+
+var outputFormats = []string{outputFormatRSS, outputFormatAtom, outputFormatJSON}
+
+var errUnknownOutputFormat = errors.New("unknown feed format")
+
+// outputFeed is a list of posts to serve as RSS 2.0, Atom or JSON Feed.
+// FeedURL is the feed's own URL in the format being served.
+type outputFeed struct {
+	ID          uuid.UUID
+	Title       string
+	Description string
+	HomeURL     string
+	FeedURL     string
+	Author      string
+	Updated     time.Time
+	Items       []outputItem
+}
+
+// outputItem is one post of an outputFeed. Content is HTML; Source and
+// SourceURL name the feed the post came from.
+type outputItem struct {
+	ID         uuid.UUID
+	Title      string
+	URL        string
+	Content    string
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+	Source     string
+	SourceURL  string
+}
+
+// outputItemFromPost turns a post, with the reader's follow settings already
+// applied, into an item. feed may be empty when the post's feed is unknown.
+func outputItemFromPost(post Post, feed database.Feed, feedName string) outputItem {
+	item := outputItem{
+		ID:         post.ID,
+		Title:      post.Title,
+		URL:        post.Url,
+		Categories: post.Categories,
+		Published:  post.CreatedAt,
+		Updated:    post.UpdatedAt,
+		Source:     feedName,
+		SourceURL:  feed.SiteUrl.String,
+	}
+	if post.Description != nil {
+		item.Content = *post.Description
+	}
+	if post.Author != nil {
+		item.Author = *post.Author
+	}
+	if post.PublishedAt != nil {
+		item.Published = *post.PublishedAt
+	}
+	return item
+}
+
+// lastModified is the newest change to the feed or any of its items.
+func (f outputFeed) lastModified() time.Time {
+	latest := f.Updated
+	for _, item := range f.Items {
+		if item.Updated.After(latest) {
+			latest = item.Updated
+		}
+	}
+	return latest.UTC()
+}
+
+type rssOutput struct {
+	XMLName xml.Name         `xml:"rss"`
+	Version string           `xml:"version,attr"`
+	AtomNS  string           `xml:"xmlns:atom,attr"`
+	DCNS    string           `xml:"xmlns:dc,attr"`
+	Channel rssOutputChannel `xml:"channel"`
+}
+
+type rssOutputChannel struct {
+	Title         string          `xml:"title"`
+	Link          string          `xml:"link"`
+	Description   string          `xml:"description"`
+	LastBuildDate string          `xml:"lastBuildDate"`
+	SelfLink      atomOutputLink  `xml:"atom:link"`
+	Items         []rssOutputItem `xml:"item"`
+}
+
+type rssOutputItem struct {
+	Title       string           `xml:"title"`
+	Link        string           `xml:"link,omitempty"`
+	GUID        rssOutputGUID    `xml:"guid"`
+	PubDate     string           `xml:"pubDate"`
+	Creator     string           `xml:"dc:creator,omitempty"`
+	Categories  []string         `xml:"category"`
+	Description string           `xml:"description"`
+	Source      *rssOutputSource `xml:"source"`
+}
+
+type rssOutputGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssOutputSource struct {
+	URL  string `xml:"url,attr"`
+	Name string `xml:",chardata"`
+}
+
+type atomOutput struct {
+	XMLName  xml.Name          `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string            `xml:"id"`
+	Title    string            `xml:"title"`
+	Subtitle string            `xml:"subtitle,omitempty"`
+	Updated  string            `xml:"updated"`
+	Links    []atomOutputLink  `xml:"link"`
+	Author   atomOutputPerson  `xml:"author"`
+	Entries  []atomOutputEntry `xml:"entry"`
+}
+
+type atomOutputLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomOutputPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomOutputCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomOutputText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomOutputSource struct {
+	Title string          `xml:"title"`
+	Link  *atomOutputLink `xml:"link"`
+}
+
+type atomOutputEntry struct {
+	ID         string               `xml:"id"`
+	Title      string               `xml:"title"`
+	Links      []atomOutputLink     `xml:"link"`
+	Published  string               `xml:"published"`
+	Updated    string               `xml:"updated"`
+	Author     *atomOutputPerson    `xml:"author"`
+	Categories []atomOutputCategory `xml:"category"`
+	Content    atomOutputText       `xml:"content"`
+	Source     *atomOutputSource    `xml:"source"`
+}
+
+type jsonFeedOutput struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url,omitempty"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+func buildRSSOutput(feed outputFeed) rssOutput {
+	out := rssOutput{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssOutputChannel{
+			Title:         feed.Title,
+			Link:          feed.HomeURL,
+			Description:   feed.Description,
+			LastBuildDate: feed.lastModified().Format(time.RFC1123Z),
+			SelfLink:      atomOutputLink{Href: feed.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			Items:         make([]rssOutputItem, len(feed.Items)),
+		},
+	}
+	for i, item := range feed.Items {
+		entry := rssOutputItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			GUID:        rssOutputGUID{Value: "urn:uuid:" + item.ID.String()},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Creator:     item.Author,
+			Categories:  item.Categories,
+			Description: item.Content,
+		}
+		// RSS requires a source's URL, so sources without one are left out.
+		if item.SourceURL != "" {
+			entry.Source = &rssOutputSource{URL: item.SourceURL, Name: item.Source}
+		}
+		out.Channel.Items[i] = entry
+	}
+	return out
+}
+
+func buildAtomOutput(feed outputFeed) atomOutput {
+	out := atomOutput{
+		ID:       "urn:uuid:" + feed.ID.String(),
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		Updated:  feed.lastModified().Format(time.RFC3339),
+		Links: []atomOutputLink{
+			{Href: feed.FeedURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: feed.HomeURL, Rel: "alternate"},
+		},
+		Author:  atomOutputPerson{Name: feed.Author},
+		Entries: make([]atomOutputEntry, len(feed.Items)),
+	}
+	for i, item := range feed.Items {
+		entry := atomOutputEntry{
+			ID:        "urn:uuid:" + item.ID.String(),
+			Title:     item.Title,
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Content:   atomOutputText{Type: "html", Body: item.Content},
+		}
+		if item.URL != "" {
+			entry.Links = []atomOutputLink{{Href: item.URL, Rel: "alternate"}}
+		}
+		if item.Author != "" {
+			entry.Author = &atomOutputPerson{Name: item.Author}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomOutputCategory{Term: category})
+		}
+		if item.Source != "" {
+			entry.Source = &atomOutputSource{Title: item.Source}
+			if item.SourceURL != "" {
+				entry.Source.Link = &atomOutputLink{Href: item.SourceURL, Rel: "alternate"}
+			}
+		}
+		out.Entries[i] = entry
+	}
+	return out
+}
+
+func buildJSONFeedOutput(feed outputFeed) jsonFeedOutput {
+	out := jsonFeedOutput{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.HomeURL,
+		FeedURL:     feed.FeedURL,
+		Description: feed.Description,
+		Items:       make([]jsonFeedItem, len(feed.Items)),
+	}
+	if feed.Author != "" {
+		out.Authors = []jsonFeedAuthor{{Name: feed.Author}}
+	}
+	for i, item := range feed.Items {
+		entry := jsonFeedItem{
+			ID:            "urn:uuid:" + item.ID.String(),
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentHTML:   item.Content,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			DateModified:  item.Updated.UTC().Format(time.RFC3339),
+			Tags:          item.Categories,
+		}
+		if item.Author != "" {
+			entry.Authors = []jsonFeedAuthor{{Name: item.Author}}
+		}
+		out.Items[i] = entry
+	}
+	return out
+}
+
+// renderOutputFeed encodes feed in format and returns it with its content
+// type. The encoders escape all text, so titles and HTML content can't break
+// out of their elements.
+func renderOutputFeed(feed outputFeed, format string) ([]byte, string, error) {
+	switch format {
+	case outputFormatRSS:
+		out, err := xml.MarshalIndent(buildRSSOutput(feed), "", "  ")
+		if err != nil {
+			return nil, "", err
+		}
+		return append([]byte(xml.Header), out...), "application/rss+xml; charset=utf-8", nil
+	case outputFormatAtom:
+		out, err := xml.MarshalIndent(buildAtomOutput(feed), "", "  ")
+		if err != nil {
+			return nil, "", err
+		}
+		return append([]byte(xml.Header), out...), "application/atom+xml; charset=utf-8", nil
+	case outputFormatJSON:
+		out, err := json.MarshalIndent(buildJSONFeedOutput(feed), "", "  ")
+		if err != nil {
+			return nil, "", err
+		}
+		return out, "application/feed+json; charset=utf-8", nil
+	}
+	return nil, "", errUnknownOutputFormat
+}
+
+// serveOutputFeed writes feed in format, answering conditional requests with
+// 304 Not Modified through its ETag and Last-Modified headers.
+func serveOutputFeed(w http.ResponseWriter, r *http.Request, feed outputFeed, format string) {
+	body, contentType, err := renderOutputFeed(feed, format)
+	if errors.Is(err, errUnknownOutputFormat) {
+		respondWithError(w, http.StatusNotFound, "Unknown feed format")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't write feed")
+		return
+	}
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	http.ServeContent(w, r, "", feed.lastModified(), bytes.NewReader(body))
+}
+
+// requestBaseURL is the scheme and host the request was made to, honouring
+// the proxy's X-Forwarded-Proto.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testOutputFeed() outputFeed {
+	published := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	return outputFeed{
+		ID:      uuid.New(),
+		Title:   `Tom & Jerry's "feed"`,
+		HomeURL: "https://reader.example.com",
+		FeedURL: "https://reader.example.com/v1/published/abc.rss",
+		Author:  "Tom",
+		Updated: published,
+		Items: []outputItem{{
+			ID:         uuid.New(),
+			Title:      "</title><script>alert(1)</script>",
+			URL:        "https://example.com/post?a=1&b=2",
+			Content:    "<p>Hello <b>world</b> ]]> &amp;</p>",
+			Author:     "Jane",
+			Categories: []string{"Go & Rust"},
+			Published:  published,
+			Updated:    published.Add(time.Hour),
+			Source:     "Example",
+			SourceURL:  "https://example.com",
+		}},
+	}
+}
+
+func TestRenderOutputFeedEscapes(t *testing.T) {
+	feed := testOutputFeed()
+	item := feed.Items[0]
+
+	body, _, err := renderOutputFeed(feed, outputFormatRSS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rss RSSFeed
+	if err := xml.Unmarshal(body, &rss); err != nil {
+		t.Fatalf("RSS output doesn't parse: %v", err)
+	}
+	if rss.Channel.Title != feed.Title || len(rss.Channel.Item) != 1 {
+		t.Fatalf("unexpected channel %+v", rss.Channel)
+	}
+	if got := rss.Channel.Item[0]; got.Title != item.Title || got.Description != item.Content || got.Link != item.URL {
+		t.Errorf("RSS item didn't round-trip: %+v", got)
+	}
+
+	body, _, err = renderOutputFeed(feed, outputFormatAtom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var atom atomOutput
+	if err := xml.Unmarshal(body, &atom); err != nil {
+		t.Fatalf("Atom output doesn't parse: %v", err)
+	}
+	if len(atom.Entries) != 1 || atom.Entries[0].Title != item.Title || atom.Entries[0].Content.Body != item.Content {
+		t.Errorf("Atom entry didn't round-trip: %+v", atom.Entries)
+	}
+
+	body, _, err = renderOutputFeed(feed, outputFormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsonFeed jsonFeedOutput
+	if err := json.Unmarshal(body, &jsonFeed); err != nil {
+		t.Fatalf("JSON Feed output doesn't parse: %v", err)
+	}
+	if len(jsonFeed.Items) != 1 || jsonFeed.Items[0].ContentHTML != item.Content {
+		t.Errorf("JSON Feed item didn't round-trip: %+v", jsonFeed.Items)
+	}
+
+	if _, _, err := renderOutputFeed(feed, "yaml"); err != errUnknownOutputFormat {
+		t.Errorf("expected errUnknownOutputFormat, got %v", err)
+	}
+}
+
+func TestServeOutputFeedConditionalGet(t *testing.T) {
+	feed := testOutputFeed()
+
+	rec := httptest.NewRecorder()
+	serveOutputFeed(rec, httptest.NewRequest(http.MethodGet, "/feed.atom", nil), feed, outputFormatAtom)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	etag := rec.Header().Get("ETag")
+	lastModified := rec.Header().Get("Last-Modified")
+	if etag == "" || lastModified != feed.lastModified().Format(http.TimeFormat) {
+		t.Fatalf("missing validators: ETag %q, Last-Modified %q", etag, lastModified)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/feed.atom", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	serveOutputFeed(rec, req, feed, outputFormatAtom)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: expected 304, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/feed.atom", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	rec = httptest.NewRecorder()
+	serveOutputFeed(rec, req, feed, outputFormatAtom)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since: expected 304, got %d", rec.Code)
+	}
+
+	feed.Items[0].Updated = feed.Items[0].Updated.Add(time.Hour)
+	req = httptest.NewRequest(http.MethodGet, "/feed.atom", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	serveOutputFeed(rec, req, feed, outputFormatAtom)
+	if rec.Code != http.StatusOK {
+		t.Errorf("changed feed: expected 200, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	feedStatsDays  = 90
+	feedStatsWeeks = 26
+)
+
+type PostCount struct {
+	Start time.Time `json:"start"`
+	Posts int64     `json:"posts"`
+}
+
+type FeedStats struct {
+	FeedID               uuid.UUID   `json:"feed_id"`
+	ComputedAt           time.Time   `json:"computed_at"`
+	TotalPosts           int64       `json:"total_posts"`
+	PostsPerDay          []PostCount `json:"posts_per_day"`
+	PostsPerWeek         []PostCount `json:"posts_per_week"`
+	AverageLength        float64     `json:"average_length"`
+	HourHistogram        [24]int64   `json:"hour_histogram"`
+	WeekdayHistogram     [7]int64    `json:"weekday_histogram"` // Sunday first
+	Followers            int64       `json:"followers"`
+	Starrers             int64       `json:"starrers"`
+	LatestPostAt         *time.Time  `json:"latest_post_at"`
+	LastFetchSucceededAt *time.Time  `json:"last_fetch_succeeded_at"`
+}
+
+// computeFeedStats buckets the feed's posts into the days and weeks up to
+// now, UTC.
+func computeFeedStats(ctx context.Context, db *database.Queries, feed database.Feed, now time.Time) (FeedStats, error) {
+	now = now.UTC()
+	stats := FeedStats{
+		FeedID:               feed.ID,
+		ComputedAt:           now,
+		LastFetchSucceededAt: nullTimeToTimePtr(feed.LastFetchSucceededAt),
+	}
+
+	today := now.Truncate(24 * time.Hour)
+	daySince := today.AddDate(0, 0, -(feedStatsDays - 1))
+	days, err := db.GetFeedPostCountsByDay(ctx, database.GetFeedPostCountsByDayParams{
+		FeedID: feed.ID,
+		Since:  daySince,
+	})
+	if err != nil {
+		return FeedStats{}, err
+	}
+	byDay := map[time.Time]int64{}
+	for _, day := range days {
+		byDay[day.Day.UTC()] = day.Posts
+	}
+	for day := daySince; !day.After(today); day = day.AddDate(0, 0, 1) {
+		stats.PostsPerDay = append(stats.PostsPerDay, PostCount{Start: day, Posts: byDay[day]})
+	}
+
+	// Postgres weeks start on Monday.
+	thisWeek := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	weekSince := thisWeek.AddDate(0, 0, -7*(feedStatsWeeks-1))
+	weeks, err := db.GetFeedPostCountsByWeek(ctx, database.GetFeedPostCountsByWeekParams{
+		FeedID: feed.ID,
+		Since:  weekSince,
+	})
+	if err != nil {
+		return FeedStats{}, err
+	}
+	byWeek := map[time.Time]int64{}
+	for _, week := range weeks {
+		byWeek[week.Week.UTC()] = week.Posts
+	}
+	for week := weekSince; !week.After(thisWeek); week = week.AddDate(0, 0, 7) {
+		stats.PostsPerWeek = append(stats.PostsPerWeek, PostCount{Start: week, Posts: byWeek[week]})
+	}
+
+	totals, err := db.GetFeedPostTotals(ctx, feed.ID)
+	if err != nil {
+		return FeedStats{}, err
+	}
+	stats.TotalPosts = totals.TotalPosts
+	stats.AverageLength = totals.AverageLength
+	stats.LatestPostAt = nullTimeToTimePtr(totals.LatestPostAt)
+
+	hours, err := db.GetFeedPostingHours(ctx, feed.ID)
+	if err != nil {
+		return FeedStats{}, err
+	}
+	for _, hour := range hours {
+		if hour.Hour >= 0 && hour.Hour < 24 {
+			stats.HourHistogram[hour.Hour] = hour.Posts
+		}
+	}
+	weekdays, err := db.GetFeedPostingWeekdays(ctx, feed.ID)
+	if err != nil {
+		return FeedStats{}, err
+	}
+	for _, weekday := range weekdays {
+		if weekday.Weekday >= 0 && weekday.Weekday < 7 {
+			stats.WeekdayHistogram[weekday.Weekday] = weekday.Posts
+		}
+	}
+
+	audience, err := db.GetFeedAudienceCounts(ctx, feed.ID)
+	if err != nil {
+		return FeedStats{}, err
+	}
+	stats.Followers = audience.Followers
+	stats.Starrers = audience.Starrers
+	return stats, nil
+}
+
+// FeedStatsCache keeps computed stats in memory. Feeds are computed on first
+// request and then kept fresh by a background refresher; feeds nobody has
+// asked about for a while are dropped.
+type FeedStatsCache struct {
+	db      *database.Queries
+	maxAge  time.Duration
+	mu      sync.Mutex
+	entries map[uuid.UUID]*feedStatsEntry
+}
+
+type feedStatsEntry struct {
+	stats       FeedStats
+	requestedAt time.Time
+}
+
+func NewFeedStatsCache(db *database.Queries, maxAge time.Duration) *FeedStatsCache {
+	return &FeedStatsCache{
+		db:      db,
+		maxAge:  maxAge,
+		entries: map[uuid.UUID]*feedStatsEntry{},
+	}
+}
+
+func (c *FeedStatsCache) Get(ctx context.Context, feed database.Feed) (FeedStats, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[feed.ID]
+	if ok {
+		entry.requestedAt = time.Now()
+		stats := entry.stats
+		c.mu.Unlock()
+		return stats, nil
+	}
+	c.mu.Unlock()
+
+	stats, err := computeFeedStats(ctx, c.db, feed, time.Now())
+	if err != nil {
+		return FeedStats{}, err
+	}
+	c.mu.Lock()
+	c.entries[feed.ID] = &feedStatsEntry{stats: stats, requestedAt: time.Now()}
+	c.mu.Unlock()
+	return stats, nil
+}
+
+// StartRefresher recomputes cached stats older than maxAge every interval.
+func (c *FeedStatsCache) StartRefresher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.refresh()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (c *FeedStatsCache) refresh() {
+	c.mu.Lock()
+	stale := []uuid.UUID{}
+	for feedID, entry := range c.entries {
+		switch {
+		case time.Since(entry.requestedAt) > 24*time.Hour:
+			delete(c.entries, feedID)
+		case time.Since(entry.stats.ComputedAt) > c.maxAge:
+			stale = append(stale, feedID)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, feedID := range stale {
+		feed, err := c.db.GetFeed(context.Background(), feedID)
+		if err != nil {
+			log.Printf("Couldn't load feed %s for stats: %v", feedID, err)
+			c.mu.Lock()
+			delete(c.entries, feedID)
+			c.mu.Unlock()
+			continue
+		}
+		stats, err := computeFeedStats(context.Background(), c.db, feed, time.Now())
+		if err != nil {
+			log.Printf("Couldn't refresh stats for feed %s: %v", feed.Name, err)
+			continue
+		}
+		c.mu.Lock()
+		if entry, ok := c.entries[feedID]; ok {
+			entry.stats = stats
+		}
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func utcDay(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestComputeFeedStatsBuckets(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		today    time.Time
+		thisWeek time.Time
+	}{
+		{"wednesday", time.Date(2024, 3, 6, 15, 4, 0, 0, time.UTC), utcDay(2024, 3, 6), utcDay(2024, 3, 4)},
+		{"sunday ends the week", time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC), utcDay(2024, 3, 10), utcDay(2024, 3, 4)},
+		{"monday starts it", utcDay(2024, 3, 4), utcDay(2024, 3, 4), utcDay(2024, 3, 4)},
+		{"local time is read as UTC", time.Date(2024, 3, 5, 1, 0, 0, 0, time.FixedZone("", 3*60*60)), utcDay(2024, 3, 4), utcDay(2024, 3, 4)},
+		{"across a year", time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC), utcDay(2024, 1, 2), utcDay(2024, 1, 1)},
+	}
+	for _, tt := range tests {
+		daySince := tt.today.AddDate(0, 0, -(feedStatsDays - 1))
+		weekSince := tt.thisWeek.AddDate(0, 0, -7*(feedStatsWeeks-1))
+
+		cfg, db := newTestConfig(t)
+		// Postgres hands back buckets in the session's zone; rows outside the
+		// window are ignored.
+		local := time.FixedZone("", -5*60*60)
+		db.returns("GetFeedPostCountsByDay",
+			database.GetFeedPostCountsByDayRow{Day: daySince.AddDate(0, 0, -1), Posts: 9},
+			database.GetFeedPostCountsByDayRow{Day: daySince, Posts: 1},
+			database.GetFeedPostCountsByDayRow{Day: tt.today.In(local), Posts: 4},
+		)
+		db.returns("GetFeedPostCountsByWeek",
+			database.GetFeedPostCountsByWeekRow{Week: weekSince, Posts: 2},
+			database.GetFeedPostCountsByWeekRow{Week: tt.thisWeek, Posts: 7},
+		)
+		db.returns("GetFeedPostTotals", database.GetFeedPostTotalsRow{})
+		db.returns("GetFeedPostingHours")
+		db.returns("GetFeedPostingWeekdays")
+		db.returns("GetFeedAudienceCounts", database.GetFeedAudienceCountsRow{})
+
+		stats, err := computeFeedStats(context.Background(), cfg.DB, database.Feed{ID: uuid.New()}, tt.now)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if calls := db.called("GetFeedPostCountsByDay"); calls[0].Args[1] != daySince {
+			t.Errorf("%s: days counted since %v, want %v", tt.name, calls[0].Args[1], daySince)
+		}
+		if calls := db.called("GetFeedPostCountsByWeek"); calls[0].Args[1] != weekSince {
+			t.Errorf("%s: weeks counted since %v, want %v", tt.name, calls[0].Args[1], weekSince)
+		}
+
+		days := stats.PostsPerDay
+		if len(days) != feedStatsDays || !days[0].Start.Equal(daySince) || !days[len(days)-1].Start.Equal(tt.today) {
+			t.Fatalf("%s: expected %d days from %v to %v, got %d from %v", tt.name, feedStatsDays, daySince, tt.today, len(days), days[0].Start)
+		}
+		if days[0].Posts != 1 || days[1].Posts != 0 || days[len(days)-1].Posts != 4 {
+			t.Errorf("%s: unexpected day counts %+v ... %+v", tt.name, days[:2], days[len(days)-1])
+		}
+
+		weeks := stats.PostsPerWeek
+		if len(weeks) != feedStatsWeeks || !weeks[0].Start.Equal(weekSince) || !weeks[len(weeks)-1].Start.Equal(tt.thisWeek) {
+			t.Fatalf("%s: expected %d weeks from %v to %v, got %d from %v", tt.name, feedStatsWeeks, weekSince, tt.thisWeek, len(weeks), weeks[0].Start)
+		}
+		for i, week := range weeks {
+			if week.Start.Weekday() != time.Monday {
+				t.Errorf("%s: week %d starts on %v", tt.name, i, week.Start.Weekday())
+			}
+		}
+		if weeks[0].Posts != 2 || weeks[1].Posts != 0 || weeks[len(weeks)-1].Posts != 7 {
+			t.Errorf("%s: unexpected week counts %+v ... %+v", tt.name, weeks[:2], weeks[len(weeks)-1])
+		}
+	}
+}
+
+func TestComputeFeedStatsTotals(t *testing.T) {
+	latest := time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC)
+	succeeded := time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		totals   database.GetFeedPostTotalsRow
+		hours    []any
+		weekdays []any
+		wantHour [24]int64
+		wantDay  [7]int64
+	}{
+		{
+			name: "no posts",
+		},
+		{
+			name:     "posts",
+			totals:   database.GetFeedPostTotalsRow{TotalPosts: 3, AverageLength: 12.5, LatestPostAt: sql.NullTime{Time: latest, Valid: true}},
+			hours:    []any{database.GetFeedPostingHoursRow{Hour: 0, Posts: 1}, database.GetFeedPostingHoursRow{Hour: 23, Posts: 2}},
+			weekdays: []any{database.GetFeedPostingWeekdaysRow{Weekday: 0, Posts: 1}, database.GetFeedPostingWeekdaysRow{Weekday: 3, Posts: 2}},
+			wantHour: [24]int64{0: 1, 23: 2},
+			wantDay:  [7]int64{0: 1, 3: 2},
+		},
+		{
+			name:     "out of range buckets are dropped",
+			totals:   database.GetFeedPostTotalsRow{TotalPosts: 2, AverageLength: 3},
+			hours:    []any{database.GetFeedPostingHoursRow{Hour: -1, Posts: 1}, database.GetFeedPostingHoursRow{Hour: 24, Posts: 1}},
+			weekdays: []any{database.GetFeedPostingWeekdaysRow{Weekday: 7, Posts: 2}},
+		},
+	}
+	for _, tt := range tests {
+		cfg, db := newTestConfig(t)
+		db.returns("GetFeedPostCountsByDay")
+		db.returns("GetFeedPostCountsByWeek")
+		db.returns("GetFeedPostTotals", tt.totals)
+		db.returns("GetFeedPostingHours", tt.hours...)
+		db.returns("GetFeedPostingWeekdays", tt.weekdays...)
+		db.returns("GetFeedAudienceCounts", database.GetFeedAudienceCountsRow{Followers: 4, Starrers: 1})
+
+		feed := database.Feed{ID: uuid.New(), LastFetchSucceededAt: sql.NullTime{Time: succeeded, Valid: true}}
+		stats, err := computeFeedStats(context.Background(), cfg.DB, feed, latest)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if stats.FeedID != feed.ID || stats.TotalPosts != tt.totals.TotalPosts || stats.AverageLength != tt.totals.AverageLength {
+			t.Errorf("%s: unexpected totals %+v", tt.name, stats)
+		}
+		if (stats.LatestPostAt != nil) != tt.totals.LatestPostAt.Valid || stats.LatestPostAt != nil && !stats.LatestPostAt.Equal(latest) {
+			t.Errorf("%s: unexpected latest post %v", tt.name, stats.LatestPostAt)
+		}
+		if stats.LastFetchSucceededAt == nil || !stats.LastFetchSucceededAt.Equal(succeeded) {
+			t.Errorf("%s: unexpected last fetch %v", tt.name, stats.LastFetchSucceededAt)
+		}
+		if stats.HourHistogram != tt.wantHour || stats.WeekdayHistogram != tt.wantDay {
+			t.Errorf("%s: unexpected histograms %v %v", tt.name, stats.HourHistogram, stats.WeekdayHistogram)
+		}
+		if stats.Followers != 4 || stats.Starrers != 1 {
+			t.Errorf("%s: unexpected audience %d/%d", tt.name, stats.Followers, stats.Starrers)
+		}
+	}
+}
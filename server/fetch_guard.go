@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var errNonPublicAddress = errors.New("refusing to connect to a non-public address")
+
+// allowPrivateFetches lets feeds point at loopback and private networks. It
+// is off unless FETCH_ALLOW_PRIVATE_ADDRESSES is set, since users choose the
+// URLs the server fetches.
+var allowPrivateFetches = false
+
+// nonPublicPrefixes are ranges net/netip's predicates don't cover: carrier
+// grade NAT, benchmarking, and IPv4-translated and documentation space.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// publicAddress reports whether addr is a globally routable unicast address.
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// refuseNonPublic is a net.Dialer Control hook. It runs on the resolved
+// address of every connection, so redirects and DNS answers that point
+// inwards are caught too.
+func refuseNonPublic(network, address string, _ syscall.RawConn) error {
+	if allowPrivateFetches {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return errNonPublicAddress
+	}
+	return nil
+}
+
+// fetchClient fetches user-supplied URLs. It dials directly rather than
+// through a proxy, so the address check sees the real destination.
+var fetchClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: refuseNonPublic,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConnsPerHost: 4,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::1", false},
+		{"::", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+	}
+	for _, tt := range tests {
+		if got := publicAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("publicAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestRefuseNonPublic(t *testing.T) {
+	if err := refuseNonPublic("tcp4", "127.0.0.1:80", nil); err != errNonPublicAddress {
+		t.Errorf("loopback: expected errNonPublicAddress, got %v", err)
+	}
+	if err := refuseNonPublic("tcp6", "[::ffff:10.0.0.1]:443", nil); err != errNonPublicAddress {
+		t.Errorf("mapped private: expected errNonPublicAddress, got %v", err)
+	}
+	if err := refuseNonPublic("tcp4", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("public: unexpected error %v", err)
+	}
+}
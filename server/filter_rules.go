@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+const (
+	FilterFieldTitle    = "title"
+	FilterFieldContent  = "content"
+	FilterFieldAuthor   = "author"
+	FilterFieldCategory = "category"
+	FilterFieldDomain   = "domain"
+	FilterFieldFeed     = "feed"
+)
+
+const (
+	FilterOpContains    = "contains"
+	FilterOpNotContains = "not_contains"
+	FilterOpEquals      = "equals"
+	FilterOpNotEquals   = "not_equals"
+	FilterOpMatches     = "matches"
+)
+
+// There is no star action: stars belong to feeds here, so starring a post
+// would star its whole feed. Rules that want to keep a post save it instead.
+const (
+	FilterActionHide     = "hide"
+	FilterActionMarkRead = "mark_read"
+	FilterActionLabel    = "label"
+	FilterActionSave     = "save"
+	FilterActionNotify   = "notify"
+)
+
+const (
+	maxFilterConditions = 20
+	// filterRuleBackfill is how many of the user's latest posts a new or
+	// changed rule is applied to.
+	filterRuleBackfill = 500
+)
+
+// FilterCondition tests one field of a post. Text comparisons ignore case;
+// matches takes a regular expression. A domain equals its subdomains too, and
+// a feed condition's value is the feed's ID.
+type FilterCondition struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+type FilterAction struct {
+	Type    string     `json:"type"`
+	LabelID *uuid.UUID `json:"label_id,omitempty"`
+}
+
+type filterCondition struct {
+	FilterCondition
+	value   string
+	pattern *regexp.Regexp
+}
+
+// filterRule is a stored rule ready to match posts.
+type filterRule struct {
+	database.FilterRule
+	conditions []filterCondition
+	actions    []FilterAction
+}
+
+func compileFilterConditions(conditions []FilterCondition) ([]filterCondition, error) {
+	if len(conditions) == 0 {
+		return nil, errors.New("a rule needs at least one condition")
+	}
+	if len(conditions) > maxFilterConditions {
+		return nil, fmt.Errorf("a rule can have at most %d conditions", maxFilterConditions)
+	}
+	compiled := make([]filterCondition, len(conditions))
+	for i, condition := range conditions {
+		switch condition.Field {
+		case FilterFieldTitle, FilterFieldContent, FilterFieldAuthor, FilterFieldCategory, FilterFieldDomain, FilterFieldFeed:
+		default:
+			return nil, fmt.Errorf("unknown condition field %q", condition.Field)
+		}
+		if condition.Value == "" {
+			return nil, fmt.Errorf("condition on %s needs a value", condition.Field)
+		}
+		c := filterCondition{
+			FilterCondition: condition,
+			value:           strings.ToLower(condition.Value),
+		}
+		switch condition.Operator {
+		case FilterOpContains, FilterOpNotContains, FilterOpEquals, FilterOpNotEquals:
+		case FilterOpMatches:
+			pattern, err := regexp.Compile("(?i)" + condition.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", condition.Value, err)
+			}
+			c.pattern = pattern
+		default:
+			return nil, fmt.Errorf("unknown condition operator %q", condition.Operator)
+		}
+		if condition.Field == FilterFieldFeed {
+			if _, err := uuid.Parse(condition.Value); err != nil {
+				return nil, errors.New("feed conditions take a feed ID")
+			}
+		}
+		compiled[i] = c
+	}
+	return compiled, nil
+}
+
+func validateFilterActions(actions []FilterAction) error {
+	if len(actions) == 0 {
+		return errors.New("a rule needs at least one action")
+	}
+	for _, action := range actions {
+		switch action.Type {
+		case FilterActionHide, FilterActionMarkRead, FilterActionSave, FilterActionNotify:
+		case FilterActionLabel:
+			if action.LabelID == nil {
+				return errors.New("label actions need a label_id")
+			}
+		default:
+			return fmt.Errorf("unknown action %q", action.Type)
+		}
+	}
+	return nil
+}
+
+func compileFilterRule(rule database.FilterRule) (filterRule, error) {
+	var conditions []FilterCondition
+	if err := json.Unmarshal(rule.Conditions, &conditions); err != nil {
+		return filterRule{}, err
+	}
+	var actions []FilterAction
+	if err := json.Unmarshal(rule.Actions, &actions); err != nil {
+		return filterRule{}, err
+	}
+	compiled, err := compileFilterConditions(conditions)
+	if err != nil {
+		return filterRule{}, err
+	}
+	return filterRule{FilterRule: rule, conditions: compiled, actions: actions}, nil
+}
+
+// postDomain is the host of a post's link without a leading www.
+func postDomain(post database.Post) string {
+	parsed, err := url.Parse(post.Url)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+func (c filterCondition) matchText(text string) bool {
+	lower := strings.ToLower(text)
+	switch c.Operator {
+	case FilterOpContains:
+		return strings.Contains(lower, c.value)
+	case FilterOpNotContains:
+		return !strings.Contains(lower, c.value)
+	case FilterOpEquals:
+		return lower == c.value
+	case FilterOpNotEquals:
+		return lower != c.value
+	case FilterOpMatches:
+		return c.pattern.MatchString(text)
+	}
+	return false
+}
+
+func (c filterCondition) matches(post database.Post) bool {
+	switch c.Field {
+	case FilterFieldTitle:
+		return c.matchText(post.Title)
+	case FilterFieldContent:
+		return c.matchText(post.Description.String)
+	case FilterFieldAuthor:
+		return c.matchText(post.Author.String)
+	case FilterFieldFeed:
+		return c.matchText(post.FeedID.String())
+	case FilterFieldDomain:
+		domain := postDomain(post)
+		switch c.Operator {
+		case FilterOpEquals:
+			return domain == c.value || strings.HasSuffix(domain, "."+c.value)
+		case FilterOpNotEquals:
+			return domain != c.value && !strings.HasSuffix(domain, "."+c.value)
+		}
+		return c.matchText(domain)
+	case FilterFieldCategory:
+		// Negative operators hold when no category matches the positive one.
+		positive := c
+		switch c.Operator {
+		case FilterOpNotContains:
+			positive.Operator = FilterOpContains
+		case FilterOpNotEquals:
+			positive.Operator = FilterOpEquals
+		}
+		found := false
+		for _, category := range post.Categories {
+			if positive.matchText(category) {
+				found = true
+				break
+			}
+		}
+		if positive.Operator != c.Operator {
+			return !found
+		}
+		return found
+	}
+	return false
+}
+
+func matchFilterConditions(conditions []filterCondition, matchAny bool, post database.Post) bool {
+	for _, condition := range conditions {
+		if condition.matches(post) == matchAny {
+			return matchAny
+		}
+	}
+	return !matchAny
+}
+
+func (rule filterRule) matches(post database.Post) bool {
+	return matchFilterConditions(rule.conditions, rule.MatchAny, post)
+}
+
+// apply runs the rule's actions on a post it matched. notify is false when
+// the post isn't new: a changed rule re-applied to older posts, or a
+// backfill.
+func (rule filterRule) apply(ctx context.Context, db *database.Queries, post database.Post, notify bool) error {
+	now := time.Now().UTC()
+	for _, action := range rule.actions {
+		var err error
+		switch action.Type {
+		case FilterActionHide:
+			err = db.HidePost(ctx, database.HidePostParams{
+				UserID:    rule.UserID,
+				PostID:    post.ID,
+				RuleID:    rule.ID,
+				CreatedAt: now,
+			})
+		case FilterActionMarkRead:
+			err = db.MarkPostReadIfUnset(ctx, database.MarkPostReadIfUnsetParams{
+				UserID:    rule.UserID,
+				PostID:    post.ID,
+				UpdatedAt: now,
+			})
+		case FilterActionLabel:
+			err = db.AddPostLabel(ctx, database.AddPostLabelParams{
+				LabelID:   *action.LabelID,
+				PostID:    post.ID,
+				CreatedAt: now,
+			})
+		case FilterActionSave:
+			err = db.SavePostIfUnsaved(ctx, database.SavePostIfUnsavedParams{
+				ID:        uuid.New(),
+				CreatedAt: now,
+				UserID:    rule.UserID,
+				PostID:    post.ID,
+			})
+		case FilterActionNotify:
+			if notify {
+				err = createRuleMatchedNotification(ctx, db, rule, post)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s action: %w", action.Type, err)
+		}
+	}
+	return nil
+}
+
+func createRuleMatchedNotification(ctx context.Context, db *database.Queries, rule filterRule, post database.Post) error {
+	metadata, err := json.Marshal(map[string]interface{}{
+		"rule_id":    rule.ID.String(),
+		"rule_name":  rule.Name,
+		"post_id":    post.ID.String(),
+		"post_title": post.Title,
+		"post_url":   post.Url,
+		"feed_id":    post.FeedID.String(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.CreateNotification(ctx, database.CreateNotificationParams{
+		ID:          uuid.New(),
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+		UserID:      rule.UserID,
+		Type:        string(NotificationTypeRuleMatched),
+		Message:     fmt.Sprintf("%s matched: %s", rule.Name, post.Title),
+		ReferenceID: uuid.NullUUID{UUID: post.ID, Valid: true},
+		Metadata:    pqtype.NullRawMessage{RawMessage: metadata, Valid: true},
+	})
+	return err
+}
+
+// applyFilterRules runs the enabled rules of everyone following feedID on
+// its newly stored posts. notify is false for posts that were published a
+// while ago, such as a backfill's.
+func applyFilterRules(ctx context.Context, db *database.Queries, feedID uuid.UUID, posts []database.Post, notify bool) {
+	if len(posts) == 0 {
+		return
+	}
+	stored, err := db.GetEnabledFilterRulesForFeed(ctx, feedID)
+	if err != nil {
+		log.Printf("Couldn't get filter rules for feed %s: %v", feedID, err)
+		return
+	}
+	for _, s := range stored {
+		rule, err := compileFilterRule(s)
+		if err != nil {
+			log.Printf("Skipping filter rule %s: %v", s.ID, err)
+			continue
+		}
+		for _, post := range posts {
+			if !rule.matches(post) {
+				continue
+			}
+			if err := rule.apply(ctx, db, post, notify); err != nil {
+				log.Printf("Filter rule %s on post %s: %v", rule.ID, post.ID, err)
+			}
+		}
+	}
+}
+
+// reapplyFilterRule brings back the posts a rule hid and, if it is enabled,
+// runs it again on the user's latest posts.
+func reapplyFilterRule(ctx context.Context, db *database.Queries, stored database.FilterRule) error {
+	if err := db.UnhidePostsForRule(ctx, stored.ID); err != nil {
+		return err
+	}
+	if !stored.Enabled {
+		return nil
+	}
+	rule, err := compileFilterRule(stored)
+	if err != nil {
+		return err
+	}
+	posts, err := db.GetRecentPostsForUser(ctx, database.GetRecentPostsForUserParams{
+		UserID: stored.UserID,
+		Limit:  filterRuleBackfill,
+	})
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		if !rule.matches(post) {
+			continue
+		}
+		if err := rule.apply(ctx, db, post, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
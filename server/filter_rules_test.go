@@ -0,0 +1,87 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestFilterConditionsMatch(t *testing.T) {
+	feedID := uuid.New()
+	post := database.Post{
+		Title:       "Sponsored: Try our new widget",
+		Url:         "https://blog.example.com/posts/widget",
+		Description: sql.NullString{String: "<p>Widgets for everyone</p>", Valid: true},
+		Author:      sql.NullString{String: "Jane Doe", Valid: true},
+		Categories:  []string{"Ads", "Gadgets"},
+		FeedID:      feedID,
+	}
+
+	tests := []struct {
+		name       string
+		conditions []FilterCondition
+		matchAny   bool
+		want       bool
+	}{
+		{"title contains ignores case", []FilterCondition{{FilterFieldTitle, FilterOpContains, "sponsored"}}, false, true},
+		{"title matches pattern", []FilterCondition{{FilterFieldTitle, FilterOpMatches, `^sponsored:`}}, false, true},
+		{"content not contains", []FilterCondition{{FilterFieldContent, FilterOpNotContains, "widgets"}}, false, false},
+		{"author equals", []FilterCondition{{FilterFieldAuthor, FilterOpEquals, "jane doe"}}, false, true},
+		{"category equals", []FilterCondition{{FilterFieldCategory, FilterOpEquals, "ads"}}, false, true},
+		{"category not equals", []FilterCondition{{FilterFieldCategory, FilterOpNotEquals, "ads"}}, false, false},
+		{"domain equals subdomain", []FilterCondition{{FilterFieldDomain, FilterOpEquals, "example.com"}}, false, true},
+		{"domain not equals other", []FilterCondition{{FilterFieldDomain, FilterOpNotEquals, "example.org"}}, false, true},
+		{"feed equals", []FilterCondition{{FilterFieldFeed, FilterOpEquals, feedID.String()}}, false, true},
+		{"all needs every condition", []FilterCondition{
+			{FilterFieldTitle, FilterOpContains, "sponsored"},
+			{FilterFieldAuthor, FilterOpEquals, "someone else"},
+		}, false, false},
+		{"any needs one condition", []FilterCondition{
+			{FilterFieldTitle, FilterOpContains, "sponsored"},
+			{FilterFieldAuthor, FilterOpEquals, "someone else"},
+		}, true, true},
+	}
+	for _, tt := range tests {
+		compiled, err := compileFilterConditions(tt.conditions)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := matchFilterConditions(compiled, tt.matchAny, post); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompileFilterConditionsRejectsInvalid(t *testing.T) {
+	invalid := [][]FilterCondition{
+		nil,
+		{{"rating", FilterOpEquals, "5"}},
+		{{FilterFieldTitle, "starts_with", "a"}},
+		{{FilterFieldTitle, FilterOpMatches, "("}},
+		{{FilterFieldFeed, FilterOpEquals, "not-a-uuid"}},
+		{{FilterFieldTitle, FilterOpContains, ""}},
+	}
+	for _, conditions := range invalid {
+		if _, err := compileFilterConditions(conditions); err == nil {
+			t.Errorf("expected %v to be rejected", conditions)
+		}
+	}
+}
+
+func TestValidateFilterActions(t *testing.T) {
+	labelID := uuid.New()
+	if err := validateFilterActions([]FilterAction{{Type: FilterActionHide}, {Type: FilterActionLabel, LabelID: &labelID}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateFilterActions([]FilterAction{{Type: FilterActionLabel}}); err == nil {
+		t.Error("expected a label action without label_id to be rejected")
+	}
+	if err := validateFilterActions([]FilterAction{{Type: "delete"}}); err == nil {
+		t.Error("expected an unknown action to be rejected")
+	}
+	if err := validateFilterActions([]FilterAction{{Type: "star"}}); err == nil {
+		t.Error("expected the star action to be rejected")
+	}
+}
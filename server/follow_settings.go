@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+const (
+	sortOrderNewest = "newest"
+	sortOrderOldest = "oldest"
+)
+
+// excerptLength is how many characters of a description a follow with
+// full_content off shows.
+const excerptLength = 300
+
+var excerptPolicy = bluemonday.StrictPolicy()
+
+// followSettings holds a user's feed follows keyed by feed, so responses can
+// show the user's own title and content setting for each feed. Feeds the
+// user doesn't follow keep their shared settings.
+type followSettings map[uuid.UUID]database.FeedFollow
+
+func (cfg *apiConfig) followSettingsForUser(ctx context.Context, userID uuid.UUID) (followSettings, error) {
+	follows, err := cfg.DB.GetFeedFollowsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(followSettings, len(follows))
+	for _, follow := range follows {
+		settings[follow.FeedID] = follow
+	}
+	return settings, nil
+}
+
+func (s followSettings) feedName(feedID uuid.UUID, name string) string {
+	if follow, ok := s[feedID]; ok && follow.Title.Valid {
+		return follow.Title.String
+	}
+	return name
+}
+
+func (s followSettings) sortOrder(feedID uuid.UUID) string {
+	if follow, ok := s[feedID]; ok {
+		return follow.SortOrder
+	}
+	return sortOrderNewest
+}
+
+func (s followSettings) feed(feed Feed) Feed {
+	feed.Name = s.feedName(feed.ID, feed.Name)
+	return feed
+}
+
+func (s followSettings) feeds(feeds []Feed) []Feed {
+	for i := range feeds {
+		feeds[i] = s.feed(feeds[i])
+	}
+	return feeds
+}
+
+func (s followSettings) post(post Post) Post {
+	if follow, ok := s[post.FeedID]; ok && !follow.FullContent && post.Description != nil {
+		excerpt := postExcerpt(*post.Description)
+		post.Description = &excerpt
+	}
+	return post
+}
+
+func (s followSettings) posts(posts []Post) []Post {
+	for i := range posts {
+		posts[i] = s.post(posts[i])
+	}
+	return posts
+}
+
+// postExcerpt turns a description into at most excerptLength characters of
+// plain text.
+func postExcerpt(description string) string {
+	text := html.UnescapeString(excerptPolicy.Sanitize(description))
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= excerptLength {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimRight(string(runes[:excerptLength]), " ") + "…"
+}
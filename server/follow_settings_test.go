@@ -0,0 +1,56 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func TestPostExcerpt(t *testing.T) {
+	got := postExcerpt("<p>Hello <b>world</b> &amp;\n\n<img src=\"x.png\"> friends</p>")
+	if got != "Hello world & friends" {
+		t.Errorf("postExcerpt = %q", got)
+	}
+
+	long := postExcerpt("<p>" + strings.Repeat("word ", 200) + "</p>")
+	if utf8.RuneCountInString(long) > excerptLength+1 || !strings.HasSuffix(long, "…") {
+		t.Errorf("long excerpt has %d characters: %q", utf8.RuneCountInString(long), long)
+	}
+}
+
+func TestFollowSettings(t *testing.T) {
+	renamed, plain, unfollowed := uuid.New(), uuid.New(), uuid.New()
+	settings := followSettings{
+		renamed: {
+			FeedID:      renamed,
+			Title:       sql.NullString{String: "My title", Valid: true},
+			FullContent: false,
+			SortOrder:   sortOrderOldest,
+		},
+		plain: {FeedID: plain, FullContent: true, SortOrder: sortOrderNewest},
+	}
+
+	if got := settings.feedName(renamed, "Shared"); got != "My title" {
+		t.Errorf("renamed feed name = %q", got)
+	}
+	if got := settings.feedName(plain, "Shared"); got != "Shared" {
+		t.Errorf("plain feed name = %q", got)
+	}
+	if got := settings.sortOrder(unfollowed); got != sortOrderNewest {
+		t.Errorf("unfollowed sort order = %q", got)
+	}
+
+	description := "<p>Full <em>text</em></p>"
+	if got := settings.post(Post{FeedID: renamed, Description: &description}); *got.Description != "Full text" {
+		t.Errorf("excerpted description = %q", *got.Description)
+	}
+	if got := settings.post(Post{FeedID: plain, Description: &description}); *got.Description != description {
+		t.Errorf("full description = %q", *got.Description)
+	}
+	if description != "<p>Full <em>text</em></p>" {
+		t.Errorf("post changed the original description to %q", description)
+	}
+}
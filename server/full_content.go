@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// fullContentProcessor replaces an item's description with the article on its
+// page and follows the page's <link rel="canonical">. Only items the feed
+// hasn't stored yet are fetched.
+type fullContentProcessor struct {
+	selector string
+}
+
+func newFullContentProcessor(options json.RawMessage) (ItemProcessor, error) {
+	var opts struct {
+		Selector string `json:"selector"`
+	}
+	if err := decodeProcessorOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Selector != "" {
+		if _, err := cascadia.Compile(opts.Selector); err != nil {
+			return nil, err
+		}
+	}
+	return fullContentProcessor{selector: opts.Selector}, nil
+}
+
+func (p fullContentProcessor) Process(ctx ItemContext, item *RSSItem) (bool, error) {
+	if ctx.IsStored != nil && (ctx.IsStored(item.Link) || ctx.IsStored(item.OriginalLink)) {
+		return true, nil
+	}
+	page, err := fetchURL(item.Link, nil)
+	if err != nil {
+		return true, err
+	}
+	content, canonical, err := extractFullContent(item.Link, page, p.selector)
+	if err != nil {
+		return true, err
+	}
+	if canonical != "" {
+		item.Link = canonicalizeURL(canonical, nil, ctx.TrackingParams)
+	}
+	if content != "" {
+		item.Description = content
+	}
+	return true, nil
+}
+
+// extractFullContent returns the sanitised article HTML from page and its
+// canonical URL, if it declares a usable one. Without a selector the first
+// <article> or <main> is taken.
+func extractFullContent(pageURL string, page []byte, selector string) (string, string, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return "", "", err
+	}
+
+	canonical := ""
+	if href, ok := doc.Find(`link[rel~="canonical"]`).First().Attr("href"); ok {
+		canonical = usableCanonicalURL(pageURL, href)
+	}
+
+	selectors := []string{"article", "main", `[role="main"]`}
+	if selector != "" {
+		selectors = []string{selector}
+	}
+	for _, s := range selectors {
+		selection := doc.Find(s).First()
+		if selection.Length() == 0 {
+			continue
+		}
+		html, err := goquery.OuterHtml(selection)
+		if err != nil {
+			return "", "", err
+		}
+		return strings.TrimSpace(contentPolicy.Sanitize(html)), canonical, nil
+	}
+	return "", canonical, nil
+}
+
+// usableCanonicalURL resolves a rel=canonical href, ignoring non-web URLs and
+// the common mistake of pointing every article at the site's home page.
+func usableCanonicalURL(pageURL, href string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	canonical, err := base.Parse(strings.TrimSpace(href))
+	if err != nil || (canonical.Scheme != "http" && canonical.Scheme != "https") {
+		return ""
+	}
+	if strings.Trim(canonical.Path, "/") == "" && strings.Trim(base.Path, "/") != "" {
+		return ""
+	}
+	return canonical.String()
+}
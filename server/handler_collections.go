@@ -0,0 +1,708 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+const (
+	CollectionKindPosts = "posts"
+	CollectionKindFeeds = "feeds"
+)
+
+const (
+	CollectionPublic   = "public"
+	CollectionUnlisted = "unlisted"
+	CollectionPrivate  = "private"
+)
+
+var collectionSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Collection is a named list of posts or feeds. URLs are where it can be read
+// without signing in, unless it is private.
+type Collection struct {
+	ID          uuid.UUID         `json:"id"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Slug        string            `json:"slug"`
+	Name        string            `json:"name"`
+	Description *string           `json:"description"`
+	Kind        string            `json:"kind"`
+	Visibility  string            `json:"visibility"`
+	Owner       string            `json:"owner,omitempty"`
+	URLs        map[string]string `json:"urls"`
+}
+
+// CollectionDetail is a collection with its items: CollectionPosts for a
+// posts collection and CollectionFeeds for a feeds one. Posts come a page at
+// a time; NextCursor is null on the last page and for feeds collections.
+type CollectionDetail struct {
+	Collection
+	Items      interface{} `json:"items"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+type CollectionPost struct {
+	Post
+	FeedName string    `json:"feed_name"`
+	Note     *string   `json:"note"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+type CollectionFeed struct {
+	Feed
+	Note    *string   `json:"note"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+func collectionURL(baseURL, slug string) string {
+	return baseURL + "/v1/shared/" + slug
+}
+
+func databaseCollectionToCollection(collection database.Collection, owner, baseURL string) Collection {
+	return Collection{
+		ID:          collection.ID,
+		CreatedAt:   collection.CreatedAt,
+		UpdatedAt:   collection.UpdatedAt,
+		Slug:        collection.Slug,
+		Name:        collection.Name,
+		Description: nullStringToStringPtr(collection.Description),
+		Kind:        collection.Kind,
+		Visibility:  collection.Visibility,
+		Owner:       owner,
+		URLs: map[string]string{
+			"json": collectionURL(baseURL, collection.Slug),
+			"rss":  collectionURL(baseURL, collection.Slug) + ".rss",
+		},
+	}
+}
+
+func newCollectionSlug() (string, error) {
+	slugBytes := make([]byte, 8)
+	if _, err := rand.Read(slugBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(slugBytes), nil
+}
+
+func validCollectionVisibility(visibility string) bool {
+	return visibility == CollectionPublic || visibility == CollectionUnlisted || visibility == CollectionPrivate
+}
+
+// validateCollectionSlug checks a slug chosen by the user, writing the error
+// response itself. except is the collection keeping its own slug.
+func (cfg *apiConfig) validateCollectionSlug(w http.ResponseWriter, r *http.Request, slug string, except uuid.UUID) bool {
+	if len(slug) < 3 || len(slug) > 64 || !collectionSlugPattern.MatchString(slug) {
+		respondWithError(w, http.StatusBadRequest, "Slug must be 3 to 64 lowercase letters, digits and dashes")
+		return false
+	}
+	existing, err := cfg.DB.GetCollectionBySlug(r.Context(), slug)
+	if err == nil && existing.ID != except {
+		respondWithError(w, http.StatusConflict, "That slug is already taken")
+		return false
+	}
+	return true
+}
+
+func (cfg *apiConfig) ownCollection(w http.ResponseWriter, r *http.Request, user database.User) (database.Collection, bool) {
+	collectionID, err := uuid.Parse(chi.URLParam(r, "collectionID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid collection ID format")
+		return database.Collection{}, false
+	}
+	collection, err := cfg.DB.GetCollectionForUser(r.Context(), database.GetCollectionForUserParams{
+		ID:     collectionID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Collection not found")
+		return database.Collection{}, false
+	}
+	return collection, true
+}
+
+// collectionPosts loads a page of a posts collection, newest addition first.
+// Posts whose feed was deleted or made private since are left out by the
+// query, so they don't take up a place on the page.
+func (cfg *apiConfig) collectionPosts(r *http.Request, collectionID uuid.UUID, page PageParams) ([]database.GetCollectionPostsRow, *string, error) {
+	rows, err := cfg.DB.GetCollectionPosts(r.Context(), database.GetCollectionPostsParams{
+		CollectionID: collectionID,
+		Limit:        page.queryLimit(),
+		CursorTime:   page.CursorTime,
+		CursorID:     page.CursorID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, next := trimPage(rows, page.Limit, func(row database.GetCollectionPostsRow) string {
+		return encodeTimeCursor(row.AddedAt, row.ID)
+	})
+	return rows, next, nil
+}
+
+// collectionItems loads a collection's items and, for a posts collection,
+// the cursor of the next page. Feeds collections aren't paged.
+func (cfg *apiConfig) collectionItems(r *http.Request, collection database.Collection, page PageParams) (interface{}, *string, error) {
+	if collection.Kind == CollectionKindFeeds {
+		rows, err := cfg.DB.GetCollectionFeeds(r.Context(), collection.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		feeds := make([]CollectionFeed, len(rows))
+		for i, row := range rows {
+			feeds[i] = CollectionFeed{
+				Feed: databaseFeedToFeed(database.Feed{
+					ID:                   row.ID,
+					CreatedAt:            row.CreatedAt,
+					UpdatedAt:            row.UpdatedAt,
+					Name:                 row.Name,
+					Url:                  row.Url,
+					UserID:               row.UserID,
+					LastFetchedAt:        row.LastFetchedAt,
+					Kind:                 row.Kind,
+					LastFetchSucceededAt: row.LastFetchSucceededAt,
+					DeletedAt:            row.DeletedAt,
+					SiteUrl:              row.SiteUrl,
+				}),
+				Note:    nullStringToStringPtr(row.Note),
+				AddedAt: row.AddedAt,
+			}
+		}
+		return feeds, nil, nil
+	}
+
+	rows, next, err := cfg.collectionPosts(r, collection.ID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	posts := make([]CollectionPost, len(rows))
+	for i, row := range rows {
+		posts[i] = CollectionPost{
+			Post: databasePostToPost(database.Post{
+				ID:          row.ID,
+				CreatedAt:   row.CreatedAt,
+				UpdatedAt:   row.UpdatedAt,
+				Title:       row.Title,
+				Url:         row.Url,
+				Description: row.Description,
+				PublishedAt: row.PublishedAt,
+				FeedID:      row.FeedID,
+				OriginalUrl: row.OriginalUrl,
+				Author:      row.Author,
+				Categories:  row.Categories,
+			}),
+			FeedName: row.FeedName,
+			Note:     nullStringToStringPtr(row.Note),
+			AddedAt:  row.AddedAt,
+		}
+	}
+	return posts, next, nil
+}
+
+func (cfg *apiConfig) handlerCollectionsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	collections, err := cfg.DB.GetCollectionsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get collections")
+		return
+	}
+
+	result := make([]Collection, len(collections))
+	for i, collection := range collections {
+		result[i] = databaseCollectionToCollection(collection, "", requestBaseURL(r))
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// handlerCollectionCreate creates an empty collection. Without a slug it gets
+// a random one, and it stays private unless asked otherwise.
+func (cfg *apiConfig) handlerCollectionCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Kind        string `json:"kind"`
+		Visibility  string `json:"visibility"`
+		Slug        string `json:"slug"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Collection name can't be empty")
+		return
+	}
+	if params.Kind != CollectionKindPosts && params.Kind != CollectionKindFeeds {
+		respondWithError(w, http.StatusBadRequest, "kind must be posts or feeds")
+		return
+	}
+	if params.Visibility == "" {
+		params.Visibility = CollectionPrivate
+	}
+	if !validCollectionVisibility(params.Visibility) {
+		respondWithError(w, http.StatusBadRequest, "visibility must be public, unlisted or private")
+		return
+	}
+	if params.Slug != "" {
+		if !cfg.validateCollectionSlug(w, r, params.Slug, uuid.Nil) {
+			return
+		}
+	} else {
+		slug, err := newCollectionSlug()
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't generate slug")
+			return
+		}
+		params.Slug = slug
+	}
+
+	collection, err := cfg.DB.CreateCollection(r.Context(), database.CreateCollectionParams{
+		ID:          uuid.New(),
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+		UserID:      user.ID,
+		Slug:        params.Slug,
+		Name:        params.Name,
+		Description: nullStringFromString(strings.TrimSpace(params.Description)),
+		Kind:        params.Kind,
+		Visibility:  params.Visibility,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create collection")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseCollectionToCollection(collection, "", requestBaseURL(r)))
+}
+
+func (cfg *apiConfig) handlerCollectionGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	collection, ok := cfg.ownCollection(w, r, user)
+	if !ok {
+		return
+	}
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	items, next, err := cfg.collectionItems(r, collection, page)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get collection items")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, CollectionDetail{
+		Collection: databaseCollectionToCollection(collection, "", requestBaseURL(r)),
+		Items:      items,
+		NextCursor: next,
+	})
+}
+
+// handlerCollectionUpdate renames a collection or changes its description,
+// visibility or slug. A new slug revokes links to the old one.
+func (cfg *apiConfig) handlerCollectionUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	collection, ok := cfg.ownCollection(w, r, user)
+	if !ok {
+		return
+	}
+	type parameters struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		Visibility  *string `json:"visibility"`
+		Slug        *string `json:"slug"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if params.Name != nil {
+		collection.Name = strings.TrimSpace(*params.Name)
+		if collection.Name == "" {
+			respondWithError(w, http.StatusBadRequest, "Collection name can't be empty")
+			return
+		}
+	}
+	if params.Description != nil {
+		collection.Description = nullStringFromString(strings.TrimSpace(*params.Description))
+	}
+	if params.Visibility != nil {
+		if !validCollectionVisibility(*params.Visibility) {
+			respondWithError(w, http.StatusBadRequest, "visibility must be public, unlisted or private")
+			return
+		}
+		collection.Visibility = *params.Visibility
+	}
+	if params.Slug != nil && *params.Slug != collection.Slug {
+		if !cfg.validateCollectionSlug(w, r, *params.Slug, collection.ID) {
+			return
+		}
+		collection.Slug = *params.Slug
+	}
+
+	updated, err := cfg.DB.UpdateCollection(r.Context(), database.UpdateCollectionParams{
+		ID:          collection.ID,
+		Slug:        collection.Slug,
+		Name:        collection.Name,
+		Description: collection.Description,
+		Visibility:  collection.Visibility,
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update collection")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseCollectionToCollection(updated, "", requestBaseURL(r)))
+}
+
+// handlerCollectionRevoke gives a collection a new random slug, so links
+// already handed out stop working.
+func (cfg *apiConfig) handlerCollectionRevoke(w http.ResponseWriter, r *http.Request, user database.User) {
+	collection, ok := cfg.ownCollection(w, r, user)
+	if !ok {
+		return
+	}
+	slug, err := newCollectionSlug()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't generate slug")
+		return
+	}
+
+	updated, err := cfg.DB.UpdateCollection(r.Context(), database.UpdateCollectionParams{
+		ID:          collection.ID,
+		Slug:        slug,
+		Name:        collection.Name,
+		Description: collection.Description,
+		Visibility:  collection.Visibility,
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke collection link")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseCollectionToCollection(updated, "", requestBaseURL(r)))
+}
+
+func (cfg *apiConfig) handlerCollectionDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	collection, ok := cfg.ownCollection(w, r, user)
+	if !ok {
+		return
+	}
+	if err := cfg.DB.DeleteCollection(r.Context(), collection.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete collection")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
+
+// handlerCollectionItemAdd adds a post or feed to a collection, or replaces
+// the note of one already in it. Only posts and feeds that anyone may see
+// can be shared.
+func (cfg *apiConfig) handlerCollectionItemAdd(w http.ResponseWriter, r *http.Request, user database.User) {
+	collection, ok := cfg.ownCollection(w, r, user)
+	if !ok {
+		return
+	}
+	type parameters struct {
+		PostID *uuid.UUID `json:"post_id"`
+		FeedID *uuid.UUID `json:"feed_id"`
+		Note   string     `json:"note"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	note := nullStringFromString(strings.TrimSpace(params.Note))
+	var err error
+	if collection.Kind == CollectionKindPosts {
+		if params.PostID == nil {
+			respondWithError(w, http.StatusBadRequest, "post_id is required for a posts collection")
+			return
+		}
+		var post database.Post
+		post, err = cfg.DB.GetPost(r.Context(), *params.PostID)
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Post not found")
+			return
+		}
+		if !cfg.publicFeed(w, r, user, post.FeedID) {
+			return
+		}
+		err = cfg.DB.AddCollectionPost(r.Context(), database.AddCollectionPostParams{
+			CollectionID: collection.ID,
+			PostID:       post.ID,
+			Note:         note,
+			CreatedAt:    time.Now().UTC(),
+		})
+	} else {
+		if params.FeedID == nil {
+			respondWithError(w, http.StatusBadRequest, "feed_id is required for a feeds collection")
+			return
+		}
+		if !cfg.publicFeed(w, r, user, *params.FeedID) {
+			return
+		}
+		err = cfg.DB.AddCollectionFeed(r.Context(), database.AddCollectionFeedParams{
+			CollectionID: collection.ID,
+			FeedID:       *params.FeedID,
+			Note:         note,
+			CreatedAt:    time.Now().UTC(),
+		})
+	}
+	if err == nil {
+		err = cfg.DB.TouchCollection(r.Context(), database.TouchCollectionParams{
+			ID:        collection.ID,
+			UpdatedAt: time.Now().UTC(),
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't add to collection")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
+
+// publicFeed checks that feedID is a feed anyone may see, writing the error
+// response itself. The user's own private feeds can't be shared either.
+func (cfg *apiConfig) publicFeed(w http.ResponseWriter, r *http.Request, user database.User, feedID uuid.UUID) bool {
+	if _, err := cfg.DB.GetFeedVisibleToUser(r.Context(), database.GetFeedVisibleToUserParams{
+		ID:     feedID,
+		UserID: user.ID,
+	}); err != nil {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return false
+	}
+	if _, err := cfg.DB.GetFeedVisibleToUser(r.Context(), database.GetFeedVisibleToUserParams{
+		ID:     feedID,
+		UserID: uuid.Nil,
+	}); err != nil {
+		respondWithError(w, http.StatusForbidden, "Only posts and feeds that are public can be shared")
+		return false
+	}
+	return true
+}
+
+// handlerCollectionItemRemove removes a post or feed, named by itemID, from a
+// collection.
+func (cfg *apiConfig) handlerCollectionItemRemove(w http.ResponseWriter, r *http.Request, user database.User) {
+	collection, ok := cfg.ownCollection(w, r, user)
+	if !ok {
+		return
+	}
+	itemID, err := uuid.Parse(chi.URLParam(r, "itemID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid item ID format")
+		return
+	}
+
+	var removed int64
+	if collection.Kind == CollectionKindPosts {
+		removed, err = cfg.DB.RemoveCollectionPost(r.Context(), database.RemoveCollectionPostParams{
+			CollectionID: collection.ID,
+			PostID:       itemID,
+		})
+	} else {
+		removed, err = cfg.DB.RemoveCollectionFeed(r.Context(), database.RemoveCollectionFeedParams{
+			CollectionID: collection.ID,
+			FeedID:       itemID,
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove from collection")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, http.StatusNotFound, "Item not in collection")
+		return
+	}
+	if err := cfg.DB.TouchCollection(r.Context(), database.TouchCollectionParams{
+		ID:        collection.ID,
+		UpdatedAt: time.Now().UTC(),
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove from collection")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
+
+// handlerPublicCollectionsGet lists public collections, most recently
+// changed first. It needs no authentication.
+func (cfg *apiConfig) handlerPublicCollectionsGet(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	rows, err := cfg.DB.GetPublicCollections(r.Context(), database.GetPublicCollectionsParams{
+		Limit:      page.queryLimit(),
+		CursorTime: page.CursorTime,
+		CursorID:   page.CursorID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get collections")
+		return
+	}
+
+	rows, next := trimPage(rows, page.Limit, func(row database.GetPublicCollectionsRow) string {
+		return encodeTimeCursor(row.UpdatedAt, row.ID)
+	})
+	collections := make([]Collection, len(rows))
+	for i, row := range rows {
+		collections[i] = databaseCollectionToCollection(database.Collection{
+			ID:          row.ID,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+			UserID:      row.UserID,
+			Slug:        row.Slug,
+			Name:        row.Name,
+			Description: row.Description,
+			Kind:        row.Kind,
+			Visibility:  row.Visibility,
+		}, row.OwnerName, requestBaseURL(r))
+	}
+	respondWithJSON(w, http.StatusOK, struct {
+		Collections []Collection `json:"collections"`
+		NextCursor  *string      `json:"next_cursor"`
+	}{
+		Collections: collections,
+		NextCursor:  next,
+	})
+}
+
+// sharedCollection loads the collection a slug names, answering 404 for
+// unknown slugs and private collections alike.
+func (cfg *apiConfig) sharedCollection(w http.ResponseWriter, r *http.Request) (database.Collection, string, bool) {
+	row, err := cfg.DB.GetCollectionBySlug(r.Context(), chi.URLParam(r, "slug"))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && row.Visibility == CollectionPrivate) {
+		respondWithError(w, http.StatusNotFound, "Collection not found")
+		return database.Collection{}, "", false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get collection")
+		return database.Collection{}, "", false
+	}
+	return database.Collection{
+		ID:          row.ID,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+		UserID:      row.UserID,
+		Slug:        row.Slug,
+		Name:        row.Name,
+		Description: row.Description,
+		Kind:        row.Kind,
+		Visibility:  row.Visibility,
+	}, row.OwnerName, true
+}
+
+// handlerSharedCollectionGet shows a public or unlisted collection to anyone.
+func (cfg *apiConfig) handlerSharedCollectionGet(w http.ResponseWriter, r *http.Request) {
+	collection, owner, ok := cfg.sharedCollection(w, r)
+	if !ok {
+		return
+	}
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	items, next, err := cfg.collectionItems(r, collection, page)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get collection items")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, CollectionDetail{
+		Collection: databaseCollectionToCollection(collection, owner, requestBaseURL(r)),
+		Items:      items,
+		NextCursor: next,
+	})
+}
+
+// handlerSharedCollectionRSS serves a public or unlisted collection as RSS: a
+// posts collection's posts, or the newest posts of a feeds collection's
+// feeds.
+func (cfg *apiConfig) handlerSharedCollectionRSS(w http.ResponseWriter, r *http.Request) {
+	collection, owner, ok := cfg.sharedCollection(w, r)
+	if !ok {
+		return
+	}
+
+	var items []outputItem
+	if collection.Kind == CollectionKindFeeds {
+		rows, err := cfg.DB.GetRecentPostsInCollectionFeeds(r.Context(), database.GetRecentPostsInCollectionFeedsParams{
+			CollectionID: collection.ID,
+			Limit:        outputFeedSize,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get posts")
+			return
+		}
+		for _, row := range rows {
+			post := databasePostToPost(database.Post{
+				ID:          row.ID,
+				CreatedAt:   row.CreatedAt,
+				UpdatedAt:   row.UpdatedAt,
+				Title:       row.Title,
+				Url:         row.Url,
+				Description: row.Description,
+				PublishedAt: row.PublishedAt,
+				FeedID:      row.FeedID,
+				Author:      row.Author,
+				Categories:  row.Categories,
+			})
+			items = append(items, outputItemFromPost(post, database.Feed{SiteUrl: row.FeedSiteUrl}, row.FeedName))
+		}
+	} else {
+		rows, _, err := cfg.collectionPosts(r, collection.ID, PageParams{Limit: outputFeedSize})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get posts")
+			return
+		}
+		for _, row := range rows {
+			post := databasePostToPost(database.Post{
+				ID:          row.ID,
+				CreatedAt:   row.CreatedAt,
+				UpdatedAt:   row.UpdatedAt,
+				Title:       row.Title,
+				Url:         row.Url,
+				Description: row.Description,
+				PublishedAt: row.PublishedAt,
+				FeedID:      row.FeedID,
+				Author:      row.Author,
+				Categories:  row.Categories,
+			})
+			items = append(items, outputItemFromPost(post, database.Feed{SiteUrl: row.FeedSiteUrl}, row.FeedName))
+		}
+	}
+
+	baseURL := requestBaseURL(r)
+	serveOutputFeed(w, r, outputFeed{
+		ID:          collection.ID,
+		Title:       collection.Name,
+		Description: collection.Description.String,
+		HomeURL:     collectionURL(baseURL, collection.Slug),
+		FeedURL:     collectionURL(baseURL, collection.Slug) + ".rss",
+		Author:      owner,
+		Updated:     collection.UpdatedAt,
+		Items:       items,
+	}, outputFormatRSS)
+}
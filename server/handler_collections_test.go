@@ -0,0 +1,102 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestCollectionSlugPattern(t *testing.T) {
+	for _, slug := range []string{"go-reading", "weekly-2024", "abc"} {
+		if !collectionSlugPattern.MatchString(slug) {
+			t.Errorf("expected %q to be a valid slug", slug)
+		}
+	}
+	for _, slug := range []string{"Go-Reading", "list.rss", "-lead", "trail-", "double--dash", "with space", "a/b"} {
+		if collectionSlugPattern.MatchString(slug) {
+			t.Errorf("expected %q to be rejected", slug)
+		}
+	}
+}
+
+func TestDatabaseCollectionToCollectionURLs(t *testing.T) {
+	collection := databaseCollectionToCollection(database.Collection{Slug: "go-reading"}, "Tom", "https://reader.example.com")
+	if got := collection.URLs["json"]; got != "https://reader.example.com/v1/shared/go-reading" {
+		t.Errorf("unexpected JSON URL %q", got)
+	}
+	if got := collection.URLs["rss"]; got != "https://reader.example.com/v1/shared/go-reading.rss" {
+		t.Errorf("unexpected RSS URL %q", got)
+	}
+}
+
+func TestHandlerSharedCollectionGetPages(t *testing.T) {
+	collection := database.GetCollectionBySlugRow{ID: uuid.New(), Slug: "go-reading", Name: "Go", Kind: CollectionKindPosts, Visibility: CollectionUnlisted, OwnerName: "Tom"}
+	added := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	type stored struct {
+		row    database.GetCollectionPostsRow
+		shared bool
+	}
+	var table []stored
+	for i, shared := range []bool{true, false, true, true} {
+		table = append(table, stored{database.GetCollectionPostsRow{ID: uuid.New(), FeedID: uuid.New(), AddedAt: added.Add(-time.Duration(i) * time.Minute)}, shared})
+	}
+
+	cfg, db := newTestConfig(t)
+	db.returns("GetCollectionBySlug", collection)
+	// Answer as the query would: posts of feeds no longer shared are left
+	// out before the limit is applied.
+	db.on("GetCollectionPosts", func(args []driver.Value) fakeResult {
+		var rows [][]driver.Value
+		for _, post := range table {
+			if post.shared && int64(len(rows)) < args[1].(int64) {
+				rows = append(rows, fakeRow(post.row))
+			}
+		}
+		return fakeResult{Rows: rows}
+	})
+	rec := servePublic(cfg.handlerSharedCollectionGet, http.MethodGet, "/?limit=2", "", map[string]string{"slug": collection.Slug})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	calls := db.called("GetCollectionPosts")
+	if len(calls) != 1 || argUUID(t, calls[0].Args[0]) != collection.ID || calls[0].Args[1] != int64(3) || calls[0].Args[2] != nil {
+		t.Fatalf("unexpected page queries %+v", calls)
+	}
+	query := calls[0].Query
+	for _, filter := range []string{"feeds.deleted_at IS NULL", "feeds.kind <> 'newsletter'", "feed_credentials"} {
+		if i := strings.Index(query, filter); i < 0 || i > strings.Index(query, "LIMIT") {
+			t.Errorf("expected the query to filter on %q ahead of its limit", filter)
+		}
+	}
+
+	var got struct {
+		Items      []CollectionPost `json:"items"`
+		NextCursor *string          `json:"next_cursor"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	// The post whose feed gained credentials doesn't take a place on the page.
+	if len(got.Items) != 2 || got.Items[0].ID != table[0].row.ID || got.Items[1].ID != table[2].row.ID {
+		t.Errorf("expected %s and %s, got %+v", table[0].row.ID, table[2].row.ID, got.Items)
+	}
+	if want := encodeTimeCursor(table[2].row.AddedAt, table[2].row.ID); got.NextCursor == nil || *got.NextCursor != want {
+		t.Errorf("expected next cursor %s, got %v", want, got.NextCursor)
+	}
+
+	// Private collections aren't shared.
+	cfg, db = newTestConfig(t)
+	collection.Visibility = CollectionPrivate
+	db.returns("GetCollectionBySlug", collection)
+	rec = servePublic(cfg.handlerSharedCollectionGet, http.MethodGet, "/", "", map[string]string{"slug": collection.Slug})
+	if rec.Code != http.StatusNotFound || len(db.called("GetCollectionPosts")) != 0 {
+		t.Errorf("expected 404 without loading posts, got %d", rec.Code)
+	}
+}
@@ -1,18 +1,27 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/andybalholm/cascadia"
 	"github.com/google/uuid"
 )
 
 func (cfg *apiConfig) handlerFeedCreate(w http.ResponseWriter, r *http.Request, user database.User) {
 	type parameters struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
+		Name        string           `json:"name"`
+		URL         string           `json:"url"`
+		Kind        string           `json:"kind"`
+		Selectors   *PageSelectors   `json:"selectors"`
+		Selector    string           `json:"selector"`
+		Credentials *FeedCredentials `json:"credentials"`
 	}
 	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
@@ -21,29 +30,270 @@ func (cfg *apiConfig) handlerFeedCreate(w http.ResponseWriter, r *http.Request,
 		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters")
 		return
 	}
+	if params.Kind == "" {
+		params.Kind = FeedKindRSS
+	}
+	switch params.Kind {
+	case FeedKindRSS:
+	case FeedKindScrapedPage:
+		if params.Selectors == nil {
+			respondWithError(w, http.StatusBadRequest, "Scraped page feeds require selectors")
+			return
+		}
+		if err := params.Selectors.validate(); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid selectors: "+err.Error())
+			return
+		}
+	case FeedKindMonitoredPage:
+		if params.Selector != "" {
+			if _, err := cascadia.Compile(params.Selector); err != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid selector: "+err.Error())
+				return
+			}
+		}
+	default:
+		respondWithError(w, http.StatusBadRequest, "Unknown feed kind")
+		return
+	}
+	if params.Credentials != nil {
+		if err := params.Credentials.validate(); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid credentials: "+err.Error())
+			return
+		}
+		if cfg.Credentials == nil {
+			respondWithError(w, http.StatusServiceUnavailable, "Feed credentials are not configured on this server")
+			return
+		}
+	}
+
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create feed")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
 
-	feed, err := cfg.DB.CreateFeed(r.Context(), database.CreateFeedParams{
+	feed, err := qtx.CreateFeed(r.Context(), database.CreateFeedParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
 		UserID:    user.ID,
 		Name:      params.Name,
 		Url:       params.URL,
+		Kind:      params.Kind,
 	})
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create feed")
 		return
 	}
+	switch params.Kind {
+	case FeedKindScrapedPage:
+		_, err = qtx.UpsertFeedSelectors(r.Context(), database.UpsertFeedSelectorsParams{
+			FeedID:          feed.ID,
+			CreatedAt:       time.Now().UTC(),
+			UpdatedAt:       time.Now().UTC(),
+			ItemSelector:    params.Selectors.Item,
+			TitleSelector:   params.Selectors.Title,
+			LinkSelector:    params.Selectors.Link,
+			DateSelector:    nullStringFromString(params.Selectors.Date),
+			SummarySelector: nullStringFromString(params.Selectors.Summary),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't store feed selectors")
+			return
+		}
+	case FeedKindMonitoredPage:
+		_, err = qtx.CreatePageMonitor(r.Context(), database.CreatePageMonitorParams{
+			FeedID:    feed.ID,
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			Selector:  nullStringFromString(params.Selector),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't create page monitor")
+			return
+		}
+	}
+	if params.Kind == FeedKindRSS && cfg.BackfillMaxPages > 0 {
+		_, err = qtx.StartFeedBackfill(r.Context(), database.StartFeedBackfillParams{
+			FeedID:    feed.ID,
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			MaxPages:  int32(cfg.BackfillMaxPages),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't start feed backfill")
+			return
+		}
+	}
+	if params.Credentials != nil {
+		err = storeFeedCredentials(r.Context(), qtx, cfg.Credentials, feed.ID, *params.Credentials)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't store feed credentials")
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create feed")
+		return
+	}
 
 	respondWithJSON(w, http.StatusOK, databaseFeedToFeed(feed))
 }
 
+// handlerGetFeeds is public. Feeds carrying credentials are only listed for
+// the user who created them.
 func (cfg *apiConfig) handlerGetFeeds(w http.ResponseWriter, r *http.Request) {
-	feeds, err := cfg.DB.GetFeeds(r.Context())
+	var feeds []database.Feed
+	var settings followSettings
+	var err error
+	if user, authErr := cfg.authenticate(r); authErr == nil {
+		feeds, err = cfg.DB.GetFeedsVisibleToUser(r.Context(), user.ID)
+		if err == nil {
+			settings, err = cfg.followSettingsForUser(r.Context(), user.ID)
+		}
+	} else {
+		feeds, err = cfg.DB.GetPublicFeeds(r.Context())
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't get feeds")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, databaseFeedsToFeeds(feeds))
-}
\ No newline at end of file
+	respondWithJSON(w, http.StatusOK, settings.feeds(databaseFeedsToFeeds(feeds)))
+}
+
+func (cfg *apiConfig) handlerFeedGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.visibleFeed(w, r, user)
+	if !ok {
+		return
+	}
+	settings, err := cfg.followSettingsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed follows")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, settings.feed(databaseFeedToFeed(feed)))
+}
+
+// handlerFeedUpdate renames a feed or points it at a new URL. A feed whose
+// URL changes is fetched again on the scraper's next run.
+func (cfg *apiConfig) handlerFeedUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.feedOwnedByUser(w, r, user)
+	if !ok {
+		return
+	}
+	type parameters struct {
+		Name *string `json:"name"`
+		URL  *string `json:"url"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	name, url := feed.Name, feed.Url
+	if params.Name != nil {
+		name = strings.TrimSpace(*params.Name)
+		if name == "" {
+			respondWithError(w, http.StatusBadRequest, "Feed name can't be empty")
+			return
+		}
+	}
+	if params.URL != nil && strings.TrimSpace(*params.URL) != feed.Url {
+		url = strings.TrimSpace(*params.URL)
+		if feed.Kind == FeedKindNewsletter {
+			respondWithError(w, http.StatusBadRequest, "Newsletter feeds keep their inbound address")
+			return
+		}
+		if url == "" {
+			respondWithError(w, http.StatusBadRequest, "Feed URL can't be empty")
+			return
+		}
+		inUse, err := cfg.DB.FeedURLInUse(r.Context(), database.FeedURLInUseParams{
+			Url: url,
+			ID:  feed.ID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update feed")
+			return
+		}
+		if inUse {
+			respondWithError(w, http.StatusConflict, "Another feed already uses that URL")
+			return
+		}
+	}
+
+	updated, err := cfg.DB.UpdateFeed(r.Context(), database.UpdateFeedParams{
+		ID:        feed.ID,
+		Name:      name,
+		Url:       url,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update feed")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseFeedToFeed(updated))
+}
+
+// handlerFeedDelete soft deletes a feed. Its follows, stars and inbound
+// addresses go with it, and every other follower is told.
+func (cfg *apiConfig) handlerFeedDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.feedOwnedByUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete feed")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
+
+	err = qtx.SoftDeleteFeed(r.Context(), database.SoftDeleteFeedParams{
+		ID:        feed.ID,
+		DeletedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete feed")
+		return
+	}
+	followers, err := qtx.DeleteFeedFollowsForFeed(r.Context(), feed.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove feed follows")
+		return
+	}
+	if err := qtx.DeleteStarredFeedsForFeed(r.Context(), feed.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove feed stars")
+		return
+	}
+	if err := qtx.DeleteInboundAddressesForFeed(r.Context(), feed.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove inbound addresses")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete feed")
+		return
+	}
+
+	if cfg.NotificationService != nil {
+		go func() {
+			for _, followerID := range followers {
+				if followerID == user.ID {
+					continue
+				}
+				if err := cfg.NotificationService.SendFeedDeletedNotification(context.Background(), followerID, feed); err != nil {
+					log.Printf("Couldn't notify %s that feed %s was deleted: %v", followerID, feed.Name, err)
+				}
+			}
+		}()
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
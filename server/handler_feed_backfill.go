@@ -0,0 +1,112 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+type FeedBackfill struct {
+	FeedID       uuid.UUID `json:"feed_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Status       string    `json:"status"`
+	PagesFetched int32     `json:"pages_fetched"`
+	MaxPages     int32     `json:"max_pages"`
+	ItemsFound   int32     `json:"items_found"`
+	Error        *string   `json:"error"`
+}
+
+func databaseFeedBackfillToFeedBackfill(backfill database.FeedBackfill) FeedBackfill {
+	return FeedBackfill{
+		FeedID:       backfill.FeedID,
+		CreatedAt:    backfill.CreatedAt,
+		UpdatedAt:    backfill.UpdatedAt,
+		Status:       backfill.Status,
+		PagesFetched: backfill.PagesFetched,
+		MaxPages:     backfill.MaxPages,
+		ItemsFound:   backfill.ItemsFound,
+		Error:        nullStringToStringPtr(backfill.Error),
+	}
+}
+
+// handlerFeedBackfillStart (re)starts walking a feed's archive. Only the
+// feed's creator and its followers may start one, since it costs a fetch per
+// page. max_pages may lower, but not raise, the server's limit.
+func (cfg *apiConfig) handlerFeedBackfillStart(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		MaxPages int `json:"max_pages"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if cfg.BackfillMaxPages <= 0 {
+		respondWithError(w, http.StatusServiceUnavailable, "Feed backfill is not enabled on this server")
+		return
+	}
+	feed, ok := cfg.visibleFeed(w, r, user)
+	if !ok {
+		return
+	}
+	if feed.UserID != user.ID {
+		_, err := cfg.DB.GetFeedFollowForFeed(r.Context(), database.GetFeedFollowForFeedParams{
+			UserID: user.ID,
+			FeedID: feed.ID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusForbidden, "Only the feed's creator or followers can backfill it")
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't check feed follow")
+			return
+		}
+	}
+	if feed.Kind != FeedKindRSS {
+		respondWithError(w, http.StatusBadRequest, "Only RSS feeds can be backfilled")
+		return
+	}
+	maxPages := cfg.BackfillMaxPages
+	if params.MaxPages > 0 && params.MaxPages < maxPages {
+		maxPages = params.MaxPages
+	}
+
+	backfill, err := cfg.DB.StartFeedBackfill(r.Context(), database.StartFeedBackfillParams{
+		FeedID:    feed.ID,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		MaxPages:  int32(maxPages),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start backfill")
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, databaseFeedBackfillToFeedBackfill(backfill))
+}
+
+func (cfg *apiConfig) handlerFeedBackfillGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.visibleFeed(w, r, user)
+	if !ok {
+		return
+	}
+	backfill, err := cfg.DB.GetFeedBackfill(r.Context(), feed.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed has not been backfilled")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get backfill")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseFeedBackfillToFeedBackfill(backfill))
+}
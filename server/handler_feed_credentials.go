@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+// visibleFeed loads the feedID URL parameter's feed, answering 404 for feeds
+// the user may not see.
+func (cfg *apiConfig) visibleFeed(w http.ResponseWriter, r *http.Request, user database.User) (database.Feed, bool) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed ID format")
+		return database.Feed{}, false
+	}
+	feed, err := cfg.DB.GetFeedVisibleToUser(r.Context(), database.GetFeedVisibleToUserParams{
+		ID:     feedID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return database.Feed{}, false
+	}
+	return feed, true
+}
+
+// feedOwnedByUser loads a feed for one of its creator's management endpoints,
+// writing the error response itself when the user may not manage it.
+func (cfg *apiConfig) feedOwnedByUser(w http.ResponseWriter, r *http.Request, user database.User) (database.Feed, bool) {
+	feed, ok := cfg.visibleFeed(w, r, user)
+	if !ok {
+		return database.Feed{}, false
+	}
+	if feed.UserID != user.ID {
+		respondWithError(w, http.StatusForbidden, "Only the feed's creator can change it")
+		return database.Feed{}, false
+	}
+	return feed, true
+}
+
+func (cfg *apiConfig) handlerFeedCredentialsUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.feedOwnedByUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	var creds FeedCredentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if err := creds.validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid credentials: "+err.Error())
+		return
+	}
+	if cfg.Credentials == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "Feed credentials are not configured on this server")
+		return
+	}
+
+	// A feed with credentials is visible only to its creator, so everyone
+	// else's follows and stars go in the same transaction.
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store feed credentials")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
+
+	if err := storeFeedCredentials(r.Context(), qtx, cfg.Credentials, feed.ID, creds); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store feed credentials")
+		return
+	}
+	followers, err := qtx.DeleteOtherFeedFollowsForFeed(r.Context(), database.DeleteOtherFeedFollowsForFeedParams{
+		FeedID: feed.ID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove feed follows")
+		return
+	}
+	starrers, err := qtx.DeleteOtherStarredFeedsForFeed(r.Context(), database.DeleteOtherStarredFeedsForFeedParams{
+		FeedID: feed.ID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove feed stars")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store feed credentials")
+		return
+	}
+
+	if cfg.NotificationService != nil {
+		go func() {
+			notified := map[uuid.UUID]bool{}
+			for _, userID := range append(followers, starrers...) {
+				if notified[userID] {
+					continue
+				}
+				notified[userID] = true
+				if err := cfg.NotificationService.SendFeedMadePrivateNotification(context.Background(), userID, feed); err != nil {
+					log.Printf("Couldn't notify %s that feed %s was made private: %v", userID, feed.Name, err)
+				}
+			}
+		}()
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"feed_id":   feed.ID.String(),
+		"auth_type": creds.Type,
+	})
+}
+
+func (cfg *apiConfig) handlerFeedCredentialsDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.feedOwnedByUser(w, r, user)
+	if !ok {
+		return
+	}
+
+	if err := cfg.DB.DeleteFeedCredentials(r.Context(), feed.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete feed credentials")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Sreenesh123/rssagg/internal/database"
@@ -33,6 +34,15 @@ func (cfg *apiConfig) handlerFeedFollowCreate(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	_, err = cfg.DB.GetFeedVisibleToUser(r.Context(), database.GetFeedVisibleToUserParams{
+		ID:     params.FeedID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+
 	feedFollow, err := cfg.DB.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now().UTC(),
@@ -66,4 +76,86 @@ func (cfg *apiConfig) handlerFeedFollowDelete(w http.ResponseWriter, r *http.Req
 	}
 
 	respondWithJSON(w, http.StatusOK, struct{}{})
-}
\ No newline at end of file
+}
+
+// handlerFeedFollowUpdate changes the user's settings for a followed feed:
+// its folder (null for the top level) and position there, a private title
+// (empty to go back to the feed's name), whether it shows in the main
+// timeline, full content or excerpts, and the order its posts are read in.
+func (cfg *apiConfig) handlerFeedFollowUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedFollowID, err := uuid.Parse(chi.URLParam(r, "feedFollowID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid feed follow ID")
+		return
+	}
+	feedFollow, err := cfg.DB.GetFeedFollowForUser(r.Context(), database.GetFeedFollowForUserParams{
+		ID:     feedFollowID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Feed follow not found")
+		return
+	}
+	type parameters struct {
+		FolderID         optionalUUID `json:"folder_id"`
+		Position         *int32       `json:"position"`
+		Title            *string      `json:"title"`
+		HideFromTimeline *bool        `json:"hide_from_timeline"`
+		FullContent      *bool        `json:"full_content"`
+		SortOrder        *string      `json:"sort_order"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if params.FolderID.Set {
+		if params.FolderID.Value.Valid {
+			_, err := cfg.DB.GetFolderForUser(r.Context(), database.GetFolderForUserParams{
+				ID:     params.FolderID.Value.UUID,
+				UserID: user.ID,
+			})
+			if err != nil {
+				respondWithError(w, http.StatusNotFound, "Folder not found")
+				return
+			}
+		}
+		feedFollow.FolderID = params.FolderID.Value
+	}
+	if params.Position != nil {
+		feedFollow.Position = *params.Position
+	}
+	if params.Title != nil {
+		feedFollow.Title = nullStringFromString(strings.TrimSpace(*params.Title))
+	}
+	if params.HideFromTimeline != nil {
+		feedFollow.HideFromTimeline = *params.HideFromTimeline
+	}
+	if params.FullContent != nil {
+		feedFollow.FullContent = *params.FullContent
+	}
+	if params.SortOrder != nil {
+		if *params.SortOrder != sortOrderNewest && *params.SortOrder != sortOrderOldest {
+			respondWithError(w, http.StatusBadRequest, "sort_order must be newest or oldest")
+			return
+		}
+		feedFollow.SortOrder = *params.SortOrder
+	}
+
+	updated, err := cfg.DB.UpdateFeedFollow(r.Context(), database.UpdateFeedFollowParams{
+		ID:               feedFollow.ID,
+		FolderID:         feedFollow.FolderID,
+		Position:         feedFollow.Position,
+		Title:            feedFollow.Title,
+		HideFromTimeline: feedFollow.HideFromTimeline,
+		FullContent:      feedFollow.FullContent,
+		SortOrder:        feedFollow.SortOrder,
+		UpdatedAt:        time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update feed follow")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseFeedFollowToFeedFollow(updated))
+}
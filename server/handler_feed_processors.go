@@ -0,0 +1,88 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+)
+
+// handlerFeedProcessorsGet lists the processors that run only for this feed,
+// after the server-wide ones.
+func (cfg *apiConfig) handlerFeedProcessorsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.feedOwnedByUser(w, r, user)
+	if !ok {
+		return
+	}
+	stored, err := cfg.DB.GetFeedProcessors(r.Context(), feed.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithJSON(w, http.StatusOK, []ProcessorConfig{})
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed processors")
+		return
+	}
+	configs := []ProcessorConfig{}
+	if err := json.Unmarshal(stored.Config, &configs); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't read feed processors")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, configs)
+}
+
+// handlerFeedProcessorsUpdate replaces the feed's processors with the ordered
+// list in the body.
+func (cfg *apiConfig) handlerFeedProcessorsUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.feedOwnedByUser(w, r, user)
+	if !ok {
+		return
+	}
+	var configs []ProcessorConfig
+	if err := json.NewDecoder(r.Body).Decode(&configs); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if _, err := buildProcessorChain(configs); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid processors: "+err.Error())
+		return
+	}
+	if configs == nil {
+		configs = []ProcessorConfig{}
+	}
+	config, err := json.Marshal(configs)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store feed processors")
+		return
+	}
+
+	_, err = cfg.DB.UpsertFeedProcessors(r.Context(), database.UpsertFeedProcessorsParams{
+		FeedID:    feed.ID,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Config:    config,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store feed processors")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, configs)
+}
+
+func (cfg *apiConfig) handlerFeedProcessorsDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.feedOwnedByUser(w, r, user)
+	if !ok {
+		return
+	}
+	if err := cfg.DB.DeleteFeedProcessors(r.Context(), feed.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete feed processors")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
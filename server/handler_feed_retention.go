@@ -0,0 +1,108 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+type PostPrune struct {
+	CreatedAt    time.Time `json:"created_at"`
+	Reason       string    `json:"reason"`
+	PostsRemoved int32     `json:"posts_removed"`
+}
+
+// FeedRetention shows a feed's own settings (null inherits the server-wide
+// policy), the policy actually applied, and what pruning removed recently.
+type FeedRetention struct {
+	FeedID       uuid.UUID       `json:"feed_id"`
+	MaxAgeDays   *int32          `json:"max_age_days"`
+	MaxItems     *int32          `json:"max_items"`
+	Effective    RetentionPolicy `json:"effective"`
+	RecentPrunes []PostPrune     `json:"recent_prunes"`
+}
+
+func (cfg *apiConfig) feedRetention(r *http.Request, feedID uuid.UUID) (FeedRetention, error) {
+	stored, err := cfg.DB.GetFeedRetention(r.Context(), feedID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return FeedRetention{}, err
+	}
+	prunes, err := cfg.DB.GetRecentPostPrunesForFeed(r.Context(), feedID)
+	if err != nil {
+		return FeedRetention{}, err
+	}
+
+	result := FeedRetention{
+		FeedID:       feedID,
+		MaxAgeDays:   nullInt32ToInt32Ptr(stored.MaxAgeDays),
+		MaxItems:     nullInt32ToInt32Ptr(stored.MaxItems),
+		Effective:    cfg.Retention.withOverrides(stored.MaxAgeDays, stored.MaxItems),
+		RecentPrunes: make([]PostPrune, len(prunes)),
+	}
+	for i, prune := range prunes {
+		result.RecentPrunes[i] = PostPrune{
+			CreatedAt:    prune.CreatedAt,
+			Reason:       prune.Reason,
+			PostsRemoved: prune.PostsRemoved,
+		}
+	}
+	return result, nil
+}
+
+func (cfg *apiConfig) handlerFeedRetentionGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.visibleFeed(w, r, user)
+	if !ok {
+		return
+	}
+	retention, err := cfg.feedRetention(r, feed.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed retention")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, retention)
+}
+
+func (cfg *apiConfig) handlerFeedRetentionUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.feedOwnedByUser(w, r, user)
+	if !ok {
+		return
+	}
+	type parameters struct {
+		MaxAgeDays *int32 `json:"max_age_days"`
+		MaxItems   *int32 `json:"max_items"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if (params.MaxAgeDays != nil && *params.MaxAgeDays < 0) || (params.MaxItems != nil && *params.MaxItems < 0) {
+		respondWithError(w, http.StatusBadRequest, "Retention limits can't be negative")
+		return
+	}
+
+	_, err := cfg.DB.UpsertFeedRetention(r.Context(), database.UpsertFeedRetentionParams{
+		FeedID:     feed.ID,
+		CreatedAt:  time.Now().UTC(),
+		UpdatedAt:  time.Now().UTC(),
+		MaxAgeDays: int32PtrToNullInt32(params.MaxAgeDays),
+		MaxItems:   int32PtrToNullInt32(params.MaxItems),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update feed retention")
+		return
+	}
+	retention, err := cfg.feedRetention(r, feed.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed retention")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, retention)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+)
+
+// handlerFeedStatsGet serves cached stats, so they can be up to the cache's
+// max age behind.
+func (cfg *apiConfig) handlerFeedStatsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.visibleFeed(w, r, user)
+	if !ok {
+		return
+	}
+	stats, err := cfg.FeedStats.Get(r.Context(), feed)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed stats")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestHandlerFeedCreateReaddsDeletedURL(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	deletedID := uuid.New()
+	stored := map[string]bool{fmt.Sprint(deletedID, " https://example.com/post"): true}
+
+	cfg, db := newTestConfig(t)
+	db.on("CreateFeed", func(args []driver.Value) fakeResult {
+		return fakeResult{Rows: [][]driver.Value{fakeRow(database.Feed{
+			ID:     argUUID(t, args[0]),
+			Name:   args[3].(string),
+			Url:    args[4].(string),
+			UserID: argUUID(t, args[5]),
+			Kind:   args[6].(string),
+		})}}
+	})
+	// Post URLs are unique within a feed, as the posts table enforces.
+	db.on("CreatePost", func(args []driver.Value) fakeResult {
+		key := fmt.Sprint(argUUID(t, args[7]), " ", args[4])
+		if stored[key] {
+			return fakeResult{Err: fmt.Errorf(`pq: duplicate key value violates unique constraint "posts_feed_id_url_key"`)}
+		}
+		stored[key] = true
+		return fakeResult{Rows: [][]driver.Value{fakeRow(database.Post{ID: argUUID(t, args[0]), FeedID: argUUID(t, args[7])})}}
+	})
+	db.returns("GetEnabledFilterRulesForFeed")
+
+	rec := serveAuthed(cfg.handlerFeedCreate, user, http.MethodPost, "/", `{"name": "Example", "url": "https://example.com/feed"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var feed Feed
+	if err := json.NewDecoder(rec.Body).Decode(&feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.ID == deletedID || feed.Url != "https://example.com/feed" || db.commits != 1 {
+		t.Fatalf("expected a new committed feed, got %+v", feed)
+	}
+
+	items := []RSSItem{{Title: "Post", Link: "https://example.com/post"}, {Title: "Post", Link: "https://example.com/post"}}
+	saved, err := savePosts(cfg.DB, database.Feed{ID: feed.ID}, items, false)
+	if err != nil || saved != 1 {
+		t.Errorf("expected the deleted feed's post stored once for the new feed, got %d: %v", saved, err)
+	}
+}
+
+func TestHandlerFeedUpdate(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	feed := database.Feed{ID: uuid.New(), UserID: user.ID, Name: "Old", Url: "https://example.com/old", Kind: FeedKindRSS}
+	newsletter := database.Feed{ID: uuid.New(), UserID: user.ID, Name: "Letters", Url: "letters@in.example.com", Kind: FeedKindNewsletter}
+	others := database.Feed{ID: uuid.New(), UserID: uuid.New(), Name: "Theirs", Url: "https://example.com/theirs", Kind: FeedKindRSS}
+
+	tests := []struct {
+		name     string
+		feed     database.Feed
+		body     string
+		inUse    bool
+		want     int
+		wantName string
+		wantURL  string
+	}{
+		{"rename", feed, `{"name": " New "}`, false, http.StatusOK, "New", feed.Url},
+		{"move", feed, `{"url": "https://example.com/new"}`, false, http.StatusOK, feed.Name, "https://example.com/new"},
+		{"same url", feed, `{"url": "https://example.com/old"}`, true, http.StatusOK, feed.Name, feed.Url},
+		{"url in use", feed, `{"url": "https://example.com/new"}`, true, http.StatusConflict, "", ""},
+		{"empty name", feed, `{"name": "  "}`, false, http.StatusBadRequest, "", ""},
+		{"empty url", feed, `{"url": " "}`, false, http.StatusBadRequest, "", ""},
+		{"newsletter url", newsletter, `{"url": "https://example.com/new"}`, false, http.StatusBadRequest, "", ""},
+		{"newsletter rename", newsletter, `{"name": "News"}`, false, http.StatusOK, "News", newsletter.Url},
+		{"not the creator", others, `{"name": "Mine"}`, false, http.StatusForbidden, "", ""},
+		{"bad json", feed, `{`, false, http.StatusBadRequest, "", ""},
+	}
+	for _, tt := range tests {
+		cfg, db := newTestConfig(t)
+		db.returns("GetFeedVisibleToUser", tt.feed)
+		db.returns("FeedURLInUse", tt.inUse)
+		db.on("UpdateFeed", func(args []driver.Value) fakeResult {
+			updated := tt.feed
+			updated.Name, updated.Url = args[1].(string), args[2].(string)
+			return fakeResult{Rows: [][]driver.Value{fakeRow(updated)}}
+		})
+
+		rec := serveAuthed(cfg.handlerFeedUpdate, user, http.MethodPatch, "/", tt.body, map[string]string{"feedID": tt.feed.ID.String()})
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body)
+			continue
+		}
+		updates := db.called("UpdateFeed")
+		if tt.want != http.StatusOK {
+			if len(updates) != 0 {
+				t.Errorf("%s: expected no update, got %+v", tt.name, updates)
+			}
+			continue
+		}
+		if len(updates) != 1 || argUUID(t, updates[0].Args[0]) != tt.feed.ID || updates[0].Args[1] != tt.wantName || updates[0].Args[2] != tt.wantURL {
+			t.Errorf("%s: unexpected UpdateFeed calls %+v", tt.name, updates)
+		}
+	}
+}
+
+func TestHandlerFeedDelete(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	feed := database.Feed{ID: uuid.New(), UserID: user.ID, Name: "Mine", Kind: FeedKindRSS}
+	params := map[string]string{"feedID": feed.ID.String()}
+
+	cfg, db := newTestConfig(t)
+	db.returns("GetFeedVisibleToUser", feed)
+	db.affects("SoftDeleteFeed", 1)
+	db.returns("DeleteFeedFollowsForFeed", user.ID, uuid.New())
+	db.affects("DeleteStarredFeedsForFeed", 1)
+	db.affects("DeleteInboundAddressesForFeed", 0)
+	rec := serveAuthed(cfg.handlerFeedDelete, user, http.MethodDelete, "/", "", params)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	deletes := db.called("SoftDeleteFeed")
+	if len(deletes) != 1 || argUUID(t, deletes[0].Args[0]) != feed.ID || deletes[0].Args[1] == nil {
+		t.Errorf("expected %s soft deleted, got %+v", feed.ID, deletes)
+	}
+	for _, name := range []string{"DeleteFeedFollowsForFeed", "DeleteStarredFeedsForFeed", "DeleteInboundAddressesForFeed"} {
+		if calls := db.called(name); len(calls) != 1 || argUUID(t, calls[0].Args[0]) != feed.ID {
+			t.Errorf("expected %s for %s, got %+v", name, feed.ID, calls)
+		}
+	}
+	if db.commits != 1 {
+		t.Errorf("expected one commit, got %d", db.commits)
+	}
+
+	// Only the creator may delete a feed.
+	cfg, db = newTestConfig(t)
+	db.returns("GetFeedVisibleToUser", database.Feed{ID: feed.ID, UserID: uuid.New()})
+	rec = serveAuthed(cfg.handlerFeedDelete, user, http.MethodDelete, "/", "", params)
+	if rec.Code != http.StatusForbidden || len(db.called("SoftDeleteFeed")) != 0 {
+		t.Errorf("expected 403 without deleting, got %d", rec.Code)
+	}
+
+	// A feed the user can't see isn't there.
+	cfg, db = newTestConfig(t)
+	db.returns("GetFeedVisibleToUser")
+	rec = serveAuthed(cfg.handlerFeedDelete, user, http.MethodDelete, "/", "", params)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+// filterRuleTestSize is how many of the user's latest posts a rule is tested
+// against.
+const filterRuleTestSize = 100
+
+type FilterRule struct {
+	ID         uuid.UUID         `json:"id"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Name       string            `json:"name"`
+	Enabled    bool              `json:"enabled"`
+	MatchAny   bool              `json:"match_any"`
+	Conditions []FilterCondition `json:"conditions"`
+	Actions    []FilterAction    `json:"actions"`
+}
+
+func databaseFilterRuleToFilterRule(rule database.FilterRule) FilterRule {
+	result := FilterRule{
+		ID:        rule.ID,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+		Name:      rule.Name,
+		Enabled:   rule.Enabled,
+		MatchAny:  rule.MatchAny,
+	}
+	json.Unmarshal(rule.Conditions, &result.Conditions)
+	json.Unmarshal(rule.Actions, &result.Actions)
+	return result
+}
+
+func (cfg *apiConfig) ownFilterRule(w http.ResponseWriter, r *http.Request, user database.User) (database.FilterRule, bool) {
+	ruleID, err := uuid.Parse(chi.URLParam(r, "ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID format")
+		return database.FilterRule{}, false
+	}
+	rule, err := cfg.DB.GetFilterRuleForUser(r.Context(), database.GetFilterRuleForUserParams{
+		ID:     ruleID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Rule not found")
+		return database.FilterRule{}, false
+	}
+	return rule, true
+}
+
+// validateFilterRule checks a rule's conditions and actions, writing the
+// error response itself. Labels must be the user's own.
+func (cfg *apiConfig) validateFilterRule(w http.ResponseWriter, r *http.Request, user database.User, conditions []FilterCondition, actions []FilterAction) bool {
+	if _, err := compileFilterConditions(conditions); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	if err := validateFilterActions(actions); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	for _, action := range actions {
+		if action.Type != FilterActionLabel {
+			continue
+		}
+		_, err := cfg.DB.GetLabelForUser(r.Context(), database.GetLabelForUserParams{
+			ID:     *action.LabelID,
+			UserID: user.ID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Label not found")
+			return false
+		}
+	}
+	return true
+}
+
+// saveFilterRule stores a created or changed rule and re-applies it to the
+// user's latest posts in one transaction.
+func (cfg *apiConfig) saveFilterRule(r *http.Request, save func(*database.Queries) (database.FilterRule, error)) (database.FilterRule, error) {
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		return database.FilterRule{}, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
+
+	rule, err := save(qtx)
+	if err != nil {
+		return database.FilterRule{}, err
+	}
+	if err := reapplyFilterRule(r.Context(), qtx, rule); err != nil {
+		return database.FilterRule{}, err
+	}
+	return rule, tx.Commit()
+}
+
+func (cfg *apiConfig) handlerFilterRulesGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	rules, err := cfg.DB.GetFilterRulesForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get rules")
+		return
+	}
+
+	result := make([]FilterRule, len(rules))
+	for i, rule := range rules {
+		result[i] = databaseFilterRuleToFilterRule(rule)
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// handlerFilterRuleCreate adds a rule and applies it to the user's latest
+// posts as well as to new ones.
+func (cfg *apiConfig) handlerFilterRuleCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Name       string            `json:"name"`
+		Enabled    *bool             `json:"enabled"`
+		MatchAny   bool              `json:"match_any"`
+		Conditions []FilterCondition `json:"conditions"`
+		Actions    []FilterAction    `json:"actions"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Rule name can't be empty")
+		return
+	}
+	if !cfg.validateFilterRule(w, r, user, params.Conditions, params.Actions) {
+		return
+	}
+	enabled := true
+	if params.Enabled != nil {
+		enabled = *params.Enabled
+	}
+	conditions, _ := json.Marshal(params.Conditions)
+	actions, _ := json.Marshal(params.Actions)
+
+	rule, err := cfg.saveFilterRule(r, func(qtx *database.Queries) (database.FilterRule, error) {
+		return qtx.CreateFilterRule(r.Context(), database.CreateFilterRuleParams{
+			ID:         uuid.New(),
+			CreatedAt:  time.Now().UTC(),
+			UpdatedAt:  time.Now().UTC(),
+			UserID:     user.ID,
+			Name:       params.Name,
+			Enabled:    enabled,
+			MatchAny:   params.MatchAny,
+			Conditions: conditions,
+			Actions:    actions,
+		})
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create rule")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseFilterRuleToFilterRule(rule))
+}
+
+// handlerFilterRuleUpdate changes a rule. Posts it hid come back and the new
+// version is applied to the user's latest posts; other actions already taken
+// stay.
+func (cfg *apiConfig) handlerFilterRuleUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	rule, ok := cfg.ownFilterRule(w, r, user)
+	if !ok {
+		return
+	}
+	type parameters struct {
+		Name       *string            `json:"name"`
+		Enabled    *bool              `json:"enabled"`
+		MatchAny   *bool              `json:"match_any"`
+		Conditions *[]FilterCondition `json:"conditions"`
+		Actions    *[]FilterAction    `json:"actions"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	current := databaseFilterRuleToFilterRule(rule)
+	if params.Name != nil {
+		current.Name = strings.TrimSpace(*params.Name)
+		if current.Name == "" {
+			respondWithError(w, http.StatusBadRequest, "Rule name can't be empty")
+			return
+		}
+	}
+	if params.Enabled != nil {
+		current.Enabled = *params.Enabled
+	}
+	if params.MatchAny != nil {
+		current.MatchAny = *params.MatchAny
+	}
+	if params.Conditions != nil {
+		current.Conditions = *params.Conditions
+	}
+	if params.Actions != nil {
+		current.Actions = *params.Actions
+	}
+	if !cfg.validateFilterRule(w, r, user, current.Conditions, current.Actions) {
+		return
+	}
+	conditions, _ := json.Marshal(current.Conditions)
+	actions, _ := json.Marshal(current.Actions)
+
+	updated, err := cfg.saveFilterRule(r, func(qtx *database.Queries) (database.FilterRule, error) {
+		return qtx.UpdateFilterRule(r.Context(), database.UpdateFilterRuleParams{
+			ID:         rule.ID,
+			Name:       current.Name,
+			Enabled:    current.Enabled,
+			MatchAny:   current.MatchAny,
+			Conditions: conditions,
+			Actions:    actions,
+			UpdatedAt:  time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update rule")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseFilterRuleToFilterRule(updated))
+}
+
+// handlerFilterRuleDelete deletes a rule, bringing back the posts it hid.
+func (cfg *apiConfig) handlerFilterRuleDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	rule, ok := cfg.ownFilterRule(w, r, user)
+	if !ok {
+		return
+	}
+	if err := cfg.DB.DeleteFilterRule(r.Context(), rule.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete rule")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
+
+// testFilterConditions responds with those of the user's latest posts the
+// conditions match, without taking any actions.
+func (cfg *apiConfig) testFilterConditions(w http.ResponseWriter, r *http.Request, user database.User, conditions []FilterCondition, matchAny bool) {
+	compiled, err := compileFilterConditions(conditions)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	posts, err := cfg.DB.GetRecentPostsForUser(r.Context(), database.GetRecentPostsForUserParams{
+		UserID: user.ID,
+		Limit:  filterRuleTestSize,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get posts")
+		return
+	}
+
+	matched := []database.Post{}
+	for _, post := range posts {
+		if matchFilterConditions(compiled, matchAny, post) {
+			matched = append(matched, post)
+		}
+	}
+	respondWithJSON(w, http.StatusOK, struct {
+		Tested  int    `json:"tested"`
+		Matched []Post `json:"matched"`
+	}{
+		Tested:  len(posts),
+		Matched: databasePostsToPosts(matched),
+	})
+}
+
+// handlerFilterRuleTest tries unsaved conditions against the user's latest
+// posts.
+func (cfg *apiConfig) handlerFilterRuleTest(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		MatchAny   bool              `json:"match_any"`
+		Conditions []FilterCondition `json:"conditions"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	cfg.testFilterConditions(w, r, user, params.Conditions, params.MatchAny)
+}
+
+// handlerFilterRuleTestSaved tries a saved rule against the user's latest
+// posts.
+func (cfg *apiConfig) handlerFilterRuleTestSaved(w http.ResponseWriter, r *http.Request, user database.User) {
+	rule, ok := cfg.ownFilterRule(w, r, user)
+	if !ok {
+		return
+	}
+	current := databaseFilterRuleToFilterRule(rule)
+	cfg.testFilterConditions(w, r, user, current.Conditions, current.MatchAny)
+}
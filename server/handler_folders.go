@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+type Folder struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	Name      string     `json:"name"`
+	Position  int32      `json:"position"`
+}
+
+func databaseFolderToFolder(folder database.Folder) Folder {
+	return Folder{
+		ID:        folder.ID,
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+		ParentID:  nullUUIDToUUIDPtr(folder.ParentID),
+		Name:      folder.Name,
+		Position:  folder.Position,
+	}
+}
+
+// optionalUUID tells a field left out of a PATCH body, which keeps its value,
+// from an explicit null, which clears it.
+type optionalUUID struct {
+	Set   bool
+	Value uuid.NullUUID
+}
+
+func (o *optionalUUID) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+func (cfg *apiConfig) ownFolder(w http.ResponseWriter, r *http.Request, user database.User) (database.Folder, bool) {
+	folderID, err := uuid.Parse(chi.URLParam(r, "folderID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid folder ID format")
+		return database.Folder{}, false
+	}
+	folder, err := cfg.DB.GetFolderForUser(r.Context(), database.GetFolderForUserParams{
+		ID:     folderID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Folder not found")
+		return database.Folder{}, false
+	}
+	return folder, true
+}
+
+// validFolderParent checks that folder may be put inside parentID, keeping
+// folders one level deep. folder is uuid.Nil for a folder being created.
+func (cfg *apiConfig) validFolderParent(w http.ResponseWriter, r *http.Request, user database.User, folder, parentID uuid.UUID) bool {
+	if parentID == folder {
+		respondWithError(w, http.StatusBadRequest, "A folder can't contain itself")
+		return false
+	}
+	parent, err := cfg.DB.GetFolderForUser(r.Context(), database.GetFolderForUserParams{
+		ID:     parentID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Parent folder not found")
+		return false
+	}
+	if parent.ParentID.Valid {
+		respondWithError(w, http.StatusBadRequest, "Folders can only be nested one level deep")
+		return false
+	}
+	if folder != uuid.Nil {
+		hasSubfolders, err := cfg.DB.FolderHasSubfolders(r.Context(), uuid.NullUUID{UUID: folder, Valid: true})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't check subfolders")
+			return false
+		}
+		if hasSubfolders {
+			respondWithError(w, http.StatusBadRequest, "A folder with subfolders can't be nested")
+			return false
+		}
+	}
+	return true
+}
+
+func (cfg *apiConfig) handlerFoldersGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	folders, err := cfg.DB.GetFoldersForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get folders")
+		return
+	}
+
+	result := make([]Folder, len(folders))
+	for i, folder := range folders {
+		result[i] = databaseFolderToFolder(folder)
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func (cfg *apiConfig) handlerFolderCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Name     string     `json:"name"`
+		ParentID *uuid.UUID `json:"parent_id"`
+		Position int32      `json:"position"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "Folder name can't be empty")
+		return
+	}
+	var parentID uuid.NullUUID
+	if params.ParentID != nil {
+		if !cfg.validFolderParent(w, r, user, uuid.Nil, *params.ParentID) {
+			return
+		}
+		parentID = uuid.NullUUID{UUID: *params.ParentID, Valid: true}
+	}
+
+	folder, err := cfg.DB.CreateFolder(r.Context(), database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		ParentID:  parentID,
+		Name:      params.Name,
+		Position:  params.Position,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create folder")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseFolderToFolder(folder))
+}
+
+// handlerFolderUpdate renames, reorders or moves a folder. parent_id null
+// moves it to the top level.
+func (cfg *apiConfig) handlerFolderUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	folder, ok := cfg.ownFolder(w, r, user)
+	if !ok {
+		return
+	}
+	type parameters struct {
+		Name     *string      `json:"name"`
+		ParentID optionalUUID `json:"parent_id"`
+		Position *int32       `json:"position"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if params.Name != nil {
+		folder.Name = strings.TrimSpace(*params.Name)
+		if folder.Name == "" {
+			respondWithError(w, http.StatusBadRequest, "Folder name can't be empty")
+			return
+		}
+	}
+	if params.ParentID.Set {
+		if params.ParentID.Value.Valid && params.ParentID.Value != folder.ParentID {
+			if !cfg.validFolderParent(w, r, user, folder.ID, params.ParentID.Value.UUID) {
+				return
+			}
+		}
+		folder.ParentID = params.ParentID.Value
+	}
+	if params.Position != nil {
+		folder.Position = *params.Position
+	}
+
+	updated, err := cfg.DB.UpdateFolder(r.Context(), database.UpdateFolderParams{
+		ID:        folder.ID,
+		ParentID:  folder.ParentID,
+		Name:      folder.Name,
+		Position:  folder.Position,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update folder")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseFolderToFolder(updated))
+}
+
+// handlerFolderDelete deletes a folder and its subfolders. Their feeds stay
+// followed, at the top level.
+func (cfg *apiConfig) handlerFolderDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	folder, ok := cfg.ownFolder(w, r, user)
+	if !ok {
+		return
+	}
+	if err := cfg.DB.DeleteFolder(r.Context(), folder.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete folder")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
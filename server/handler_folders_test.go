@@ -0,0 +1,178 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+// answerFolders answers GetFolderForUser from folders, by ID.
+func answerFolders(t *testing.T, db *fakeDB, folders ...database.Folder) {
+	db.on("GetFolderForUser", func(args []driver.Value) fakeResult {
+		id := argUUID(t, args[0])
+		for _, folder := range folders {
+			if folder.ID == id {
+				return fakeResult{Rows: [][]driver.Value{fakeRow(folder)}}
+			}
+		}
+		return fakeResult{}
+	})
+}
+
+func TestValidFolderParent(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	top := database.Folder{ID: uuid.New(), UserID: user.ID, Name: "Top"}
+	nested := database.Folder{ID: uuid.New(), UserID: user.ID, Name: "Nested", ParentID: uuid.NullUUID{UUID: top.ID, Valid: true}}
+	folder := uuid.New()
+
+	tests := []struct {
+		name          string
+		folder        uuid.UUID
+		parent        uuid.UUID
+		hasSubfolders bool
+		want          int
+	}{
+		{"new folder", uuid.Nil, top.ID, false, http.StatusOK},
+		{"existing folder", folder, top.ID, false, http.StatusOK},
+		{"itself", folder, folder, false, http.StatusBadRequest},
+		{"missing parent", folder, uuid.New(), false, http.StatusNotFound},
+		{"two levels deep", folder, nested.ID, false, http.StatusBadRequest},
+		{"folder with subfolders", folder, top.ID, true, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		cfg, db := newTestConfig(t)
+		answerFolders(t, db, top, nested)
+		db.returns("FolderHasSubfolders", tt.hasSubfolders)
+
+		rec := httptest.NewRecorder()
+		ok := cfg.validFolderParent(rec, httptest.NewRequest(http.MethodPost, "/", nil), user, tt.folder, tt.parent)
+		if ok != (tt.want == http.StatusOK) || rec.Code != tt.want {
+			t.Errorf("%s: got ok=%v, status %d, want status %d", tt.name, ok, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestHandlerFolderCreate(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	top := database.Folder{ID: uuid.New(), UserID: user.ID, Name: "Top"}
+	createFolder := func(args []driver.Value) fakeResult {
+		return fakeResult{Rows: [][]driver.Value{fakeRow(database.Folder{
+			ID:       argUUID(t, args[0]),
+			UserID:   argUUID(t, args[3]),
+			ParentID: uuid.NullUUID{UUID: top.ID, Valid: args[4] != nil},
+			Name:     args[5].(string),
+		})}}
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		want       int
+		wantParent bool
+	}{
+		{"top level", `{"name": " News "}`, http.StatusCreated, false},
+		{"subfolder", `{"name": "News", "parent_id": "` + top.ID.String() + `"}`, http.StatusCreated, true},
+		{"empty name", `{"name": " "}`, http.StatusBadRequest, false},
+		{"missing parent", `{"name": "News", "parent_id": "` + uuid.NewString() + `"}`, http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		cfg, db := newTestConfig(t)
+		answerFolders(t, db, top)
+		db.on("CreateFolder", createFolder)
+
+		rec := serveAuthed(cfg.handlerFolderCreate, user, http.MethodPost, "/", tt.body, nil)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body)
+			continue
+		}
+		if tt.want != http.StatusCreated {
+			if len(db.called("CreateFolder")) != 0 {
+				t.Errorf("%s: expected no folder created", tt.name)
+			}
+			continue
+		}
+		var got Folder
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != "News" || (got.ParentID != nil) != tt.wantParent {
+			t.Errorf("%s: unexpected folder %+v", tt.name, got)
+		}
+	}
+}
+
+func TestHandlerFolderUpdate(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	top := database.Folder{ID: uuid.New(), UserID: user.ID, Name: "Top"}
+	other := database.Folder{ID: uuid.New(), UserID: user.ID, Name: "Other"}
+	folder := database.Folder{ID: uuid.New(), UserID: user.ID, Name: "Folder", ParentID: uuid.NullUUID{UUID: top.ID, Valid: true}, Position: 2}
+
+	tests := []struct {
+		name         string
+		body         string
+		want         int
+		wantParent   any
+		wantName     string
+		wantPosition int64
+	}{
+		{"rename keeps parent", `{"name": " Renamed "}`, http.StatusOK, top.ID.String(), "Renamed", 2},
+		{"null parent moves to top", `{"parent_id": null}`, http.StatusOK, nil, "Folder", 2},
+		{"move", `{"parent_id": "` + other.ID.String() + `", "position": 0}`, http.StatusOK, other.ID.String(), "Folder", 0},
+		{"into itself", `{"parent_id": "` + folder.ID.String() + `"}`, http.StatusBadRequest, nil, "", 0},
+		{"empty name", `{"name": ""}`, http.StatusBadRequest, nil, "", 0},
+	}
+	for _, tt := range tests {
+		cfg, db := newTestConfig(t)
+		answerFolders(t, db, top, other, folder)
+		db.returns("FolderHasSubfolders", false)
+		db.on("UpdateFolder", func(args []driver.Value) fakeResult {
+			return fakeResult{Rows: [][]driver.Value{fakeRow(folder)}}
+		})
+
+		rec := serveAuthed(cfg.handlerFolderUpdate, user, http.MethodPatch, "/", tt.body, map[string]string{"folderID": folder.ID.String()})
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body)
+			continue
+		}
+		updates := db.called("UpdateFolder")
+		if tt.want != http.StatusOK {
+			if len(updates) != 0 {
+				t.Errorf("%s: expected no update, got %+v", tt.name, updates)
+			}
+			continue
+		}
+		if len(updates) != 1 {
+			t.Fatalf("%s: expected one update, got %d", tt.name, len(updates))
+		}
+		args := updates[0].Args
+		if args[1] != tt.wantParent || args[2] != tt.wantName || args[3] != tt.wantPosition {
+			t.Errorf("%s: unexpected UpdateFolder args %+v", tt.name, args)
+		}
+	}
+}
+
+func TestHandlerFolderDelete(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	folder := database.Folder{ID: uuid.New(), UserID: user.ID, Name: "Folder"}
+
+	cfg, db := newTestConfig(t)
+	answerFolders(t, db, folder)
+	db.affects("DeleteFolder", 1)
+	rec := serveAuthed(cfg.handlerFolderDelete, user, http.MethodDelete, "/", "", map[string]string{"folderID": folder.ID.String()})
+	if calls := db.called("DeleteFolder"); rec.Code != http.StatusOK || len(calls) != 1 || argUUID(t, calls[0].Args[0]) != folder.ID {
+		t.Errorf("expected %s deleted, got %d and %+v", folder.ID, rec.Code, calls)
+	}
+
+	// Another user's folder isn't found.
+	cfg, db = newTestConfig(t)
+	answerFolders(t, db)
+	rec = serveAuthed(cfg.handlerFolderDelete, user, http.MethodDelete, "/", "", map[string]string{"folderID": folder.ID.String()})
+	if rec.Code != http.StatusNotFound || len(db.called("DeleteFolder")) != 0 {
+		t.Errorf("expected 404 without deleting, got %d", rec.Code)
+	}
+}
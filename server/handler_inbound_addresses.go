@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+type InboundAddress struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	FeedID    uuid.UUID `json:"feed_id"`
+	Address   string    `json:"address"`
+}
+
+func databaseInboundAddressToInboundAddress(address database.InboundAddress, domain string) InboundAddress {
+	return InboundAddress{
+		ID:        address.ID,
+		CreatedAt: address.CreatedAt,
+		FeedID:    address.FeedID,
+		Address:   inboundAddress(address.Token, domain),
+	}
+}
+
+// handlerInboundAddressCreate creates a private newsletter feed, follows it
+// for the user and hands out the address that delivers into it.
+func (cfg *apiConfig) handlerInboundAddressCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Name string `json:"name"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if params.Name == "" {
+		params.Name = "Newsletters"
+	}
+	if cfg.InboundDomain == "" {
+		respondWithError(w, http.StatusServiceUnavailable, "Inbound email is not configured on this server")
+		return
+	}
+
+	tokenBytes := make([]byte, 8)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't generate address")
+		return
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create inbound address")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
+
+	feed, err := qtx.CreateFeed(r.Context(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		Name:      params.Name,
+		Url:       "mailto:" + inboundAddress(token, cfg.InboundDomain),
+		Kind:      FeedKindNewsletter,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create newsletter feed")
+		return
+	}
+	_, err = qtx.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't follow newsletter feed")
+		return
+	}
+	address, err := qtx.CreateInboundAddress(r.Context(), database.CreateInboundAddressParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+		Token:     token,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create inbound address")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create inbound address")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseInboundAddressToInboundAddress(address, cfg.InboundDomain))
+}
+
+func (cfg *apiConfig) handlerInboundAddressesGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	addresses, err := cfg.DB.GetInboundAddressesForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get inbound addresses")
+		return
+	}
+
+	result := make([]InboundAddress, len(addresses))
+	for i, address := range addresses {
+		result[i] = databaseInboundAddressToInboundAddress(address, cfg.InboundDomain)
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// handlerInboundAddressDelete stops mail delivery but keeps the feed and the
+// posts already received.
+func (cfg *apiConfig) handlerInboundAddressDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	addressID, err := uuid.Parse(chi.URLParam(r, "addressID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid inbound address ID")
+		return
+	}
+
+	err = cfg.DB.DeleteInboundAddress(r.Context(), database.DeleteInboundAddressParams{
+		ID:     addressID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete inbound address")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
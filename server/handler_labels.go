@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultLabelColor  = "#6b7280"
+	maxLabelNameLength = 50
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type Label struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	PostCount int64     `json:"post_count"`
+}
+
+func databaseLabelToLabel(label database.Label, postCount int64) Label {
+	return Label{
+		ID:        label.ID,
+		CreatedAt: label.CreatedAt,
+		UpdatedAt: label.UpdatedAt,
+		Name:      label.Name,
+		Color:     label.Color,
+		PostCount: postCount,
+	}
+}
+
+func labelFromRow(row database.GetLabelForUserRow) Label {
+	return databaseLabelToLabel(database.Label{
+		ID:        row.ID,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+		UserID:    row.UserID,
+		Name:      row.Name,
+		Color:     row.Color,
+	}, row.PostCount)
+}
+
+// ownLabel loads the user's label named by a URL parameter, answering 404 for
+// anyone else's.
+func (cfg *apiConfig) ownLabel(w http.ResponseWriter, r *http.Request, user database.User, param string) (database.GetLabelForUserRow, bool) {
+	labelID, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid label ID format")
+		return database.GetLabelForUserRow{}, false
+	}
+	label, err := cfg.DB.GetLabelForUser(r.Context(), database.GetLabelForUserParams{
+		ID:     labelID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Label not found")
+		return database.GetLabelForUserRow{}, false
+	}
+	return label, true
+}
+
+// validateLabel checks a label's name and colour, writing the error response
+// itself. except is the label being renamed, which may keep its own name.
+func (cfg *apiConfig) validateLabel(w http.ResponseWriter, r *http.Request, user database.User, name, color string, except uuid.UUID) bool {
+	if name == "" || utf8.RuneCountInString(name) > maxLabelNameLength {
+		respondWithError(w, http.StatusBadRequest, "Label name must be 1 to 50 characters")
+		return false
+	}
+	if !labelColorPattern.MatchString(color) {
+		respondWithError(w, http.StatusBadRequest, "Label color must look like #1a2b3c")
+		return false
+	}
+	existing, err := cfg.DB.GetLabelByName(r.Context(), database.GetLabelByNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err == nil && existing.ID != except {
+		respondWithError(w, http.StatusConflict, "You already have a label with that name")
+		return false
+	}
+	return true
+}
+
+func (cfg *apiConfig) handlerLabelsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	rows, err := cfg.DB.GetLabelsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get labels")
+		return
+	}
+
+	labels := make([]Label, len(rows))
+	for i, row := range rows {
+		labels[i] = labelFromRow(database.GetLabelForUserRow(row))
+	}
+	respondWithJSON(w, http.StatusOK, labels)
+}
+
+func (cfg *apiConfig) handlerLabelCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Color == "" {
+		params.Color = defaultLabelColor
+	}
+	if !cfg.validateLabel(w, r, user, params.Name, params.Color, uuid.Nil) {
+		return
+	}
+
+	label, err := cfg.DB.CreateLabel(r.Context(), database.CreateLabelParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		Name:      params.Name,
+		Color:     strings.ToLower(params.Color),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create label")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databaseLabelToLabel(label, 0))
+}
+
+// handlerLabelUpdate renames or recolours a label. Fields left out keep
+// their values.
+func (cfg *apiConfig) handlerLabelUpdate(w http.ResponseWriter, r *http.Request, user database.User) {
+	label, ok := cfg.ownLabel(w, r, user, "labelID")
+	if !ok {
+		return
+	}
+	type parameters struct {
+		Name  *string `json:"name"`
+		Color *string `json:"color"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if params.Name != nil {
+		label.Name = strings.TrimSpace(*params.Name)
+	}
+	if params.Color != nil {
+		label.Color = *params.Color
+	}
+	if !cfg.validateLabel(w, r, user, label.Name, label.Color, label.ID) {
+		return
+	}
+
+	label.Color = strings.ToLower(label.Color)
+	label.UpdatedAt = time.Now().UTC()
+	err := cfg.DB.UpdateLabel(r.Context(), database.UpdateLabelParams{
+		ID:        label.ID,
+		Name:      label.Name,
+		Color:     label.Color,
+		UpdatedAt: label.UpdatedAt,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update label")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, labelFromRow(label))
+}
+
+func (cfg *apiConfig) handlerLabelDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	label, ok := cfg.ownLabel(w, r, user, "labelID")
+	if !ok {
+		return
+	}
+	if err := cfg.DB.DeleteLabel(r.Context(), label.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete label")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
+
+// handlerLabelMerge moves every post of the labelID label onto the
+// {"into": ...} label and deletes the labelID label.
+func (cfg *apiConfig) handlerLabelMerge(w http.ResponseWriter, r *http.Request, user database.User) {
+	from, ok := cfg.ownLabel(w, r, user, "labelID")
+	if !ok {
+		return
+	}
+	type parameters struct {
+		Into uuid.UUID `json:"into"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if params.Into == from.ID {
+		respondWithError(w, http.StatusBadRequest, "Can't merge a label into itself")
+		return
+	}
+	if _, err := cfg.DB.GetLabelForUser(r.Context(), database.GetLabelForUserParams{
+		ID:     params.Into,
+		UserID: user.ID,
+	}); err != nil {
+		respondWithError(w, http.StatusNotFound, "Label to merge into not found")
+		return
+	}
+
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't merge labels")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
+
+	err = qtx.CopyPostLabels(r.Context(), database.CopyPostLabelsParams{
+		ToLabelID:   params.Into,
+		FromLabelID: from.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't merge labels")
+		return
+	}
+	if err := qtx.DeleteLabel(r.Context(), from.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't merge labels")
+		return
+	}
+	into, err := qtx.GetLabelForUser(r.Context(), database.GetLabelForUserParams{
+		ID:     params.Into,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't merge labels")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't merge labels")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, labelFromRow(into))
+}
+
+func (cfg *apiConfig) handlerPostLabelAdd(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, ok := cfg.visiblePost(w, r, user)
+	if !ok {
+		return
+	}
+	label, ok := cfg.ownLabel(w, r, user, "labelID")
+	if !ok {
+		return
+	}
+	err := cfg.DB.AddPostLabel(r.Context(), database.AddPostLabelParams{
+		LabelID:   label.ID,
+		PostID:    post.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't label post")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
+
+// handlerPostLabelRemove takes a label off a post. Like unsaving, it doesn't
+// check that the post is still visible, so a label can be removed from a post
+// whose feed was deleted or made private since.
+func (cfg *apiConfig) handlerPostLabelRemove(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := uuid.Parse(chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid post ID format")
+		return
+	}
+	label, ok := cfg.ownLabel(w, r, user, "labelID")
+	if !ok {
+		return
+	}
+	removed, err := cfg.DB.RemovePostLabel(r.Context(), database.RemovePostLabelParams{
+		LabelID: label.ID,
+		PostID:  postID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove label")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, http.StatusNotFound, "Post doesn't have that label")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestValidateLabel(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	labelID := uuid.New()
+	tests := []struct {
+		name     string
+		label    string
+		color    string
+		existing []any
+		except   uuid.UUID
+		want     int
+	}{
+		{"valid", "Reading", "#1A2b3c", nil, uuid.Nil, http.StatusOK},
+		{"empty name", "", "#1a2b3c", nil, uuid.Nil, http.StatusBadRequest},
+		{"long name", strings.Repeat("é", maxLabelNameLength+1), "#1a2b3c", nil, uuid.Nil, http.StatusBadRequest},
+		{"longest name", strings.Repeat("é", maxLabelNameLength), "#1a2b3c", nil, uuid.Nil, http.StatusOK},
+		{"short color", "Reading", "#abc", nil, uuid.Nil, http.StatusBadRequest},
+		{"named color", "Reading", "red", nil, uuid.Nil, http.StatusBadRequest},
+		{"taken name", "Reading", "#1a2b3c", []any{database.Label{ID: uuid.New(), Name: "reading"}}, uuid.Nil, http.StatusConflict},
+		{"own name", "Reading", "#1a2b3c", []any{database.Label{ID: labelID, Name: "Reading"}}, labelID, http.StatusOK},
+	}
+	for _, tt := range tests {
+		cfg, db := newTestConfig(t)
+		db.returns("GetLabelByName", tt.existing...)
+
+		rec := httptest.NewRecorder()
+		ok := cfg.validateLabel(rec, httptest.NewRequest(http.MethodPost, "/", nil), user, tt.label, tt.color, tt.except)
+		if ok != (tt.want == http.StatusOK) || rec.Code != tt.want {
+			t.Errorf("%s: got ok=%v, status %d, want status %d", tt.name, ok, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestHandlerLabelUpdateRenames(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	label := database.GetLabelForUserRow{ID: uuid.New(), UserID: user.ID, Name: "Go", Color: "#00add8", PostCount: 3}
+	params := map[string]string{"labelID": label.ID.String()}
+
+	cfg, db := newTestConfig(t)
+	db.returns("GetLabelForUser", label)
+	db.returns("GetLabelByName")
+	db.affects("UpdateLabel", 1)
+	rec := serveAuthed(cfg.handlerLabelUpdate, user, http.MethodPatch, "/", `{"name": "  Golang ", "color": "#ABCDEF"}`, params)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var got Label
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Golang" || got.Color != "#abcdef" || got.PostCount != 3 {
+		t.Errorf("unexpected label %+v", got)
+	}
+	updates := db.called("UpdateLabel")
+	if len(updates) != 1 || argUUID(t, updates[0].Args[0]) != label.ID || updates[0].Args[1] != "Golang" || updates[0].Args[2] != "#abcdef" {
+		t.Errorf("unexpected UpdateLabel calls %+v", updates)
+	}
+
+	// Leaving the colour out keeps it.
+	cfg, db = newTestConfig(t)
+	db.returns("GetLabelForUser", label)
+	db.returns("GetLabelByName")
+	db.affects("UpdateLabel", 1)
+	rec = serveAuthed(cfg.handlerLabelUpdate, user, http.MethodPatch, "/", `{"name": "Golang"}`, params)
+	if updates := db.called("UpdateLabel"); rec.Code != http.StatusOK || len(updates) != 1 || updates[0].Args[2] != "#00add8" {
+		t.Errorf("expected the colour to be kept, got %d and %+v", rec.Code, updates)
+	}
+
+	// Renaming onto another label's name is refused.
+	cfg, db = newTestConfig(t)
+	db.returns("GetLabelForUser", label)
+	db.returns("GetLabelByName", database.Label{ID: uuid.New(), Name: "golang"})
+	rec = serveAuthed(cfg.handlerLabelUpdate, user, http.MethodPatch, "/", `{"name": "Golang"}`, params)
+	if rec.Code != http.StatusConflict || len(db.called("UpdateLabel")) != 0 {
+		t.Errorf("expected 409 without an update, got %d and %d updates", rec.Code, len(db.called("UpdateLabel")))
+	}
+}
+
+func TestHandlerLabelMerge(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	from := database.GetLabelForUserRow{ID: uuid.New(), UserID: user.ID, Name: "golang", PostCount: 2}
+	into := database.GetLabelForUserRow{ID: uuid.New(), UserID: user.ID, Name: "Go", PostCount: 5}
+	params := map[string]string{"labelID": from.ID.String()}
+
+	t.Run("into itself", func(t *testing.T) {
+		cfg, db := newTestConfig(t)
+		db.returns("GetLabelForUser", from)
+		rec := serveAuthed(cfg.handlerLabelMerge, user, http.MethodPost, "/", `{"into": "`+from.ID.String()+`"}`, params)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("into someone else's label", func(t *testing.T) {
+		cfg, db := newTestConfig(t)
+		db.on("GetLabelForUser", func(args []driver.Value) fakeResult {
+			if argUUID(t, args[0]) == from.ID {
+				return fakeResult{Rows: [][]driver.Value{fakeRow(from)}}
+			}
+			return fakeResult{}
+		})
+		rec := serveAuthed(cfg.handlerLabelMerge, user, http.MethodPost, "/", `{"into": "`+uuid.NewString()+`"}`, params)
+		if rec.Code != http.StatusNotFound || len(db.called("CopyPostLabels")) != 0 {
+			t.Errorf("expected 404 without copying, got %d", rec.Code)
+		}
+	})
+
+	t.Run("merges", func(t *testing.T) {
+		cfg, db := newTestConfig(t)
+		db.on("GetLabelForUser", func(args []driver.Value) fakeResult {
+			if argUUID(t, args[0]) == from.ID {
+				return fakeResult{Rows: [][]driver.Value{fakeRow(from)}}
+			}
+			return fakeResult{Rows: [][]driver.Value{fakeRow(into)}}
+		})
+		db.affects("CopyPostLabels", 2)
+		db.affects("DeleteLabel", 1)
+		rec := serveAuthed(cfg.handlerLabelMerge, user, http.MethodPost, "/", `{"into": "`+into.ID.String()+`"}`, params)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+		}
+		copies := db.called("CopyPostLabels")
+		if len(copies) != 1 || argUUID(t, copies[0].Args[0]) != into.ID || argUUID(t, copies[0].Args[1]) != from.ID {
+			t.Errorf("expected posts copied from %s into %s, got %+v", from.ID, into.ID, copies)
+		}
+		deletes := db.called("DeleteLabel")
+		if len(deletes) != 1 || argUUID(t, deletes[0].Args[0]) != from.ID {
+			t.Errorf("expected %s deleted, got %+v", from.ID, deletes)
+		}
+		if db.commits != 1 {
+			t.Errorf("expected one commit, got %d", db.commits)
+		}
+		var got Label
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != into.ID {
+			t.Errorf("expected the merged-into label back, got %+v", got)
+		}
+	})
+}
+
+func TestHandlerPostLabelRemove(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	label := database.GetLabelForUserRow{ID: uuid.New(), UserID: user.ID, Name: "Go"}
+	postID := uuid.New()
+	params := map[string]string{"postID": postID.String(), "labelID": label.ID.String()}
+
+	// The post's feed may be gone or private by now; the label still comes off.
+	cfg, db := newTestConfig(t)
+	db.returns("GetLabelForUser", label)
+	db.affects("RemovePostLabel", 1)
+	rec := serveAuthed(cfg.handlerPostLabelRemove, user, http.MethodDelete, "/", "", params)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	removes := db.called("RemovePostLabel")
+	if len(removes) != 1 || argUUID(t, removes[0].Args[0]) != label.ID || argUUID(t, removes[0].Args[1]) != postID {
+		t.Errorf("unexpected RemovePostLabel calls %+v", removes)
+	}
+	if len(db.called("GetPost")) != 0 {
+		t.Error("expected the post not to be looked up")
+	}
+
+	// Another user's label isn't found.
+	cfg, db = newTestConfig(t)
+	db.returns("GetLabelForUser")
+	rec = serveAuthed(cfg.handlerPostLabelRemove, user, http.MethodDelete, "/", "", params)
+	if rec.Code != http.StatusNotFound || len(db.called("RemovePostLabel")) != 0 {
+		t.Errorf("expected 404 without removing, got %d", rec.Code)
+	}
+}
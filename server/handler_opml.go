@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+// maxOPMLSize bounds uploaded OPML files; a few thousand subscriptions fit
+// in well under a megabyte.
+const maxOPMLSize = 5 << 20
+
+type OPMLImportItem struct {
+	URL       string     `json:"url"`
+	Title     string     `json:"title"`
+	Folder    *string    `json:"folder"`
+	Subfolder *string    `json:"subfolder"`
+	Starred   bool       `json:"starred"`
+	StarOnly  bool       `json:"star_only"`
+	Status    string     `json:"status"`
+	FeedID    *uuid.UUID `json:"feed_id"`
+	Error     *string    `json:"error"`
+}
+
+// OPMLImport reports an import's progress. Pending, Succeeded and Failed
+// count its items.
+type OPMLImport struct {
+	ID        uuid.UUID        `json:"id"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Status    string           `json:"status"`
+	Total     int              `json:"total"`
+	Pending   int              `json:"pending"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Items     []OPMLImportItem `json:"items"`
+}
+
+func databaseOPMLImportToOPMLImport(job database.OpmlImport, items []database.OpmlImportItem) OPMLImport {
+	result := OPMLImport{
+		ID:        job.ID,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+		Status:    job.Status,
+		Total:     len(items),
+		Items:     make([]OPMLImportItem, len(items)),
+	}
+	for i, item := range items {
+		result.Items[i] = OPMLImportItem{
+			URL:       item.Url,
+			Title:     item.Title,
+			Folder:    nullStringToStringPtr(item.Folder),
+			Subfolder: nullStringToStringPtr(item.Subfolder),
+			Starred:   item.Starred,
+			StarOnly:  item.StarOnly,
+			Status:    item.Status,
+			FeedID:    nullUUIDToUUIDPtr(item.FeedID),
+			Error:     nullStringToStringPtr(item.Error),
+		}
+		switch item.Status {
+		case OPMLItemPending:
+			result.Pending++
+		case OPMLItemSucceeded:
+			result.Succeeded++
+		case OPMLItemFailed:
+			result.Failed++
+		}
+	}
+	return result
+}
+
+// readOPMLUpload takes the OPML document from a multipart "file" field or, for
+// any other content type, the raw request body.
+func readOPMLUpload(r *http.Request) ([]byte, error) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return io.ReadAll(file)
+	}
+	return io.ReadAll(r.Body)
+}
+
+// handlerOPMLImport queues an OPML file's subscriptions for import and
+// answers straight away; the import's status endpoint reports progress.
+func (cfg *apiConfig) handlerOPMLImport(w http.ResponseWriter, r *http.Request, user database.User) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxOPMLSize)
+	data, err := readOPMLUpload(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "OPML file is too large")
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Couldn't read OPML file")
+		return
+	}
+	subscriptions, err := parseOPML(data)
+	if errors.Is(err, errNoSubscriptions) {
+		respondWithError(w, http.StatusBadRequest, "OPML file has no feeds")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse OPML file")
+		return
+	}
+
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start import")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
+
+	job, err := qtx.CreateOPMLImport(r.Context(), database.CreateOPMLImportParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start import")
+		return
+	}
+	for i, subscription := range subscriptions {
+		err = qtx.CreateOPMLImportItem(r.Context(), database.CreateOPMLImportItemParams{
+			ID:        uuid.New(),
+			ImportID:  job.ID,
+			Position:  int32(i),
+			Url:       subscription.URL,
+			Title:     subscription.Title,
+			Folder:    nullStringFromString(subscription.Folder),
+			Subfolder: nullStringFromString(subscription.Subfolder),
+			Starred:   subscription.Starred,
+			StarOnly:  subscription.StarOnly,
+			UpdatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't start import")
+			return
+		}
+	}
+	items, err := qtx.GetOPMLImportItems(r.Context(), job.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start import")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't start import")
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, databaseOPMLImportToOPMLImport(job, items))
+}
+
+func (cfg *apiConfig) handlerOPMLImportGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	importID, err := uuid.Parse(chi.URLParam(r, "importID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid import ID format")
+		return
+	}
+	job, err := cfg.DB.GetOPMLImportForUser(r.Context(), database.GetOPMLImportForUserParams{
+		ID:     importID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Import not found")
+		return
+	}
+	items, err := cfg.DB.GetOPMLImportItems(r.Context(), job.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get import items")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseOPMLImportToOPMLImport(job, items))
+}
+
+// handlerOPMLExport writes the user's followed feeds as an OPML 2.0 file that
+// the import reads back into the same folders and titles. starred=true marks
+// the starred feeds, so the import stars them again, and adds starred feeds
+// the user doesn't follow, which the import stars without following.
+func (cfg *apiConfig) handlerOPMLExport(w http.ResponseWriter, r *http.Request, user database.User) {
+	folders, err := cfg.DB.GetFoldersForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get folders")
+		return
+	}
+	follows, err := cfg.DB.GetFollowedFeedsForExport(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get followed feeds")
+		return
+	}
+	var starred []database.Feed
+	if r.URL.Query().Get("starred") == "true" {
+		starred, err = cfg.DB.GetStarredFeedsForUser(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't get starred feeds")
+			return
+		}
+	}
+
+	doc := buildOPMLExport(user.Name+"'s subscriptions", time.Now(), folders, follows, starred)
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't write OPML")
+		return
+	}
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="subscriptions.opml"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+}
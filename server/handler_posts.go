@@ -1,14 +1,57 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
 	"github.com/google/uuid"
 )
 
 func (apiCfg *apiConfig) handlerGetPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread_only") == "true"
+	var folderID uuid.NullUUID
+	if folderIDStr := r.URL.Query().Get("folder_id"); folderIDStr != "" {
+		id, err := uuid.Parse(folderIDStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid folder ID format")
+			return
+		}
+		folder, err := apiCfg.DB.GetFolderForUser(r.Context(), database.GetFolderForUserParams{
+			ID:     id,
+			UserID: user.ID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		folderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+	}
+	var labelID uuid.NullUUID
+	if labelName := r.URL.Query().Get("label"); labelName != "" {
+		label, err := apiCfg.DB.GetLabelByName(r.Context(), database.GetLabelByNameParams{
+			UserID: user.ID,
+			Name:   labelName,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Label not found")
+			return
+		}
+		labelID = uuid.NullUUID{UUID: label.ID, Valid: true}
+	}
+	settings, err := apiCfg.followSettingsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed follows")
+		return
+	}
 	feedIDStr := r.URL.Query().Get("feed_id")
 	
 	if feedIDStr != "" {
@@ -17,29 +60,25 @@ func (apiCfg *apiConfig) handlerGetPosts(w http.ResponseWriter, r *http.Request,
 			respondWithError(w, http.StatusBadRequest, "Invalid feed ID format")
 			return
 		}
-		
-		limitStr := r.URL.Query().Get("limit")
-		limit := 10
-		if limitStr != "" {
-			parsedLimit, err := strconv.Atoi(limitStr)
-			if err == nil && parsedLimit > 0 {
-				limit = parsedLimit
-			}
-		}
-		
-		offsetStr := r.URL.Query().Get("offset")
-		offset := 0
-		if offsetStr != "" {
-			parsedOffset, err := strconv.Atoi(offsetStr)
-			if err == nil && parsedOffset >= 0 {
-				offset = parsedOffset
-			}
+
+		_, err = apiCfg.DB.GetFeedVisibleToUser(r.Context(), database.GetFeedVisibleToUserParams{
+			ID:     feedID,
+			UserID: user.ID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Feed not found")
+			return
 		}
 		
 		posts, err := apiCfg.DB.GetPostsByFeedID(r.Context(), database.GetPostsByFeedIDParams{
-			FeedID: feedID,
-			Limit:  int32(limit),
-			Offset: int32(offset),
+			FeedID:      feedID,
+			Limit:       page.queryLimit(),
+			CursorTime:  page.CursorTime,
+			OldestFirst: settings.sortOrder(feedID) == sortOrderOldest,
+			CursorID:    page.CursorID,
+			UnreadOnly:  unreadOnly,
+			UserID:      user.ID,
+			LabelID:     labelID,
 		})
 		
 		if err != nil {
@@ -47,28 +86,216 @@ func (apiCfg *apiConfig) handlerGetPosts(w http.ResponseWriter, r *http.Request,
 			return
 		}
 		
-		respondWithJSON(w, http.StatusOK, databasePostsToPosts(posts))
+		posts, next := page.trimPage(posts)
+		respondWithJSON(w, http.StatusOK, PostPage[Post]{Posts: settings.posts(databasePostsToPosts(posts)), NextCursor: next})
 		return
 	}
 	
-	limit := 10
-	limitStr := r.URL.Query().Get("limit")
-	if limitStr != "" {
-		parsedLimit, err := strconv.Atoi(limitStr)
-		if err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-	
 	posts, err := apiCfg.DB.GetPostsForUser(r.Context(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
+		UserID:     user.ID,
+		Limit:      page.queryLimit(),
+		CursorTime: page.CursorTime,
+		CursorID:   page.CursorID,
+		UnreadOnly: unreadOnly,
+		LabelID:    labelID,
+		FolderID:   folderID,
 	})
 	
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't get posts")
 		return
 	}
+	posts, next := page.trimPage(posts)
+
+	if r.URL.Query().Get("collapse") == "true" {
+		collapsed, err := apiCfg.collapseClusters(r, user, settings, posts)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't collapse posts")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, PostPage[ClusteredPost]{Posts: collapsed, NextCursor: next})
+		return
+	}
 	
-	respondWithJSON(w, http.StatusOK, databasePostsToPosts(posts))
+	respondWithJSON(w, http.StatusOK, PostPage[Post]{Posts: settings.posts(databasePostsToPosts(posts)), NextCursor: next})
+}
+
+// visiblePost loads the postID URL parameter's post, answering 404 for posts
+// in feeds the user may not see.
+func (cfg *apiConfig) visiblePost(w http.ResponseWriter, r *http.Request, user database.User) (database.Post, bool) {
+	postID, err := uuid.Parse(chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid post ID format")
+		return database.Post{}, false
+	}
+	post, err := cfg.DB.GetPost(r.Context(), postID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return database.Post{}, false
+	}
+	_, err = cfg.DB.GetFeedVisibleToUser(r.Context(), database.GetFeedVisibleToUserParams{
+		ID:     post.FeedID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Post not found")
+		return database.Post{}, false
+	}
+	return post, true
+}
+
+// PostDetail is a post as a reader view shows it. Previous is the neighbour
+// above the post in a newest-first list and Next the one below it; the
+// timeline neighbours are null when the user doesn't follow the post's feed.
+type PostDetail struct {
+	Post
+	Feed               Feed       `json:"feed"`
+	Read               bool       `json:"read"`
+	Saved              bool       `json:"saved"`
+	Note               *string    `json:"note"`
+	Labels             []Label    `json:"labels"`
+	PreviousInFeed     *uuid.UUID `json:"previous_in_feed"`
+	NextInFeed         *uuid.UUID `json:"next_in_feed"`
+	PreviousInTimeline *uuid.UUID `json:"previous_in_timeline"`
+	NextInTimeline     *uuid.UUID `json:"next_in_timeline"`
+}
+
+func (apiCfg *apiConfig) handlerGetPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, ok := apiCfg.visiblePost(w, r, user)
+	if !ok {
+		return
+	}
+	feed, err := apiCfg.DB.GetFeed(r.Context(), post.FeedID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get post's feed")
+		return
+	}
+	settings, err := apiCfg.followSettingsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed follows")
+		return
+	}
+	detail := PostDetail{
+		Post: settings.post(databasePostToPost(post)),
+		Feed: settings.feed(databaseFeedToFeed(feed)),
+	}
+
+	detail.Read, err = apiCfg.DB.GetPostReadForUser(r.Context(), database.GetPostReadForUserParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get read state")
+		return
+	}
+	saved, err := apiCfg.DB.GetSavedPostForUser(r.Context(), database.GetSavedPostForUserParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get saved state")
+		return
+	}
+	detail.Saved = err == nil
+	detail.Note = nullStringToStringPtr(saved.Note)
+
+	labels, err := apiCfg.DB.GetLabelsForPost(r.Context(), database.GetLabelsForPostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get labels")
+		return
+	}
+	detail.Labels = make([]Label, len(labels))
+	for i, label := range labels {
+		detail.Labels[i] = labelFromRow(database.GetLabelForUserRow(label))
+	}
+
+	neighbours, err := apiCfg.DB.GetPostNeighbours(r.Context(), database.GetPostNeighboursParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get neighbouring posts")
+		return
+	}
+	detail.PreviousInFeed = nullUUIDToUUIDPtr(neighbours.PreviousInFeed)
+	detail.NextInFeed = nullUUIDToUUIDPtr(neighbours.NextInFeed)
+	detail.PreviousInTimeline = nullUUIDToUUIDPtr(neighbours.PreviousInTimeline)
+	detail.NextInTimeline = nullUUIDToUUIDPtr(neighbours.NextInTimeline)
+
+	respondWithJSON(w, http.StatusOK, detail)
+}
+
+type ClusterMember struct {
+	PostID   uuid.UUID `json:"post_id"`
+	Url      string    `json:"url"`
+	FeedID   uuid.UUID `json:"feed_id"`
+	FeedName string    `json:"feed_name"`
+}
+
+// ClusteredPost is a timeline entry standing in for every post of its story.
+type ClusteredPost struct {
+	Post
+	AlsoCoveredBy []ClusterMember `json:"also_covered_by"`
+}
+
+// collapseClusters keeps the first post of each story in posts and lists the
+// other feeds the user can see that covered it. Posts not clustered yet stand
+// alone.
+func (apiCfg *apiConfig) collapseClusters(r *http.Request, user database.User, settings followSettings, posts []database.Post) ([]ClusteredPost, error) {
+	postIDs := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	clusters, err := apiCfg.DB.GetPostClusters(r.Context(), postIDs)
+	if err != nil {
+		return nil, err
+	}
+	clusterOf := map[uuid.UUID]uuid.UUID{}
+	clusterIDs := []uuid.UUID{}
+	for _, cluster := range clusters {
+		clusterOf[cluster.PostID] = cluster.ClusterID
+		clusterIDs = append(clusterIDs, cluster.ClusterID)
+	}
+	members, err := apiCfg.DB.GetClusterMembersVisibleToUser(r.Context(), database.GetClusterMembersVisibleToUserParams{
+		ClusterIds: clusterIDs,
+		UserID:     user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	membersOf := map[uuid.UUID][]database.GetClusterMembersVisibleToUserRow{}
+	for _, member := range members {
+		membersOf[member.ClusterID] = append(membersOf[member.ClusterID], member)
+	}
+
+	result := []ClusteredPost{}
+	shown := map[uuid.UUID]bool{}
+	for _, post := range posts {
+		entry := ClusteredPost{Post: settings.post(databasePostToPost(post)), AlsoCoveredBy: []ClusterMember{}}
+		clusterID, ok := clusterOf[post.ID]
+		if ok {
+			if shown[clusterID] {
+				continue
+			}
+			shown[clusterID] = true
+			feeds := map[uuid.UUID]bool{post.FeedID: true}
+			for _, member := range membersOf[clusterID] {
+				if feeds[member.FeedID] {
+					continue
+				}
+				feeds[member.FeedID] = true
+				entry.AlsoCoveredBy = append(entry.AlsoCoveredBy, ClusterMember{
+					PostID:   member.PostID,
+					Url:      member.Url,
+					FeedID:   member.FeedID,
+					FeedName: settings.feedName(member.FeedID, member.FeedName),
+				})
+			}
+		}
+		result = append(result, entry)
+	}
+	return result, nil
 }
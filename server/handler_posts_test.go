@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestHandlerGetPostNeighbours(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	previous, next := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name       string
+		post       database.Post
+		neighbours database.GetPostNeighboursRow
+		want       [4]*uuid.UUID
+	}{
+		{
+			name:       "followed feed",
+			post:       database.Post{ID: uuid.New(), FeedID: uuid.New()},
+			neighbours: database.GetPostNeighboursRow{PreviousInFeed: uuid.NullUUID{UUID: previous, Valid: true}, NextInFeed: uuid.NullUUID{UUID: next, Valid: true}, PreviousInTimeline: uuid.NullUUID{UUID: next, Valid: true}, NextInTimeline: uuid.NullUUID{UUID: previous, Valid: true}},
+			want:       [4]*uuid.UUID{&previous, &next, &next, &previous},
+		},
+		{
+			name:       "unfollowed feed",
+			post:       database.Post{ID: uuid.New(), FeedID: uuid.New()},
+			neighbours: database.GetPostNeighboursRow{NextInFeed: uuid.NullUUID{UUID: next, Valid: true}},
+			want:       [4]*uuid.UUID{nil, &next, nil, nil},
+		},
+		{
+			name: "no neighbours",
+			post: database.Post{ID: uuid.New(), FeedID: uuid.New()},
+		},
+	}
+	for _, tt := range tests {
+		cfg, db := newTestConfig(t)
+		db.returns("GetPost", tt.post)
+		db.returns("GetFeedVisibleToUser", database.Feed{ID: tt.post.FeedID})
+		db.returns("GetFeed", database.Feed{ID: tt.post.FeedID, Name: "Feed", Kind: "rss"})
+		db.returns("GetFeedFollowsForUser")
+		db.returns("GetPostReadForUser", false)
+		db.returns("GetSavedPostForUser")
+		db.returns("GetLabelsForPost")
+		db.returns("GetPostNeighbours", tt.neighbours)
+
+		rec := serveAuthed(cfg.handlerGetPost, user, http.MethodGet, "/", "", map[string]string{"postID": tt.post.ID.String()})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tt.name, rec.Code, rec.Body)
+		}
+
+		// The query places the post from its own row; only who is asking and
+		// which post are passed in.
+		calls := db.called("GetPostNeighbours")
+		if len(calls) != 1 || argUUID(t, calls[0].Args[0]) != user.ID || argUUID(t, calls[0].Args[1]) != tt.post.ID {
+			t.Fatalf("%s: unexpected neighbour lookups %+v", tt.name, calls)
+		}
+
+		var detail PostDetail
+		if err := json.NewDecoder(rec.Body).Decode(&detail); err != nil {
+			t.Fatal(err)
+		}
+		got := [4]*uuid.UUID{detail.PreviousInFeed, detail.NextInFeed, detail.PreviousInTimeline, detail.NextInTimeline}
+		for i := range got {
+			if (got[i] == nil) != (tt.want[i] == nil) || got[i] != nil && *got[i] != *tt.want[i] {
+				t.Errorf("%s: neighbour %d is %v, want %v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+const (
+	PublishedSourceTimeline = "timeline"
+	PublishedSourceFolder   = "folder"
+	PublishedSourceLabel    = "label"
+	PublishedSourceSaved    = "saved"
+)
+
+// PublishedFeed is a private feed URL. URLs maps each format to the feed's
+// address; anyone with one can read the feed until it is deleted.
+type PublishedFeed struct {
+	ID        uuid.UUID         `json:"id"`
+	CreatedAt time.Time         `json:"created_at"`
+	Title     string            `json:"title"`
+	Source    string            `json:"source"`
+	FolderID  *uuid.UUID        `json:"folder_id"`
+	LabelID   *uuid.UUID        `json:"label_id"`
+	URLs      map[string]string `json:"urls"`
+}
+
+func publishedFeedURL(baseURL, token, format string) string {
+	return baseURL + "/v1/published/" + token + "." + format
+}
+
+func databasePublishedFeedToPublishedFeed(feed database.PublishedFeed, baseURL string) PublishedFeed {
+	urls := make(map[string]string, len(outputFormats))
+	for _, format := range outputFormats {
+		urls[format] = publishedFeedURL(baseURL, feed.Token, format)
+	}
+	return PublishedFeed{
+		ID:        feed.ID,
+		CreatedAt: feed.CreatedAt,
+		Title:     feed.Title,
+		Source:    feed.Source,
+		FolderID:  nullUUIDToUUIDPtr(feed.FolderID),
+		LabelID:   nullUUIDToUUIDPtr(feed.LabelID),
+		URLs:      urls,
+	}
+}
+
+// handlerPublishedFeedCreate hands out a private feed URL for the user's
+// timeline, a folder, a label or their saved posts.
+func (cfg *apiConfig) handlerPublishedFeedCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		Title    string     `json:"title"`
+		Source   string     `json:"source"`
+		FolderID *uuid.UUID `json:"folder_id"`
+		LabelID  *uuid.UUID `json:"label_id"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var folderID, labelID uuid.NullUUID
+	var title string
+	switch params.Source {
+	case PublishedSourceTimeline:
+		title = user.Name + "'s timeline"
+	case PublishedSourceSaved:
+		title = user.Name + "'s saved posts"
+	case PublishedSourceFolder:
+		if params.FolderID == nil {
+			respondWithError(w, http.StatusBadRequest, "folder_id is required for a folder feed")
+			return
+		}
+		folder, err := cfg.DB.GetFolderForUser(r.Context(), database.GetFolderForUserParams{
+			ID:     *params.FolderID,
+			UserID: user.ID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		folderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+		title = folder.Name
+	case PublishedSourceLabel:
+		if params.LabelID == nil {
+			respondWithError(w, http.StatusBadRequest, "label_id is required for a label feed")
+			return
+		}
+		label, err := cfg.DB.GetLabelForUser(r.Context(), database.GetLabelForUserParams{
+			ID:     *params.LabelID,
+			UserID: user.ID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Label not found")
+			return
+		}
+		labelID = uuid.NullUUID{UUID: label.ID, Valid: true}
+		title = label.Name
+	default:
+		respondWithError(w, http.StatusBadRequest, "source must be timeline, folder, label or saved")
+		return
+	}
+	if t := strings.TrimSpace(params.Title); t != "" {
+		title = t
+	}
+
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't generate feed token")
+		return
+	}
+
+	feed, err := cfg.DB.CreatePublishedFeed(r.Context(), database.CreatePublishedFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		Token:     hex.EncodeToString(tokenBytes),
+		Title:     title,
+		Source:    params.Source,
+		FolderID:  folderID,
+		LabelID:   labelID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create published feed")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, databasePublishedFeedToPublishedFeed(feed, requestBaseURL(r)))
+}
+
+func (cfg *apiConfig) handlerPublishedFeedsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := cfg.DB.GetPublishedFeedsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get published feeds")
+		return
+	}
+
+	result := make([]PublishedFeed, len(feeds))
+	for i, feed := range feeds {
+		result[i] = databasePublishedFeedToPublishedFeed(feed, requestBaseURL(r))
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// handlerPublishedFeedDelete revokes a published feed's token; its URLs stop
+// working straight away.
+func (cfg *apiConfig) handlerPublishedFeedDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "publishedFeedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid published feed ID format")
+		return
+	}
+	deleted, err := cfg.DB.DeletePublishedFeed(r.Context(), database.DeletePublishedFeedParams{
+		ID:     feedID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete published feed")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Published feed not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
+
+// publishedFeedItems loads the newest posts of a published feed's source as
+// its owner would see them.
+func (cfg *apiConfig) publishedFeedItems(r *http.Request, published database.PublishedFeed) ([]outputItem, error) {
+	settings, err := cfg.followSettingsForUser(r.Context(), published.UserID)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := cfg.DB.GetFeedsVisibleToUser(r.Context(), published.UserID)
+	if err != nil {
+		return nil, err
+	}
+	feeds := make(map[uuid.UUID]database.Feed, len(visible))
+	for _, feed := range visible {
+		feeds[feed.ID] = feed
+	}
+
+	if published.Source == PublishedSourceSaved {
+		saved, err := cfg.DB.GetSavedPostsForUser(r.Context(), database.GetSavedPostsForUserParams{
+			UserID: published.UserID,
+			Limit:  outputFeedSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]outputItem, len(saved))
+		for i, s := range saved {
+			post := Post{
+				ID:          s.ID,
+				CreatedAt:   s.CreatedAt,
+				UpdatedAt:   s.UpdatedAt,
+				Title:       s.Title,
+				Url:         s.Url,
+				Description: nullStringToStringPtr(s.Description),
+				PublishedAt: nullTimeToTimePtr(s.PublishedAt),
+				FeedID:      s.FeedID.UUID,
+			}
+			feed := feeds[s.FeedID.UUID]
+			items[i] = outputItemFromPost(settings.post(post), feed, settings.feedName(s.FeedID.UUID, s.FeedName))
+		}
+		return items, nil
+	}
+
+	posts, err := cfg.DB.GetPostsForUser(r.Context(), database.GetPostsForUserParams{
+		UserID:   published.UserID,
+		Limit:    outputFeedSize,
+		FolderID: published.FolderID,
+		LabelID:  published.LabelID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]outputItem, len(posts))
+	for i, post := range databasePostsToPosts(posts) {
+		feed := feeds[post.FeedID]
+		items[i] = outputItemFromPost(settings.post(post), feed, settings.feedName(post.FeedID, feed.Name))
+	}
+	return items, nil
+}
+
+// handlerPublishedFeedServe serves a published feed to whoever holds its
+// token, in the format named by the URL's extension.
+func (cfg *apiConfig) handlerPublishedFeedServe(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	format := chi.URLParam(r, "format")
+	published, err := cfg.DB.GetPublishedFeedByToken(r.Context(), token)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed")
+		return
+	}
+	owner, err := cfg.DB.GetUserByID(r.Context(), published.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed")
+		return
+	}
+	items, err := cfg.publishedFeedItems(r, published)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get posts")
+		return
+	}
+
+	baseURL := requestBaseURL(r)
+	serveOutputFeed(w, r, outputFeed{
+		ID:          published.ID,
+		Title:       published.Title,
+		Description: "Posts collected by " + owner.Name,
+		HomeURL:     baseURL,
+		FeedURL:     publishedFeedURL(baseURL, published.Token, format),
+		Author:      owner.Name,
+		Updated:     published.UpdatedAt,
+		Items:       items,
+	}, format)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+type PostReadState struct {
+	PostID uuid.UUID `json:"post_id"`
+	Read   bool      `json:"read"`
+}
+
+type FeedUnreadCount struct {
+	FeedID uuid.UUID `json:"feed_id"`
+	Unread int64     `json:"unread"`
+}
+
+type FolderUnreadCount struct {
+	FolderID uuid.UUID `json:"folder_id"`
+	Unread   int64     `json:"unread"`
+}
+
+type UnreadCounts struct {
+	Total   int64               `json:"total"`
+	Feeds   []FeedUnreadCount   `json:"feeds"`
+	Folders []FolderUnreadCount `json:"folders"`
+}
+
+func (cfg *apiConfig) handlerPostMarkRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.setPostRead(w, r, user, true)
+}
+
+func (cfg *apiConfig) handlerPostMarkUnread(w http.ResponseWriter, r *http.Request, user database.User) {
+	cfg.setPostRead(w, r, user, false)
+}
+
+// setPostRead sets one post's read state. Marking a post read also records
+// the read for trending, which only counts reads users asked for.
+func (cfg *apiConfig) setPostRead(w http.ResponseWriter, r *http.Request, user database.User, read bool) {
+	post, ok := cfg.visiblePost(w, r, user)
+	if !ok {
+		return
+	}
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update read state")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
+
+	err = qtx.SetPostReadState(r.Context(), database.SetPostReadStateParams{
+		UserID:    user.ID,
+		PostID:    post.ID,
+		Read:      read,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update read state")
+		return
+	}
+	if read {
+		err = qtx.RecordPostRead(r.Context(), database.RecordPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			ReadAt: time.Now().UTC(),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't update read state")
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update read state")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, PostReadState{PostID: post.ID, Read: read})
+}
+
+// readUntil reads the optional {"until": ...} body of the mark-all-read
+// endpoints. Without one, everything up to now is marked read.
+func readUntil(r *http.Request) (time.Time, error) {
+	type parameters struct {
+		Until *time.Time `json:"until"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+		return time.Time{}, err
+	}
+	if params.Until == nil {
+		return time.Now().UTC(), nil
+	}
+	return params.Until.UTC(), nil
+}
+
+// handlerPostsMarkAllRead marks every post in the user's followed feeds up to
+// a time as read.
+func (cfg *apiConfig) handlerPostsMarkAllRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	until, err := readUntil(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	cfg.markReadUntil(w, r, user, uuid.NullUUID{}, until)
+}
+
+func (cfg *apiConfig) handlerFeedMarkAllRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	feed, ok := cfg.visibleFeed(w, r, user)
+	if !ok {
+		return
+	}
+	until, err := readUntil(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	cfg.markReadUntil(w, r, user, uuid.NullUUID{UUID: feed.ID, Valid: true}, until)
+}
+
+// markReadUntil moves the read mark of one feed, or of every followed feed
+// when feedID is null, and drops the per-post states the mark now covers.
+func (cfg *apiConfig) markReadUntil(w http.ResponseWriter, r *http.Request, user database.User, feedID uuid.NullUUID, until time.Time) {
+	tx, err := cfg.DBConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark posts read")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.DB.WithTx(tx)
+
+	if feedID.Valid {
+		err = qtx.MarkFeedReadUntil(r.Context(), database.MarkFeedReadUntilParams{
+			UserID:    user.ID,
+			FeedID:    feedID.UUID,
+			ReadUntil: until,
+			UpdatedAt: time.Now().UTC(),
+		})
+	} else {
+		err = qtx.MarkFollowedFeedsReadUntil(r.Context(), database.MarkFollowedFeedsReadUntilParams{
+			ReadUntil: until,
+			UpdatedAt: time.Now().UTC(),
+			UserID:    user.ID,
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark posts read")
+		return
+	}
+	err = qtx.ClearPostReadStatesUntil(r.Context(), database.ClearPostReadStatesUntilParams{
+		UserID:    user.ID,
+		ReadUntil: until,
+		FeedID:    feedID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark posts read")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mark posts read")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]time.Time{"read_until": until})
+}
+
+func (cfg *apiConfig) handlerUnreadCountsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	counts, err := cfg.DB.GetUnreadCountsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get unread counts")
+		return
+	}
+
+	folders, err := cfg.DB.GetFoldersForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get folders")
+		return
+	}
+
+	// Total counts what the main timeline shows, so it leaves out feeds hidden
+	// from it. A folder's count includes the feeds filed in its subfolders.
+	folderUnread := make(map[uuid.UUID]int64, len(folders))
+	parents := make(map[uuid.UUID]uuid.NullUUID, len(folders))
+	for _, folder := range folders {
+		folderUnread[folder.ID] = 0
+		parents[folder.ID] = folder.ParentID
+	}
+
+	result := UnreadCounts{
+		Feeds:   make([]FeedUnreadCount, len(counts)),
+		Folders: make([]FolderUnreadCount, 0, len(folders)),
+	}
+	for i, count := range counts {
+		result.Feeds[i] = FeedUnreadCount{FeedID: count.FeedID, Unread: count.Unread}
+		if !count.HideFromTimeline {
+			result.Total += count.Unread
+		}
+		if !count.FolderID.Valid {
+			continue
+		}
+		folderUnread[count.FolderID.UUID] += count.Unread
+		if parent := parents[count.FolderID.UUID]; parent.Valid {
+			folderUnread[parent.UUID] += count.Unread
+		}
+	}
+	for _, folder := range folders {
+		result.Folders = append(result.Folders, FolderUnreadCount{FolderID: folder.ID, Unread: folderUnread[folder.ID]})
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestReadUntil(t *testing.T) {
+	until := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		body    string
+		want    time.Time
+		wantErr bool
+	}{
+		{"no body", "", time.Time{}, false},
+		{"no until", `{}`, time.Time{}, false},
+		{"until", `{"until": "2024-03-01T12:00:00Z"}`, until, false},
+		{"until with offset", `{"until": "2024-03-01T13:00:00+01:00"}`, until, false},
+		{"bad json", `{"until": 5}`, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		before := time.Now().UTC()
+		got, err := readUntil(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if tt.want.IsZero() {
+			if got.Before(before) || got.After(time.Now().UTC()) {
+				t.Errorf("%s: expected now, got %v", tt.name, got)
+			}
+		} else if !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestHandlerPostsMarkAllRead(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	until := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cfg, db := newTestConfig(t)
+	db.affects("MarkFollowedFeedsReadUntil", 3)
+	db.affects("ClearPostReadStatesUntil", 2)
+	rec := serveAuthed(cfg.handlerPostsMarkAllRead, user, http.MethodPost, "/", `{"until": "2024-03-01T12:00:00Z"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	// The mark is sent as requested; the query keeps a later existing mark.
+	marks := db.called("MarkFollowedFeedsReadUntil")
+	if len(marks) != 1 || marks[0].Args[0] != until || argUUID(t, marks[0].Args[2]) != user.ID {
+		t.Errorf("unexpected MarkFollowedFeedsReadUntil calls %+v", marks)
+	}
+	// Per-post states the mark covers are dropped, in every followed feed, so
+	// a post marked unread before the mark counts as read again.
+	clears := db.called("ClearPostReadStatesUntil")
+	if len(clears) != 1 || argUUID(t, clears[0].Args[0]) != user.ID || clears[0].Args[1] != until || clears[0].Args[2] != nil {
+		t.Errorf("unexpected ClearPostReadStatesUntil calls %+v", clears)
+	}
+	if db.commits != 1 {
+		t.Errorf("expected one commit, got %d", db.commits)
+	}
+}
+
+func TestHandlerFeedMarkAllRead(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	feed := database.Feed{ID: uuid.New(), UserID: uuid.New(), Kind: "rss"}
+	params := map[string]string{"feedID": feed.ID.String()}
+
+	cfg, db := newTestConfig(t)
+	db.returns("GetFeedVisibleToUser", feed)
+	db.affects("MarkFeedReadUntil", 1)
+	db.affects("ClearPostReadStatesUntil", 0)
+	rec := serveAuthed(cfg.handlerFeedMarkAllRead, user, http.MethodPost, "/", "", params)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	marks := db.called("MarkFeedReadUntil")
+	if len(marks) != 1 || argUUID(t, marks[0].Args[0]) != user.ID || argUUID(t, marks[0].Args[1]) != feed.ID {
+		t.Fatalf("unexpected MarkFeedReadUntil calls %+v", marks)
+	}
+	clears := db.called("ClearPostReadStatesUntil")
+	if len(clears) != 1 || clears[0].Args[1] != marks[0].Args[2] || argUUID(t, clears[0].Args[2]) != feed.ID {
+		t.Errorf("expected the states cleared up to the mark in %s only, got %+v", feed.ID, clears)
+	}
+
+	// A failed clear leaves the mark unmoved too.
+	cfg, db = newTestConfig(t)
+	db.returns("GetFeedVisibleToUser", feed)
+	db.affects("MarkFeedReadUntil", 1)
+	db.on("ClearPostReadStatesUntil", func([]driver.Value) fakeResult { return fakeResult{Err: errors.New("boom")} })
+	rec = serveAuthed(cfg.handlerFeedMarkAllRead, user, http.MethodPost, "/", "", params)
+	if rec.Code != http.StatusInternalServerError || db.commits != 0 || db.rollbacks != 1 {
+		t.Errorf("expected a rolled back 500, got %d with %d commits", rec.Code, db.commits)
+	}
+}
+
+func TestSetPostReadOverridesMark(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	post := database.Post{ID: uuid.New(), FeedID: uuid.New(), Url: "https://example.com/a"}
+	params := map[string]string{"postID": post.ID.String()}
+
+	for _, read := range []bool{true, false} {
+		cfg, db := newTestConfig(t)
+		db.returns("GetPost", post)
+		db.returns("GetFeedVisibleToUser", database.Feed{ID: post.FeedID})
+		db.affects("SetPostReadState", 1)
+		db.affects("RecordPostRead", 1)
+		handler := cfg.handlerPostMarkUnread
+		if read {
+			handler = cfg.handlerPostMarkRead
+		}
+		rec := serveAuthed(handler, user, http.MethodPost, "/", "", params)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+		}
+		// Marking unread stores an explicit state rather than deleting one,
+		// so it wins over a read mark that covers the post.
+		states := db.called("SetPostReadState")
+		if len(states) != 1 || argUUID(t, states[0].Args[1]) != post.ID || states[0].Args[2] != read {
+			t.Errorf("read=%v: unexpected SetPostReadState calls %+v", read, states)
+		}
+		// Only marking read counts towards trending.
+		reads := db.called("RecordPostRead")
+		if read && (len(reads) != 1 || argUUID(t, reads[0].Args[0]) != user.ID || argUUID(t, reads[0].Args[1]) != post.ID) || !read && len(reads) != 0 {
+			t.Errorf("read=%v: unexpected RecordPostRead calls %+v", read, reads)
+		}
+		if db.commits != 1 {
+			t.Errorf("read=%v: expected one commit, got %d", read, db.commits)
+		}
+	}
+}
+
+func TestHandlerUnreadCountsGet(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	top := database.Folder{ID: uuid.New(), Name: "Top"}
+	nested := database.Folder{ID: uuid.New(), Name: "Nested", ParentID: uuid.NullUUID{UUID: top.ID, Valid: true}}
+	empty := database.Folder{ID: uuid.New(), Name: "Empty"}
+	in := func(folder database.Folder) uuid.NullUUID { return uuid.NullUUID{UUID: folder.ID, Valid: true} }
+	counts := []database.GetUnreadCountsForUserRow{
+		{FeedID: uuid.New(), FolderID: in(top), Unread: 3},
+		{FeedID: uuid.New(), FolderID: in(nested), Unread: 4},
+		{FeedID: uuid.New(), Unread: 5},
+		{FeedID: uuid.New(), FolderID: in(nested), HideFromTimeline: true, Unread: 2},
+	}
+
+	cfg, db := newTestConfig(t)
+	rows := make([]any, len(counts))
+	for i, count := range counts {
+		rows[i] = count
+	}
+	db.returns("GetUnreadCountsForUser", rows...)
+	db.returns("GetFoldersForUser", top, nested, empty)
+	rec := serveAuthed(cfg.handlerUnreadCountsGet, user, http.MethodGet, "/", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var got UnreadCounts
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hidden feeds stay out of the total but count towards their folders,
+	// and a folder includes its subfolders' feeds.
+	if got.Total != 12 {
+		t.Errorf("expected a total of 12, got %d", got.Total)
+	}
+	if len(got.Feeds) != len(counts) || got.Feeds[3].FeedID != counts[3].FeedID || got.Feeds[3].Unread != 2 {
+		t.Errorf("unexpected feed counts %+v", got.Feeds)
+	}
+	want := []FolderUnreadCount{{top.ID, 9}, {nested.ID, 6}, {empty.ID, 0}}
+	if len(got.Folders) != len(want) {
+		t.Fatalf("expected %d folders, got %+v", len(want), got.Folders)
+	}
+	for i := range want {
+		if got.Folders[i] != want[i] {
+			t.Errorf("folder %d: expected %+v, got %+v", i, want[i], got.Folders[i])
+		}
+	}
+}
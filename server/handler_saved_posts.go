@@ -0,0 +1,181 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+)
+
+// SavedPost is a copy of a post taken when it was saved. PostID and FeedID
+// become null once the post or its feed is deleted.
+type SavedPost struct {
+	ID          uuid.UUID  `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	PostID      *uuid.UUID `json:"post_id"`
+	FeedID      *uuid.UUID `json:"feed_id"`
+	FeedName    string     `json:"feed_name"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	Description *string    `json:"description"`
+	PublishedAt *time.Time `json:"published_at"`
+	Note        *string    `json:"note"`
+}
+
+func databaseSavedPostToSavedPost(saved database.SavedPost) SavedPost {
+	return SavedPost{
+		ID:          saved.ID,
+		CreatedAt:   saved.CreatedAt,
+		UpdatedAt:   saved.UpdatedAt,
+		PostID:      nullUUIDToUUIDPtr(saved.PostID),
+		FeedID:      nullUUIDToUUIDPtr(saved.FeedID),
+		FeedName:    saved.FeedName,
+		Title:       saved.Title,
+		Url:         saved.Url,
+		Description: nullStringToStringPtr(saved.Description),
+		PublishedAt: nullTimeToTimePtr(saved.PublishedAt),
+		Note:        nullStringToStringPtr(saved.Note),
+	}
+}
+
+// handlerPostSave saves a post, or replaces the note of one already saved.
+// Leaving note out keeps the saved note; an empty note clears it.
+func (cfg *apiConfig) handlerPostSave(w http.ResponseWriter, r *http.Request, user database.User) {
+	post, ok := cfg.visiblePost(w, r, user)
+	if !ok {
+		return
+	}
+	type parameters struct {
+		Note *string `json:"note"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	var note sql.NullString
+	if params.Note != nil {
+		note = nullStringFromString(strings.TrimSpace(*params.Note))
+	}
+	saved, err := cfg.DB.SavePost(r.Context(), database.SavePostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		Note:      note,
+		PostID:    post.ID,
+		KeepNote:  params.Note == nil,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save post")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, databaseSavedPostToSavedPost(saved))
+}
+
+// handlerPostUnsave removes the user's save of a post. It doesn't check that
+// the post is still visible, so a save outlives its feed going private or
+// being deleted only as long as the user wants it to.
+func (cfg *apiConfig) handlerPostUnsave(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := uuid.Parse(chi.URLParam(r, "postID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid post ID format")
+		return
+	}
+	removed, err := cfg.DB.DeleteSavedPost(r.Context(), database.DeleteSavedPostParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unsave post")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, http.StatusNotFound, "Post isn't saved")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
+
+// handlerSavedPostDelete removes a saved post by its own ID, which also
+// works once the post itself is gone.
+func (cfg *apiConfig) handlerSavedPostDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	savedID, err := uuid.Parse(chi.URLParam(r, "savedID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid saved post ID format")
+		return
+	}
+	removed, err := cfg.DB.DeleteSavedPostByID(r.Context(), database.DeleteSavedPostByIDParams{
+		ID:     savedID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete saved post")
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, http.StatusNotFound, "Saved post not found")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct{}{})
+}
+
+// handlerSavedPostsGet lists saved posts, most recently saved first. q
+// searches their titles, descriptions and notes.
+func (cfg *apiConfig) handlerSavedPostsGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	var query sql.NullString
+	if q := r.URL.Query().Get("q"); q != "" {
+		tsQuery, err := buildTSQuery(q)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Search query needs at least one word to look for")
+			return
+		}
+		query = sql.NullString{String: tsQuery, Valid: true}
+	}
+
+	saved, err := cfg.DB.GetSavedPostsForUser(r.Context(), database.GetSavedPostsForUserParams{
+		UserID:     user.ID,
+		Limit:      page.queryLimit(),
+		Query:      query,
+		CursorTime: page.CursorTime,
+		CursorID:   page.CursorID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get saved posts")
+		return
+	}
+
+	settings, err := cfg.followSettingsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get saved posts")
+		return
+	}
+
+	result := PostPage[SavedPost]{Posts: make([]SavedPost, 0, len(saved))}
+	saved, result.NextCursor = trimPage(saved, page.Limit, func(s database.SavedPost) string {
+		return encodeTimeCursor(s.CreatedAt, s.ID)
+	})
+	for _, s := range saved {
+		if s.FeedID.Valid {
+			s.FeedName = settings.feedName(s.FeedID.UUID, s.FeedName)
+		}
+		result.Posts = append(result.Posts, databaseSavedPostToSavedPost(s))
+	}
+	respondWithJSON(w, http.StatusOK, result)
+}
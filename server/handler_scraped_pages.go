@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+)
+
+type PagePreviewItem struct {
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	Description *string    `json:"description"`
+	PublishedAt *time.Time `json:"published_at"`
+	RawDate     string     `json:"raw_date,omitempty"`
+}
+
+// handlerSelectorsTest shows what a set of selectors would extract from a
+// page without creating a feed.
+func (cfg *apiConfig) handlerSelectorsTest(w http.ResponseWriter, r *http.Request, user database.User) {
+	type parameters struct {
+		URL         string           `json:"url"`
+		Selectors   PageSelectors    `json:"selectors"`
+		Credentials *FeedCredentials `json:"credentials"`
+	}
+	var params parameters
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if params.URL == "" {
+		respondWithError(w, http.StatusBadRequest, "URL is required")
+		return
+	}
+	if err := params.Selectors.validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid selectors: "+err.Error())
+		return
+	}
+	if params.Credentials != nil {
+		if err := params.Credentials.validate(); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid credentials: "+err.Error())
+			return
+		}
+	}
+
+	items, err := scrapePage(params.URL, params.Selectors, params.Credentials)
+	if err != nil {
+		log.Printf("Couldn't scrape %s for selector test: %v", params.URL, err)
+		respondWithError(w, http.StatusBadGateway, "Couldn't scrape page")
+		return
+	}
+
+	preview := make([]PagePreviewItem, len(items))
+	for i, item := range items {
+		preview[i] = PagePreviewItem{
+			Title:       item.Title,
+			Url:         item.Link,
+			Description: nullStringToStringPtr(nullStringFromString(item.Description)),
+			PublishedAt: nullTimeToTimePtr(parsePublishedAt(item.PubDate)),
+			RawDate:     item.PubDate,
+		}
+	}
+	respondWithJSON(w, http.StatusOK, preview)
+}
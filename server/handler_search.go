@@ -0,0 +1,94 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+// handlerSearchPosts searches the posts the user can see, best matches first.
+// feed_id, from, to and followed=true narrow the search.
+func (cfg *apiConfig) handlerSearchPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	tsQuery, err := buildTSQuery(query.Get("q"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Search query needs at least one word to look for")
+		return
+	}
+
+	params := database.SearchPostsParams{
+		Limit:        int32(parsePageSize(r) + 1),
+		Query:        tsQuery,
+		UserID:       user.ID,
+		FollowedOnly: query.Get("followed") == "true",
+	}
+	if feedIDStr := query.Get("feed_id"); feedIDStr != "" {
+		feedID, err := uuid.Parse(feedIDStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid feed ID format")
+			return
+		}
+		params.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	}
+	for name, target := range map[string]*sql.NullTime{"from": &params.Since, "to": &params.Until} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondWithError(w, http.StatusBadRequest, "Invalid '"+name+"' parameter. Use ISO-8601 format (YYYY-MM-DDTHH:MM:SSZ).")
+				return
+			}
+			*target = sql.NullTime{Time: t.UTC(), Valid: true}
+		}
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		rank, id, err := decodeSearchCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		params.CursorRank = sql.NullFloat64{Float64: float64(rank), Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	rows, err := cfg.DB.SearchPosts(r.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't search posts")
+		return
+	}
+
+	settings, err := cfg.followSettingsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't search posts")
+		return
+	}
+
+	page := PostPage[SearchResult]{Posts: []SearchResult{}}
+	rows, page.NextCursor = trimPage(rows, int(params.Limit)-1, func(row database.SearchPostsRow) string {
+		return encodeSearchCursor(row.Rank, row.ID)
+	})
+	for _, row := range rows {
+		page.Posts = append(page.Posts, SearchResult{
+			Post: settings.post(databasePostToPost(database.Post{
+				ID:          row.ID,
+				CreatedAt:   row.CreatedAt,
+				UpdatedAt:   row.UpdatedAt,
+				Title:       row.Title,
+				Url:         row.Url,
+				Description: row.Description,
+				PublishedAt: row.PublishedAt,
+				FeedID:      row.FeedID,
+				OriginalUrl: row.OriginalUrl,
+				Author:      row.Author,
+				Categories:  row.Categories,
+			})),
+			FeedName:       settings.feedName(row.FeedID, row.FeedName),
+			Rank:           row.Rank,
+			TitleHighlight: highlightPolicy.Sanitize(row.TitleHighlight),
+			Snippet:        highlightPolicy.Sanitize(row.Snippet),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, page)
+}
@@ -50,6 +50,14 @@ func (apiCfg *apiConfig) CreateStarredFeedHandler(w http.ResponseWriter, r *http
 		respondWithError(w, http.StatusBadRequest, "Invalid feed ID format")
 		return
 	}
+	_, err = apiCfg.DB.GetFeedVisibleToUser(r.Context(), database.GetFeedVisibleToUserParams{
+		ID:     feedID,
+		UserID: user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Feed not found")
+		return
+	}
 	isStarred, err := apiCfg.DB.CheckFeedIsStarred(r.Context(), database.CheckFeedIsStarredParams{
 		UserID: user.ID,
 		FeedID: feedID,
@@ -94,8 +102,13 @@ func (apiCfg *apiConfig) GetStarredFeedsHandler(w http.ResponseWriter, r *http.R
 		respondWithError(w, http.StatusInternalServerError, "Database error")
 		return
 	}
+	settings, err := apiCfg.followSettingsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
 
-	respondWithJSON(w, http.StatusOK, databaseFeedsToFeeds(feeds))
+	respondWithJSON(w, http.StatusOK, settings.feeds(databaseFeedsToFeeds(feeds)))
 }
 
 func (apiCfg *apiConfig) handleDeleteStarredFeed(w http.ResponseWriter, r *http.Request, user database.User) {
@@ -139,39 +152,28 @@ func (apiCfg *apiConfig) GetRecentPostsFromStarredFeedsHandler(w http.ResponseWr
 		since = time.Now().Add(-24 * time.Hour)
 	}
 	
-	starredFeeds, err := apiCfg.DB.GetStarredFeedsForUser(r.Context(), user.ID)
+	page, err := parsePageParams(r)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch starred feeds")
-		return
-	}
-	
-	if len(starredFeeds) == 0 {
-		respondWithJSON(w, http.StatusOK, []Post{})
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor")
 		return
 	}
 
-	
-	dbPosts, err := apiCfg.DB.GetPostsForUser(r.Context(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  100, 
+	dbPosts, err := apiCfg.DB.GetPostsInStarredFeedsForUser(r.Context(), database.GetPostsInStarredFeedsForUserParams{
+		UserID:     user.ID,
+		Limit:      page.queryLimit(),
+		Since:      since,
+		CursorTime: page.CursorTime,
+		CursorID:   page.CursorID,
 	})
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to fetch posts")
 		return
 	}
-	var allPosts []Post
-	for _, dbPost := range dbPosts {
-		post := databasePostToPost(dbPost)
-		var postTime time.Time
-		if post.PublishedAt != nil {
-			postTime = *post.PublishedAt
-		} else {
-			postTime = post.CreatedAt
-		}
-		
-		if postTime.After(since) {
-			allPosts = append(allPosts, post)
-		}
+	settings, err := apiCfg.followSettingsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch posts")
+		return
 	}
-	respondWithJSON(w, http.StatusOK, allPosts)
+	dbPosts, next := page.trimPage(dbPosts)
+	respondWithJSON(w, http.StatusOK, PostPage[Post]{Posts: settings.posts(databasePostsToPosts(dbPosts)), NextCursor: next})
 }
\ No newline at end of file
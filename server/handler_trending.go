@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+)
+
+// TrendingPost is a post from the trending list with the signals that put it
+// there. Coverage is how many feeds carried the story.
+type TrendingPost struct {
+	Post
+	FeedName string  `json:"feed_name"`
+	Score    float64 `json:"score"`
+	Reads    int32   `json:"reads"`
+	Saves    int32   `json:"saves"`
+	Stars    int32   `json:"stars"`
+	Coverage int32   `json:"coverage"`
+}
+
+// handlerTrendingGet lists the stories trending across the instance, as of
+// the trending job's last run. Only posts in public feeds appear, less those
+// the user's filter rules hid; category narrows the list to posts tagged
+// with it.
+func (cfg *apiConfig) handlerTrendingGet(w http.ResponseWriter, r *http.Request, user database.User) {
+	rows, err := cfg.DB.GetTrendingPosts(r.Context(), database.GetTrendingPostsParams{
+		Limit:    int32(parsePageSize(r)),
+		Category: nullStringFromString(strings.TrimSpace(r.URL.Query().Get("category"))),
+		UserID:   user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get trending posts")
+		return
+	}
+	settings, err := cfg.followSettingsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get feed follows")
+		return
+	}
+
+	posts := make([]TrendingPost, len(rows))
+	for i, row := range rows {
+		post := databasePostToPost(database.Post{
+			ID:          row.ID,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+			Title:       row.Title,
+			Url:         row.Url,
+			Description: row.Description,
+			PublishedAt: row.PublishedAt,
+			FeedID:      row.FeedID,
+			OriginalUrl: row.OriginalUrl,
+			Author:      row.Author,
+			Categories:  row.Categories,
+		})
+		posts[i] = TrendingPost{
+			Post:     settings.post(post),
+			FeedName: settings.feedName(row.FeedID, row.FeedName),
+			Score:    row.Score,
+			Reads:    row.Reads,
+			Saves:    row.Saves,
+			Stars:    row.Stars,
+			Coverage: row.Coverage,
+		}
+	}
+	respondWithJSON(w, http.StatusOK, posts)
+}
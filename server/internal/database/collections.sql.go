@@ -0,0 +1,607 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const addCollectionFeed = `-- name: AddCollectionFeed :exec
+INSERT INTO collection_feeds (collection_id, feed_id, note, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (collection_id, feed_id) DO UPDATE
+SET note = EXCLUDED.note
+`
+
+type AddCollectionFeedParams struct {
+	CollectionID uuid.UUID
+	FeedID       uuid.UUID
+	Note         sql.NullString
+	CreatedAt    time.Time
+}
+
+func (q *Queries) AddCollectionFeed(ctx context.Context, arg AddCollectionFeedParams) error {
+	_, err := q.db.ExecContext(ctx, addCollectionFeed,
+		arg.CollectionID,
+		arg.FeedID,
+		arg.Note,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const addCollectionPost = `-- name: AddCollectionPost :exec
+INSERT INTO collection_posts (collection_id, post_id, note, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (collection_id, post_id) DO UPDATE
+SET note = EXCLUDED.note
+`
+
+type AddCollectionPostParams struct {
+	CollectionID uuid.UUID
+	PostID       uuid.UUID
+	Note         sql.NullString
+	CreatedAt    time.Time
+}
+
+func (q *Queries) AddCollectionPost(ctx context.Context, arg AddCollectionPostParams) error {
+	_, err := q.db.ExecContext(ctx, addCollectionPost,
+		arg.CollectionID,
+		arg.PostID,
+		arg.Note,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createCollection = `-- name: CreateCollection :one
+INSERT INTO collections (id, created_at, updated_at, user_id, slug, name, description, kind, visibility)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, created_at, updated_at, user_id, slug, name, description, kind, visibility
+`
+
+type CreateCollectionParams struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	Slug        string
+	Name        string
+	Description sql.NullString
+	Kind        string
+	Visibility  string
+}
+
+func (q *Queries) CreateCollection(ctx context.Context, arg CreateCollectionParams) (Collection, error) {
+	row := q.db.QueryRowContext(ctx, createCollection,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.Slug,
+		arg.Name,
+		arg.Description,
+		arg.Kind,
+		arg.Visibility,
+	)
+	var i Collection
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Slug,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const deleteCollection = `-- name: DeleteCollection :exec
+DELETE FROM collections
+WHERE id = $1
+`
+
+func (q *Queries) DeleteCollection(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteCollection, id)
+	return err
+}
+
+const getCollectionBySlug = `-- name: GetCollectionBySlug :one
+SELECT collections.id, collections.created_at, collections.updated_at, collections.user_id, collections.slug, collections.name, collections.description, collections.kind, collections.visibility, users.name AS owner_name
+FROM collections
+JOIN users ON users.id = collections.user_id
+WHERE collections.slug = $1
+`
+
+type GetCollectionBySlugRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	Slug        string
+	Name        string
+	Description sql.NullString
+	Kind        string
+	Visibility  string
+	OwnerName   string
+}
+
+func (q *Queries) GetCollectionBySlug(ctx context.Context, slug string) (GetCollectionBySlugRow, error) {
+	row := q.db.QueryRowContext(ctx, getCollectionBySlug, slug)
+	var i GetCollectionBySlugRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Slug,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.Visibility,
+		&i.OwnerName,
+	)
+	return i, err
+}
+
+const getCollectionFeeds = `-- name: GetCollectionFeeds :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.kind, feeds.last_fetch_succeeded_at, feeds.deleted_at, feeds.site_url,
+    collection_feeds.note, collection_feeds.created_at AS added_at
+FROM collection_feeds
+JOIN feeds ON feeds.id = collection_feeds.feed_id
+WHERE collection_feeds.collection_id = $1
+AND feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+ORDER BY collection_feeds.created_at DESC, feeds.id DESC
+`
+
+type GetCollectionFeedsRow struct {
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.UUID
+	LastFetchedAt        sql.NullTime
+	Kind                 string
+	LastFetchSucceededAt sql.NullTime
+	DeletedAt            sql.NullTime
+	SiteUrl              sql.NullString
+	Note                 sql.NullString
+	AddedAt              time.Time
+}
+
+func (q *Queries) GetCollectionFeeds(ctx context.Context, collectionID uuid.UUID) ([]GetCollectionFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCollectionFeeds, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCollectionFeedsRow
+	for rows.Next() {
+		var i GetCollectionFeedsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Kind,
+			&i.LastFetchSucceededAt,
+			&i.DeletedAt,
+			&i.SiteUrl,
+			&i.Note,
+			&i.AddedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCollectionForUser = `-- name: GetCollectionForUser :one
+SELECT id, created_at, updated_at, user_id, slug, name, description, kind, visibility FROM collections
+WHERE id = $1 AND user_id = $2
+`
+
+type GetCollectionForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetCollectionForUser(ctx context.Context, arg GetCollectionForUserParams) (Collection, error) {
+	row := q.db.QueryRowContext(ctx, getCollectionForUser, arg.ID, arg.UserID)
+	var i Collection
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Slug,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getCollectionPosts = `-- name: GetCollectionPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories,
+    feeds.name AS feed_name, feeds.site_url AS feed_site_url,
+    collection_posts.note, collection_posts.created_at AS added_at
+FROM collection_posts
+JOIN posts ON posts.id = collection_posts.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE collection_posts.collection_id = $1
+AND feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+AND (
+    $3::TIMESTAMP IS NULL
+    OR (collection_posts.created_at, posts.id) < ($3::TIMESTAMP, $4::UUID)
+)
+ORDER BY collection_posts.created_at DESC, posts.id DESC
+LIMIT $2
+`
+
+type GetCollectionPostsParams struct {
+	CollectionID uuid.UUID
+	Limit        int32
+	CursorTime   sql.NullTime
+	CursorID     uuid.NullUUID
+}
+
+type GetCollectionPostsRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	OriginalUrl sql.NullString
+	Author      sql.NullString
+	Categories  []string
+	FeedName    string
+	FeedSiteUrl sql.NullString
+	Note        sql.NullString
+	AddedAt     time.Time
+}
+
+func (q *Queries) GetCollectionPosts(ctx context.Context, arg GetCollectionPostsParams) ([]GetCollectionPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCollectionPosts,
+		arg.CollectionID,
+		arg.Limit,
+		arg.CursorTime,
+		arg.CursorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCollectionPostsRow
+	for rows.Next() {
+		var i GetCollectionPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FeedName,
+			&i.FeedSiteUrl,
+			&i.Note,
+			&i.AddedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCollectionsForUser = `-- name: GetCollectionsForUser :many
+SELECT id, created_at, updated_at, user_id, slug, name, description, kind, visibility FROM collections
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetCollectionsForUser(ctx context.Context, userID uuid.UUID) ([]Collection, error) {
+	rows, err := q.db.QueryContext(ctx, getCollectionsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Collection
+	for rows.Next() {
+		var i Collection
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Slug,
+			&i.Name,
+			&i.Description,
+			&i.Kind,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPublicCollections = `-- name: GetPublicCollections :many
+SELECT collections.id, collections.created_at, collections.updated_at, collections.user_id, collections.slug, collections.name, collections.description, collections.kind, collections.visibility, users.name AS owner_name
+FROM collections
+JOIN users ON users.id = collections.user_id
+WHERE collections.visibility = 'public'
+AND (
+    $2::TIMESTAMP IS NULL
+    OR (collections.updated_at, collections.id) < ($2::TIMESTAMP, $3::UUID)
+)
+ORDER BY collections.updated_at DESC, collections.id DESC
+LIMIT $1
+`
+
+type GetPublicCollectionsParams struct {
+	Limit      int32
+	CursorTime sql.NullTime
+	CursorID   uuid.NullUUID
+}
+
+type GetPublicCollectionsRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	Slug        string
+	Name        string
+	Description sql.NullString
+	Kind        string
+	Visibility  string
+	OwnerName   string
+}
+
+func (q *Queries) GetPublicCollections(ctx context.Context, arg GetPublicCollectionsParams) ([]GetPublicCollectionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPublicCollections, arg.Limit, arg.CursorTime, arg.CursorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPublicCollectionsRow
+	for rows.Next() {
+		var i GetPublicCollectionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Slug,
+			&i.Name,
+			&i.Description,
+			&i.Kind,
+			&i.Visibility,
+			&i.OwnerName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentPostsInCollectionFeeds = `-- name: GetRecentPostsInCollectionFeeds :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories,
+    feeds.name AS feed_name, feeds.site_url AS feed_site_url
+FROM collection_feeds
+JOIN feeds ON feeds.id = collection_feeds.feed_id
+JOIN posts ON posts.feed_id = feeds.id
+WHERE collection_feeds.collection_id = $1
+AND feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
+LIMIT $2
+`
+
+type GetRecentPostsInCollectionFeedsParams struct {
+	CollectionID uuid.UUID
+	Limit        int32
+}
+
+type GetRecentPostsInCollectionFeedsRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	OriginalUrl sql.NullString
+	Author      sql.NullString
+	Categories  []string
+	FeedName    string
+	FeedSiteUrl sql.NullString
+}
+
+func (q *Queries) GetRecentPostsInCollectionFeeds(ctx context.Context, arg GetRecentPostsInCollectionFeedsParams) ([]GetRecentPostsInCollectionFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPostsInCollectionFeeds, arg.CollectionID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecentPostsInCollectionFeedsRow
+	for rows.Next() {
+		var i GetRecentPostsInCollectionFeedsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FeedName,
+			&i.FeedSiteUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeCollectionFeed = `-- name: RemoveCollectionFeed :execrows
+DELETE FROM collection_feeds
+WHERE collection_id = $1 AND feed_id = $2
+`
+
+type RemoveCollectionFeedParams struct {
+	CollectionID uuid.UUID
+	FeedID       uuid.UUID
+}
+
+func (q *Queries) RemoveCollectionFeed(ctx context.Context, arg RemoveCollectionFeedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeCollectionFeed, arg.CollectionID, arg.FeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeCollectionPost = `-- name: RemoveCollectionPost :execrows
+DELETE FROM collection_posts
+WHERE collection_id = $1 AND post_id = $2
+`
+
+type RemoveCollectionPostParams struct {
+	CollectionID uuid.UUID
+	PostID       uuid.UUID
+}
+
+func (q *Queries) RemoveCollectionPost(ctx context.Context, arg RemoveCollectionPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeCollectionPost, arg.CollectionID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const touchCollection = `-- name: TouchCollection :exec
+UPDATE collections
+SET updated_at = $2
+WHERE id = $1
+`
+
+type TouchCollectionParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) TouchCollection(ctx context.Context, arg TouchCollectionParams) error {
+	_, err := q.db.ExecContext(ctx, touchCollection, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const updateCollection = `-- name: UpdateCollection :one
+UPDATE collections
+SET slug = $2, name = $3, description = $4, visibility = $5, updated_at = $6
+WHERE id = $1
+RETURNING id, created_at, updated_at, user_id, slug, name, description, kind, visibility
+`
+
+type UpdateCollectionParams struct {
+	ID          uuid.UUID
+	Slug        string
+	Name        string
+	Description sql.NullString
+	Visibility  string
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) UpdateCollection(ctx context.Context, arg UpdateCollectionParams) (Collection, error) {
+	row := q.db.QueryRowContext(ctx, updateCollection,
+		arg.ID,
+		arg.Slug,
+		arg.Name,
+		arg.Description,
+		arg.Visibility,
+		arg.UpdatedAt,
+	)
+	var i Collection
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Slug,
+		&i.Name,
+		&i.Description,
+		&i.Kind,
+		&i.Visibility,
+	)
+	return i, err
+}
@@ -0,0 +1,149 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const getActiveFeedBackfills = `-- name: GetActiveFeedBackfills :many
+SELECT feed_backfills.feed_id, feed_backfills.created_at, feed_backfills.updated_at, feed_backfills.status, feed_backfills.next_url, feed_backfills.pages_fetched, feed_backfills.max_pages, feed_backfills.items_found, feed_backfills.error FROM feed_backfills
+JOIN feeds ON feeds.id = feed_backfills.feed_id
+WHERE feed_backfills.status IN ('pending', 'running')
+AND feeds.deleted_at IS NULL
+ORDER BY feed_backfills.updated_at ASC
+LIMIT $1
+`
+
+func (q *Queries) GetActiveFeedBackfills(ctx context.Context, limit int32) ([]FeedBackfill, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveFeedBackfills, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedBackfill
+	for rows.Next() {
+		var i FeedBackfill
+		if err := rows.Scan(
+			&i.FeedID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.NextUrl,
+			&i.PagesFetched,
+			&i.MaxPages,
+			&i.ItemsFound,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedBackfill = `-- name: GetFeedBackfill :one
+SELECT feed_id, created_at, updated_at, status, next_url, pages_fetched, max_pages, items_found, error FROM feed_backfills
+WHERE feed_id = $1
+`
+
+func (q *Queries) GetFeedBackfill(ctx context.Context, feedID uuid.UUID) (FeedBackfill, error) {
+	row := q.db.QueryRowContext(ctx, getFeedBackfill, feedID)
+	var i FeedBackfill
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.NextUrl,
+		&i.PagesFetched,
+		&i.MaxPages,
+		&i.ItemsFound,
+		&i.Error,
+	)
+	return i, err
+}
+
+const startFeedBackfill = `-- name: StartFeedBackfill :one
+INSERT INTO feed_backfills (feed_id, created_at, updated_at, status, max_pages)
+VALUES ($1, $2, $3, 'pending', $4)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+status = 'pending',
+next_url = NULL,
+pages_fetched = 0,
+max_pages = EXCLUDED.max_pages,
+items_found = 0,
+error = NULL
+RETURNING feed_id, created_at, updated_at, status, next_url, pages_fetched, max_pages, items_found, error
+`
+
+type StartFeedBackfillParams struct {
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	MaxPages  int32
+}
+
+func (q *Queries) StartFeedBackfill(ctx context.Context, arg StartFeedBackfillParams) (FeedBackfill, error) {
+	row := q.db.QueryRowContext(ctx, startFeedBackfill,
+		arg.FeedID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.MaxPages,
+	)
+	var i FeedBackfill
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.NextUrl,
+		&i.PagesFetched,
+		&i.MaxPages,
+		&i.ItemsFound,
+		&i.Error,
+	)
+	return i, err
+}
+
+const updateFeedBackfillProgress = `-- name: UpdateFeedBackfillProgress :exec
+UPDATE feed_backfills
+SET status = $2,
+next_url = $3,
+pages_fetched = $4,
+items_found = $5,
+error = $6,
+updated_at = NOW()
+WHERE feed_id = $1
+`
+
+type UpdateFeedBackfillProgressParams struct {
+	FeedID       uuid.UUID
+	Status       string
+	NextUrl      sql.NullString
+	PagesFetched int32
+	ItemsFound   int32
+	Error        sql.NullString
+}
+
+func (q *Queries) UpdateFeedBackfillProgress(ctx context.Context, arg UpdateFeedBackfillProgressParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedBackfillProgress,
+		arg.FeedID,
+		arg.Status,
+		arg.NextUrl,
+		arg.PagesFetched,
+		arg.ItemsFound,
+		arg.Error,
+	)
+	return err
+}
@@ -0,0 +1,74 @@
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteFeedCredentials = `-- name: DeleteFeedCredentials :exec
+DELETE FROM feed_credentials
+WHERE feed_id = $1
+`
+
+func (q *Queries) DeleteFeedCredentials(ctx context.Context, feedID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedCredentials, feedID)
+	return err
+}
+
+const getFeedCredentials = `-- name: GetFeedCredentials :one
+SELECT feed_id, created_at, updated_at, auth_type, secret FROM feed_credentials
+WHERE feed_id = $1
+`
+
+func (q *Queries) GetFeedCredentials(ctx context.Context, feedID uuid.UUID) (FeedCredential, error) {
+	row := q.db.QueryRowContext(ctx, getFeedCredentials, feedID)
+	var i FeedCredential
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AuthType,
+		&i.Secret,
+	)
+	return i, err
+}
+
+const upsertFeedCredentials = `-- name: UpsertFeedCredentials :one
+INSERT INTO feed_credentials (feed_id, created_at, updated_at, auth_type, secret)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+auth_type = EXCLUDED.auth_type,
+secret = EXCLUDED.secret
+RETURNING feed_id, created_at, updated_at, auth_type, secret
+`
+
+type UpsertFeedCredentialsParams struct {
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	AuthType  string
+	Secret    []byte
+}
+
+func (q *Queries) UpsertFeedCredentials(ctx context.Context, arg UpsertFeedCredentialsParams) (FeedCredential, error) {
+	row := q.db.QueryRowContext(ctx, upsertFeedCredentials,
+		arg.FeedID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.AuthType,
+		arg.Secret,
+	)
+	var i FeedCredential
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AuthType,
+		&i.Secret,
+	)
+	return i, err
+}
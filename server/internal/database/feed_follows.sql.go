@@ -3,16 +3,17 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const createFeedFollow = `-- name: CreateFeedFollow :one
-
 INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at, user_id, feed_id
+RETURNING id, created_at, updated_at, user_id, feed_id, folder_id, position, title, hide_from_timeline, full_content, sort_order;
+--
 `
 
 type CreateFeedFollowParams struct {
@@ -38,6 +39,12 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.FolderID,
+		&i.Position,
+		&i.Title,
+		&i.HideFromTimeline,
+		&i.FullContent,
+		&i.SortOrder,
 	)
 	return i, err
 }
@@ -57,8 +64,131 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 	return err
 }
 
+const deleteFeedFollowsForFeed = `-- name: DeleteFeedFollowsForFeed :many
+DELETE FROM feed_follows
+WHERE feed_id = $1
+RETURNING user_id
+`
+
+func (q *Queries) DeleteFeedFollowsForFeed(ctx context.Context, feedID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteFeedFollowsForFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteOtherFeedFollowsForFeed = `-- name: DeleteOtherFeedFollowsForFeed :many
+DELETE FROM feed_follows
+WHERE feed_id = $1 AND user_id <> $2
+RETURNING user_id
+`
+
+type DeleteOtherFeedFollowsForFeedParams struct {
+	FeedID uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteOtherFeedFollowsForFeed(ctx context.Context, arg DeleteOtherFeedFollowsForFeedParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteOtherFeedFollowsForFeed, arg.FeedID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedFollowForFeed = `-- name: GetFeedFollowForFeed :one
+SELECT id, created_at, updated_at, user_id, feed_id, folder_id, position, title, hide_from_timeline, full_content, sort_order FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2
+`
+
+type GetFeedFollowForFeedParams struct {
+	UserID uuid.UUID
+	FeedID uuid.UUID
+}
+
+func (q *Queries) GetFeedFollowForFeed(ctx context.Context, arg GetFeedFollowForFeedParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollowForFeed, arg.UserID, arg.FeedID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+		&i.Position,
+		&i.Title,
+		&i.HideFromTimeline,
+		&i.FullContent,
+		&i.SortOrder,
+	)
+	return i, err
+}
+
+const getFeedFollowForUser = `-- name: GetFeedFollowForUser :one
+SELECT id, created_at, updated_at, user_id, feed_id, folder_id, position, title, hide_from_timeline, full_content, sort_order FROM feed_follows
+WHERE id = $1 AND user_id = $2
+`
+
+type GetFeedFollowForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetFeedFollowForUser(ctx context.Context, arg GetFeedFollowForUserParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollowForUser, arg.ID, arg.UserID)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+		&i.Position,
+		&i.Title,
+		&i.HideFromTimeline,
+		&i.FullContent,
+		&i.SortOrder,
+	)
+	return i, err
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
-SELECT id, created_at, updated_at, user_id, feed_id FROM feed_follows WHERE user_id = $1
+SELECT id, created_at, updated_at, user_id, feed_id, folder_id, position, title, hide_from_timeline, full_content, sort_order FROM feed_follows WHERE user_id = $1
+ORDER BY position, created_at;
+--
 `
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]FeedFollow, error) {
@@ -76,6 +206,12 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.FolderID,
+			&i.Position,
+			&i.Title,
+			&i.HideFromTimeline,
+			&i.FullContent,
+			&i.SortOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -89,3 +225,99 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const getFollowedFeedsForExport = `-- name: GetFollowedFeedsForExport :many
+SELECT feed_follows.feed_id, feed_follows.folder_id, feed_follows.title, feeds.name, feeds.url, feeds.site_url, feeds.kind
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+AND feeds.deleted_at IS NULL
+ORDER BY feed_follows.position, feed_follows.created_at
+`
+
+type GetFollowedFeedsForExportRow struct {
+	FeedID   uuid.UUID
+	FolderID uuid.NullUUID
+	Title    sql.NullString
+	Name     string
+	Url      string
+	SiteUrl  sql.NullString
+	Kind     string
+}
+
+func (q *Queries) GetFollowedFeedsForExport(ctx context.Context, userID uuid.UUID) ([]GetFollowedFeedsForExportRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedFeedsForExport, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFollowedFeedsForExportRow
+	for rows.Next() {
+		var i GetFollowedFeedsForExportRow
+		if err := rows.Scan(
+			&i.FeedID,
+			&i.FolderID,
+			&i.Title,
+			&i.Name,
+			&i.Url,
+			&i.SiteUrl,
+			&i.Kind,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateFeedFollow = `-- name: UpdateFeedFollow :one
+UPDATE feed_follows
+SET folder_id = $2, position = $3, title = $4, hide_from_timeline = $5, full_content = $6, sort_order = $7, updated_at = $8
+WHERE id = $1
+RETURNING id, created_at, updated_at, user_id, feed_id, folder_id, position, title, hide_from_timeline, full_content, sort_order
+`
+
+type UpdateFeedFollowParams struct {
+	ID               uuid.UUID
+	FolderID         uuid.NullUUID
+	Position         int32
+	Title            sql.NullString
+	HideFromTimeline bool
+	FullContent      bool
+	SortOrder        string
+	UpdatedAt        time.Time
+}
+
+func (q *Queries) UpdateFeedFollow(ctx context.Context, arg UpdateFeedFollowParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedFollow,
+		arg.ID,
+		arg.FolderID,
+		arg.Position,
+		arg.Title,
+		arg.HideFromTimeline,
+		arg.FullContent,
+		arg.SortOrder,
+		arg.UpdatedAt,
+	)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FolderID,
+		&i.Position,
+		&i.Title,
+		&i.HideFromTimeline,
+		&i.FullContent,
+		&i.SortOrder,
+	)
+	return i, err
+}
@@ -0,0 +1,70 @@
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteFeedProcessors = `-- name: DeleteFeedProcessors :exec
+DELETE FROM feed_processors
+WHERE feed_id = $1
+`
+
+func (q *Queries) DeleteFeedProcessors(ctx context.Context, feedID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedProcessors, feedID)
+	return err
+}
+
+const getFeedProcessors = `-- name: GetFeedProcessors :one
+SELECT feed_id, created_at, updated_at, config FROM feed_processors
+WHERE feed_id = $1
+`
+
+func (q *Queries) GetFeedProcessors(ctx context.Context, feedID uuid.UUID) (FeedProcessor, error) {
+	row := q.db.QueryRowContext(ctx, getFeedProcessors, feedID)
+	var i FeedProcessor
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Config,
+	)
+	return i, err
+}
+
+const upsertFeedProcessors = `-- name: UpsertFeedProcessors :one
+INSERT INTO feed_processors (feed_id, created_at, updated_at, config)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+config = EXCLUDED.config
+RETURNING feed_id, created_at, updated_at, config
+`
+
+type UpsertFeedProcessorsParams struct {
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Config    json.RawMessage
+}
+
+func (q *Queries) UpsertFeedProcessors(ctx context.Context, arg UpsertFeedProcessorsParams) (FeedProcessor, error) {
+	row := q.db.QueryRowContext(ctx, upsertFeedProcessors,
+		arg.FeedID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Config,
+	)
+	var i FeedProcessor
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Config,
+	)
+	return i, err
+}
@@ -0,0 +1,80 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const getFeedSelectors = `-- name: GetFeedSelectors :one
+SELECT feed_id, created_at, updated_at, item_selector, title_selector, link_selector, date_selector, summary_selector FROM feed_selectors
+WHERE feed_id = $1
+`
+
+func (q *Queries) GetFeedSelectors(ctx context.Context, feedID uuid.UUID) (FeedSelector, error) {
+	row := q.db.QueryRowContext(ctx, getFeedSelectors, feedID)
+	var i FeedSelector
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ItemSelector,
+		&i.TitleSelector,
+		&i.LinkSelector,
+		&i.DateSelector,
+		&i.SummarySelector,
+	)
+	return i, err
+}
+
+const upsertFeedSelectors = `-- name: UpsertFeedSelectors :one
+INSERT INTO feed_selectors (feed_id, created_at, updated_at, item_selector, title_selector, link_selector, date_selector, summary_selector)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+item_selector = EXCLUDED.item_selector,
+title_selector = EXCLUDED.title_selector,
+link_selector = EXCLUDED.link_selector,
+date_selector = EXCLUDED.date_selector,
+summary_selector = EXCLUDED.summary_selector
+RETURNING feed_id, created_at, updated_at, item_selector, title_selector, link_selector, date_selector, summary_selector
+`
+
+type UpsertFeedSelectorsParams struct {
+	FeedID          uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ItemSelector    string
+	TitleSelector   string
+	LinkSelector    string
+	DateSelector    sql.NullString
+	SummarySelector sql.NullString
+}
+
+func (q *Queries) UpsertFeedSelectors(ctx context.Context, arg UpsertFeedSelectorsParams) (FeedSelector, error) {
+	row := q.db.QueryRowContext(ctx, upsertFeedSelectors,
+		arg.FeedID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.ItemSelector,
+		arg.TitleSelector,
+		arg.LinkSelector,
+		arg.DateSelector,
+		arg.SummarySelector,
+	)
+	var i FeedSelector
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ItemSelector,
+		&i.TitleSelector,
+		&i.LinkSelector,
+		&i.DateSelector,
+		&i.SummarySelector,
+	)
+	return i, err
+}
@@ -0,0 +1,233 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const getFeedAudienceCounts = `-- name: GetFeedAudienceCounts :one
+SELECT
+(SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = $1) AS followers,
+(SELECT COUNT(*) FROM starred_feeds WHERE starred_feeds.feed_id = $1) AS starrers
+`
+
+type GetFeedAudienceCountsRow struct {
+	Followers int64
+	Starrers  int64
+}
+
+func (q *Queries) GetFeedAudienceCounts(ctx context.Context, feedID uuid.UUID) (GetFeedAudienceCountsRow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedAudienceCounts, feedID)
+	var i GetFeedAudienceCountsRow
+	err := row.Scan(
+		&i.Followers,
+		&i.Starrers,
+	)
+	return i, err
+}
+
+const getFeedPostCountsByDay = `-- name: GetFeedPostCountsByDay :many
+SELECT date_trunc('day', COALESCE(published_at, created_at))::TIMESTAMP AS day, COUNT(*) AS posts
+FROM posts
+WHERE feed_id = $1
+AND COALESCE(published_at, created_at) >= $2
+GROUP BY day
+ORDER BY day
+`
+
+type GetFeedPostCountsByDayParams struct {
+	FeedID uuid.UUID
+	Since  time.Time
+}
+
+type GetFeedPostCountsByDayRow struct {
+	Day   time.Time
+	Posts int64
+}
+
+func (q *Queries) GetFeedPostCountsByDay(ctx context.Context, arg GetFeedPostCountsByDayParams) ([]GetFeedPostCountsByDayRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedPostCountsByDay, arg.FeedID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedPostCountsByDayRow
+	for rows.Next() {
+		var i GetFeedPostCountsByDayRow
+		if err := rows.Scan(
+			&i.Day,
+			&i.Posts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedPostCountsByWeek = `-- name: GetFeedPostCountsByWeek :many
+SELECT date_trunc('week', COALESCE(published_at, created_at))::TIMESTAMP AS week, COUNT(*) AS posts
+FROM posts
+WHERE feed_id = $1
+AND COALESCE(published_at, created_at) >= $2
+GROUP BY week
+ORDER BY week
+`
+
+type GetFeedPostCountsByWeekParams struct {
+	FeedID uuid.UUID
+	Since  time.Time
+}
+
+type GetFeedPostCountsByWeekRow struct {
+	Week  time.Time
+	Posts int64
+}
+
+func (q *Queries) GetFeedPostCountsByWeek(ctx context.Context, arg GetFeedPostCountsByWeekParams) ([]GetFeedPostCountsByWeekRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedPostCountsByWeek, arg.FeedID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedPostCountsByWeekRow
+	for rows.Next() {
+		var i GetFeedPostCountsByWeekRow
+		if err := rows.Scan(
+			&i.Week,
+			&i.Posts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedPostTotals = `-- name: GetFeedPostTotals :one
+SELECT totals.total_posts, totals.average_length, latest.latest_post_at
+FROM (
+    SELECT COUNT(*) AS total_posts,
+    COALESCE(AVG(LENGTH(COALESCE(description, ''))), 0)::FLOAT8 AS average_length
+    FROM posts
+    WHERE feed_id = $1
+) totals
+LEFT JOIN LATERAL (
+    SELECT COALESCE(published_at, created_at) AS latest_post_at
+    FROM posts
+    WHERE feed_id = $1
+    ORDER BY COALESCE(published_at, created_at) DESC
+    LIMIT 1
+) latest ON TRUE
+`
+
+type GetFeedPostTotalsRow struct {
+	TotalPosts    int64
+	AverageLength float64
+	LatestPostAt  sql.NullTime
+}
+
+func (q *Queries) GetFeedPostTotals(ctx context.Context, feedID uuid.UUID) (GetFeedPostTotalsRow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedPostTotals, feedID)
+	var i GetFeedPostTotalsRow
+	err := row.Scan(
+		&i.TotalPosts,
+		&i.AverageLength,
+		&i.LatestPostAt,
+	)
+	return i, err
+}
+
+const getFeedPostingHours = `-- name: GetFeedPostingHours :many
+SELECT EXTRACT(HOUR FROM published_at)::INTEGER AS hour, COUNT(*) AS posts
+FROM posts
+WHERE feed_id = $1
+AND published_at IS NOT NULL
+GROUP BY hour
+`
+
+type GetFeedPostingHoursRow struct {
+	Hour  int32
+	Posts int64
+}
+
+func (q *Queries) GetFeedPostingHours(ctx context.Context, feedID uuid.UUID) ([]GetFeedPostingHoursRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedPostingHours, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedPostingHoursRow
+	for rows.Next() {
+		var i GetFeedPostingHoursRow
+		if err := rows.Scan(
+			&i.Hour,
+			&i.Posts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedPostingWeekdays = `-- name: GetFeedPostingWeekdays :many
+SELECT EXTRACT(DOW FROM published_at)::INTEGER AS weekday, COUNT(*) AS posts
+FROM posts
+WHERE feed_id = $1
+AND published_at IS NOT NULL
+GROUP BY weekday
+`
+
+type GetFeedPostingWeekdaysRow struct {
+	Weekday int32
+	Posts   int64
+}
+
+func (q *Queries) GetFeedPostingWeekdays(ctx context.Context, feedID uuid.UUID) ([]GetFeedPostingWeekdaysRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedPostingWeekdays, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedPostingWeekdaysRow
+	for rows.Next() {
+		var i GetFeedPostingWeekdaysRow
+		if err := rows.Scan(
+			&i.Weekday,
+			&i.Posts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
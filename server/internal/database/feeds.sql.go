@@ -3,15 +3,16 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, kind)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url
 `
 
 type CreateFeedParams struct {
@@ -21,6 +22,7 @@ type CreateFeedParams struct {
 	Name      string
 	Url       string
 	UserID    uuid.UUID
+	Kind      string
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -31,6 +33,7 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.Name,
 		arg.Url,
 		arg.UserID,
+		arg.Kind,
 	)
 	var i Feed
 	err := row.Scan(
@@ -41,12 +44,123 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Kind,
+		&i.LastFetchSucceededAt,
+		&i.DeletedAt,
+		&i.SiteUrl,
+	)
+	return i, err
+}
+
+const feedURLInUse = `-- name: FeedURLInUse :one
+SELECT EXISTS (
+    SELECT 1 FROM feeds
+    WHERE url = $1 AND id <> $2 AND deleted_at IS NULL
+)
+`
+
+type FeedURLInUseParams struct {
+	Url string
+	ID  uuid.UUID
+}
+
+func (q *Queries) FeedURLInUse(ctx context.Context, arg FeedURLInUseParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, feedURLInUse, arg.Url, arg.ID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const getFeed = `-- name: GetFeed :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) GetFeed(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeed, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Kind,
+		&i.LastFetchSucceededAt,
+		&i.DeletedAt,
+		&i.SiteUrl,
+	)
+	return i, err
+}
+
+const getFeedByURL = `-- name: GetFeedByURL :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url FROM feeds
+WHERE url = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByURL, url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Kind,
+		&i.LastFetchSucceededAt,
+		&i.DeletedAt,
+		&i.SiteUrl,
+	)
+	return i, err
+}
+
+const getFeedVisibleToUser = `-- name: GetFeedVisibleToUser :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url FROM feeds
+WHERE id = $1
+AND deleted_at IS NULL
+AND (
+    user_id = $2
+    OR (
+        kind <> 'newsletter'
+        AND NOT EXISTS (
+            SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+        )
+    )
+)
+`
+
+type GetFeedVisibleToUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetFeedVisibleToUser(ctx context.Context, arg GetFeedVisibleToUserParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedVisibleToUser, arg.ID, arg.UserID)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Kind,
+		&i.LastFetchSucceededAt,
+		&i.DeletedAt,
+		&i.SiteUrl,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url FROM feeds
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -66,6 +180,59 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Kind,
+			&i.LastFetchSucceededAt,
+			&i.DeletedAt,
+			&i.SiteUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsVisibleToUser = `-- name: GetFeedsVisibleToUser :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url FROM feeds
+WHERE deleted_at IS NULL
+AND (
+    user_id = $1
+    OR (
+        kind <> 'newsletter'
+        AND NOT EXISTS (
+            SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+        )
+    )
+)
+`
+
+func (q *Queries) GetFeedsVisibleToUser(ctx context.Context, userID uuid.UUID) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsVisibleToUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Kind,
+			&i.LastFetchSucceededAt,
+			&i.DeletedAt,
+			&i.SiteUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -81,7 +248,9 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 }
 
 const getNextFeedsToFetch = `-- name: GetNextFeedsToFetch :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url FROM feeds
+WHERE kind <> 'newsletter'
+AND deleted_at IS NULL
 ORDER BY last_fetched_at ASC NULLS FIRST
 LIMIT $1
 `
@@ -103,6 +272,54 @@ func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed,
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Kind,
+			&i.LastFetchSucceededAt,
+			&i.DeletedAt,
+			&i.SiteUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPublicFeeds = `-- name: GetPublicFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url FROM feeds
+WHERE kind <> 'newsletter'
+AND deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+`
+
+func (q *Queries) GetPublicFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getPublicFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Kind,
+			&i.LastFetchSucceededAt,
+			&i.DeletedAt,
+			&i.SiteUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -117,12 +334,29 @@ func (q *Queries) GetNextFeedsToFetch(ctx context.Context, limit int32) ([]Feed,
 	return items, nil
 }
 
+const markFeedFetchSucceeded = `-- name: MarkFeedFetchSucceeded :exec
+UPDATE feeds
+SET last_fetch_succeeded_at = NOW(),
+site_url = COALESCE($2, site_url)
+WHERE id = $1
+`
+
+type MarkFeedFetchSucceededParams struct {
+	ID      uuid.UUID
+	SiteUrl sql.NullString
+}
+
+func (q *Queries) MarkFeedFetchSucceeded(ctx context.Context, arg MarkFeedFetchSucceededParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetchSucceeded, arg.ID, arg.SiteUrl)
+	return err
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :one
 UPDATE feeds
 SET last_fetched_at = NOW(),
 updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url
 `
 
 func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, error) {
@@ -136,6 +370,67 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) (Feed, erro
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Kind,
+		&i.LastFetchSucceededAt,
+		&i.DeletedAt,
+		&i.SiteUrl,
+	)
+	return i, err
+}
+
+const softDeleteFeed = `-- name: SoftDeleteFeed :exec
+UPDATE feeds
+SET deleted_at = $2, updated_at = $2
+WHERE id = $1
+`
+
+type SoftDeleteFeedParams struct {
+	ID        uuid.UUID
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) SoftDeleteFeed(ctx context.Context, arg SoftDeleteFeedParams) error {
+	_, err := q.db.ExecContext(ctx, softDeleteFeed, arg.ID, arg.DeletedAt)
+	return err
+}
+
+const updateFeed = `-- name: UpdateFeed :one
+UPDATE feeds
+SET name = $2,
+url = $3,
+last_fetched_at = CASE WHEN url = $3 THEN last_fetched_at END,
+updated_at = $4
+WHERE id = $1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, kind, last_fetch_succeeded_at, deleted_at, site_url
+`
+
+type UpdateFeedParams struct {
+	ID        uuid.UUID
+	Name      string
+	Url       string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateFeed(ctx context.Context, arg UpdateFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeed,
+		arg.ID,
+		arg.Name,
+		arg.Url,
+		arg.UpdatedAt,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Kind,
+		&i.LastFetchSucceededAt,
+		&i.DeletedAt,
+		&i.SiteUrl,
 	)
 	return i, err
 }
@@ -0,0 +1,247 @@
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createFilterRule = `-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, created_at, updated_at, user_id, name, enabled, match_any, conditions, actions)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, created_at, updated_at, user_id, name, enabled, match_any, conditions, actions
+`
+
+type CreateFilterRuleParams struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	Enabled    bool
+	MatchAny   bool
+	Conditions json.RawMessage
+	Actions    json.RawMessage
+}
+
+func (q *Queries) CreateFilterRule(ctx context.Context, arg CreateFilterRuleParams) (FilterRule, error) {
+	row := q.db.QueryRowContext(ctx, createFilterRule,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.Name,
+		arg.Enabled,
+		arg.MatchAny,
+		arg.Conditions,
+		arg.Actions,
+	)
+	var i FilterRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.Enabled,
+		&i.MatchAny,
+		&i.Conditions,
+		&i.Actions,
+	)
+	return i, err
+}
+
+const deleteFilterRule = `-- name: DeleteFilterRule :exec
+DELETE FROM filter_rules
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFilterRule(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFilterRule, id)
+	return err
+}
+
+const getEnabledFilterRulesForFeed = `-- name: GetEnabledFilterRulesForFeed :many
+SELECT filter_rules.id, filter_rules.created_at, filter_rules.updated_at, filter_rules.user_id, filter_rules.name, filter_rules.enabled, filter_rules.match_any, filter_rules.conditions, filter_rules.actions FROM filter_rules
+JOIN feed_follows ON feed_follows.user_id = filter_rules.user_id
+WHERE feed_follows.feed_id = $1
+AND filter_rules.enabled
+ORDER BY filter_rules.user_id, filter_rules.created_at
+`
+
+func (q *Queries) GetEnabledFilterRulesForFeed(ctx context.Context, feedID uuid.UUID) ([]FilterRule, error) {
+	rows, err := q.db.QueryContext(ctx, getEnabledFilterRulesForFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FilterRule
+	for rows.Next() {
+		var i FilterRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+			&i.Enabled,
+			&i.MatchAny,
+			&i.Conditions,
+			&i.Actions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFilterRuleForUser = `-- name: GetFilterRuleForUser :one
+SELECT id, created_at, updated_at, user_id, name, enabled, match_any, conditions, actions FROM filter_rules
+WHERE id = $1 AND user_id = $2
+`
+
+type GetFilterRuleForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetFilterRuleForUser(ctx context.Context, arg GetFilterRuleForUserParams) (FilterRule, error) {
+	row := q.db.QueryRowContext(ctx, getFilterRuleForUser, arg.ID, arg.UserID)
+	var i FilterRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.Enabled,
+		&i.MatchAny,
+		&i.Conditions,
+		&i.Actions,
+	)
+	return i, err
+}
+
+const getFilterRulesForUser = `-- name: GetFilterRulesForUser :many
+SELECT id, created_at, updated_at, user_id, name, enabled, match_any, conditions, actions FROM filter_rules
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetFilterRulesForUser(ctx context.Context, userID uuid.UUID) ([]FilterRule, error) {
+	rows, err := q.db.QueryContext(ctx, getFilterRulesForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FilterRule
+	for rows.Next() {
+		var i FilterRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+			&i.Enabled,
+			&i.MatchAny,
+			&i.Conditions,
+			&i.Actions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hidePost = `-- name: HidePost :exec
+INSERT INTO hidden_posts (user_id, post_id, rule_id, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, post_id, rule_id) DO NOTHING
+`
+
+type HidePostParams struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	RuleID    uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) HidePost(ctx context.Context, arg HidePostParams) error {
+	_, err := q.db.ExecContext(ctx, hidePost,
+		arg.UserID,
+		arg.PostID,
+		arg.RuleID,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const unhidePostsForRule = `-- name: UnhidePostsForRule :exec
+DELETE FROM hidden_posts
+WHERE rule_id = $1
+`
+
+func (q *Queries) UnhidePostsForRule(ctx context.Context, ruleID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, unhidePostsForRule, ruleID)
+	return err
+}
+
+const updateFilterRule = `-- name: UpdateFilterRule :one
+UPDATE filter_rules
+SET name = $2, enabled = $3, match_any = $4, conditions = $5, actions = $6, updated_at = $7
+WHERE id = $1
+RETURNING id, created_at, updated_at, user_id, name, enabled, match_any, conditions, actions
+`
+
+type UpdateFilterRuleParams struct {
+	ID         uuid.UUID
+	Name       string
+	Enabled    bool
+	MatchAny   bool
+	Conditions json.RawMessage
+	Actions    json.RawMessage
+	UpdatedAt  time.Time
+}
+
+func (q *Queries) UpdateFilterRule(ctx context.Context, arg UpdateFilterRuleParams) (FilterRule, error) {
+	row := q.db.QueryRowContext(ctx, updateFilterRule,
+		arg.ID,
+		arg.Name,
+		arg.Enabled,
+		arg.MatchAny,
+		arg.Conditions,
+		arg.Actions,
+		arg.UpdatedAt,
+	)
+	var i FilterRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.Enabled,
+		&i.MatchAny,
+		&i.Conditions,
+		&i.Actions,
+	)
+	return i, err
+}
@@ -0,0 +1,200 @@
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createFolder = `-- name: CreateFolder :one
+INSERT INTO folders (id, created_at, updated_at, user_id, parent_id, name, position)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at, updated_at, user_id, parent_id, name, position
+`
+
+type CreateFolderParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	ParentID  uuid.NullUUID
+	Name      string
+	Position  int32
+}
+
+func (q *Queries) CreateFolder(ctx context.Context, arg CreateFolderParams) (Folder, error) {
+	row := q.db.QueryRowContext(ctx, createFolder,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.ParentID,
+		arg.Name,
+		arg.Position,
+	)
+	var i Folder
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.ParentID,
+		&i.Name,
+		&i.Position,
+	)
+	return i, err
+}
+
+const deleteFolder = `-- name: DeleteFolder :exec
+DELETE FROM folders
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFolder(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFolder, id)
+	return err
+}
+
+const folderHasSubfolders = `-- name: FolderHasSubfolders :one
+SELECT EXISTS (
+    SELECT 1 FROM folders
+    WHERE parent_id = $1
+)
+`
+
+func (q *Queries) FolderHasSubfolders(ctx context.Context, parentID uuid.NullUUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, folderHasSubfolders, parentID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const getFolderByName = `-- name: GetFolderByName :one
+SELECT id, created_at, updated_at, user_id, parent_id, name, position FROM folders
+WHERE user_id = $1
+AND parent_id IS NOT DISTINCT FROM $2
+AND lower(name) = lower($3)
+ORDER BY created_at
+LIMIT 1
+`
+
+type GetFolderByNameParams struct {
+	UserID   uuid.UUID
+	ParentID uuid.NullUUID
+	Name     string
+}
+
+func (q *Queries) GetFolderByName(ctx context.Context, arg GetFolderByNameParams) (Folder, error) {
+	row := q.db.QueryRowContext(ctx, getFolderByName, arg.UserID, arg.ParentID, arg.Name)
+	var i Folder
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.ParentID,
+		&i.Name,
+		&i.Position,
+	)
+	return i, err
+}
+
+const getFolderForUser = `-- name: GetFolderForUser :one
+SELECT id, created_at, updated_at, user_id, parent_id, name, position FROM folders
+WHERE id = $1 AND user_id = $2
+`
+
+type GetFolderForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetFolderForUser(ctx context.Context, arg GetFolderForUserParams) (Folder, error) {
+	row := q.db.QueryRowContext(ctx, getFolderForUser, arg.ID, arg.UserID)
+	var i Folder
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.ParentID,
+		&i.Name,
+		&i.Position,
+	)
+	return i, err
+}
+
+const getFoldersForUser = `-- name: GetFoldersForUser :many
+SELECT id, created_at, updated_at, user_id, parent_id, name, position FROM folders
+WHERE user_id = $1
+ORDER BY position, lower(name)
+`
+
+func (q *Queries) GetFoldersForUser(ctx context.Context, userID uuid.UUID) ([]Folder, error) {
+	rows, err := q.db.QueryContext(ctx, getFoldersForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Folder
+	for rows.Next() {
+		var i Folder
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.ParentID,
+			&i.Name,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateFolder = `-- name: UpdateFolder :one
+UPDATE folders
+SET parent_id = $2, name = $3, position = $4, updated_at = $5
+WHERE id = $1
+RETURNING id, created_at, updated_at, user_id, parent_id, name, position
+`
+
+type UpdateFolderParams struct {
+	ID        uuid.UUID
+	ParentID  uuid.NullUUID
+	Name      string
+	Position  int32
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateFolder(ctx context.Context, arg UpdateFolderParams) (Folder, error) {
+	row := q.db.QueryRowContext(ctx, updateFolder,
+		arg.ID,
+		arg.ParentID,
+		arg.Name,
+		arg.Position,
+		arg.UpdatedAt,
+	)
+	var i Folder
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.ParentID,
+		&i.Name,
+		&i.Position,
+	)
+	return i, err
+}
@@ -0,0 +1,125 @@
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createInboundAddress = `-- name: CreateInboundAddress :one
+INSERT INTO inbound_addresses (id, created_at, updated_at, user_id, feed_id, token)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, user_id, feed_id, token
+`
+
+type CreateInboundAddressParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	Token     string
+}
+
+func (q *Queries) CreateInboundAddress(ctx context.Context, arg CreateInboundAddressParams) (InboundAddress, error) {
+	row := q.db.QueryRowContext(ctx, createInboundAddress,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+		arg.Token,
+	)
+	var i InboundAddress
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Token,
+	)
+	return i, err
+}
+
+const deleteInboundAddress = `-- name: DeleteInboundAddress :exec
+DELETE FROM inbound_addresses
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteInboundAddressParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteInboundAddress(ctx context.Context, arg DeleteInboundAddressParams) error {
+	_, err := q.db.ExecContext(ctx, deleteInboundAddress, arg.ID, arg.UserID)
+	return err
+}
+
+const deleteInboundAddressesForFeed = `-- name: DeleteInboundAddressesForFeed :exec
+DELETE FROM inbound_addresses
+WHERE feed_id = $1
+`
+
+func (q *Queries) DeleteInboundAddressesForFeed(ctx context.Context, feedID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteInboundAddressesForFeed, feedID)
+	return err
+}
+
+const getInboundAddressByToken = `-- name: GetInboundAddressByToken :one
+SELECT id, created_at, updated_at, user_id, feed_id, token FROM inbound_addresses
+WHERE token = $1
+`
+
+func (q *Queries) GetInboundAddressByToken(ctx context.Context, token string) (InboundAddress, error) {
+	row := q.db.QueryRowContext(ctx, getInboundAddressByToken, token)
+	var i InboundAddress
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Token,
+	)
+	return i, err
+}
+
+const getInboundAddressesForUser = `-- name: GetInboundAddressesForUser :many
+SELECT id, created_at, updated_at, user_id, feed_id, token FROM inbound_addresses
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetInboundAddressesForUser(ctx context.Context, userID uuid.UUID) ([]InboundAddress, error) {
+	rows, err := q.db.QueryContext(ctx, getInboundAddressesForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InboundAddress
+	for rows.Next() {
+		var i InboundAddress
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Token,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,299 @@
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addPostLabel = `-- name: AddPostLabel :exec
+INSERT INTO post_labels (label_id, post_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (label_id, post_id) DO NOTHING
+`
+
+type AddPostLabelParams struct {
+	LabelID   uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) AddPostLabel(ctx context.Context, arg AddPostLabelParams) error {
+	_, err := q.db.ExecContext(ctx, addPostLabel, arg.LabelID, arg.PostID, arg.CreatedAt)
+	return err
+}
+
+const copyPostLabels = `-- name: CopyPostLabels :exec
+INSERT INTO post_labels (label_id, post_id, created_at)
+SELECT $1::UUID, post_labels.post_id, post_labels.created_at
+FROM post_labels
+WHERE post_labels.label_id = $2
+ON CONFLICT (label_id, post_id) DO NOTHING
+`
+
+type CopyPostLabelsParams struct {
+	ToLabelID   uuid.UUID
+	FromLabelID uuid.UUID
+}
+
+func (q *Queries) CopyPostLabels(ctx context.Context, arg CopyPostLabelsParams) error {
+	_, err := q.db.ExecContext(ctx, copyPostLabels, arg.ToLabelID, arg.FromLabelID)
+	return err
+}
+
+const createLabel = `-- name: CreateLabel :one
+INSERT INTO labels (id, created_at, updated_at, user_id, name, color)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, user_id, name, color
+`
+
+type CreateLabelParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+	Color     string
+}
+
+func (q *Queries) CreateLabel(ctx context.Context, arg CreateLabelParams) (Label, error) {
+	row := q.db.QueryRowContext(ctx, createLabel,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.Name,
+		arg.Color,
+	)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.Color,
+	)
+	return i, err
+}
+
+const deleteLabel = `-- name: DeleteLabel :exec
+DELETE FROM labels
+WHERE id = $1
+`
+
+func (q *Queries) DeleteLabel(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteLabel, id)
+	return err
+}
+
+const getLabelByName = `-- name: GetLabelByName :one
+SELECT id, created_at, updated_at, user_id, name, color FROM labels
+WHERE user_id = $1 AND lower(name) = lower($2)
+`
+
+type GetLabelByNameParams struct {
+	UserID uuid.UUID
+	Name   string
+}
+
+func (q *Queries) GetLabelByName(ctx context.Context, arg GetLabelByNameParams) (Label, error) {
+	row := q.db.QueryRowContext(ctx, getLabelByName, arg.UserID, arg.Name)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.Color,
+	)
+	return i, err
+}
+
+const getLabelForUser = `-- name: GetLabelForUser :one
+SELECT labels.id, labels.created_at, labels.updated_at, labels.user_id, labels.name, labels.color, labels.post_count, (
+    SELECT COUNT(*) FROM post_labels WHERE post_labels.label_id = labels.id
+) AS post_count
+FROM labels
+WHERE labels.id = $1 AND labels.user_id = $2
+`
+
+type GetLabelForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+type GetLabelForUserRow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+	Color     string
+	PostCount int64
+}
+
+func (q *Queries) GetLabelForUser(ctx context.Context, arg GetLabelForUserParams) (GetLabelForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getLabelForUser, arg.ID, arg.UserID)
+	var i GetLabelForUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.Color,
+		&i.PostCount,
+	)
+	return i, err
+}
+
+const getLabelsForPost = `-- name: GetLabelsForPost :many
+SELECT labels.id, labels.created_at, labels.updated_at, labels.user_id, labels.name, labels.color, labels.post_count, (
+    SELECT COUNT(*) FROM post_labels counted WHERE counted.label_id = labels.id
+) AS post_count
+FROM labels
+JOIN post_labels ON post_labels.label_id = labels.id
+WHERE labels.user_id = $1 AND post_labels.post_id = $2
+ORDER BY lower(labels.name)
+`
+
+type GetLabelsForPostParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+type GetLabelsForPostRow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+	Color     string
+	PostCount int64
+}
+
+func (q *Queries) GetLabelsForPost(ctx context.Context, arg GetLabelsForPostParams) ([]GetLabelsForPostRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLabelsForPost, arg.UserID, arg.PostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLabelsForPostRow
+	for rows.Next() {
+		var i GetLabelsForPostRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+			&i.Color,
+			&i.PostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLabelsForUser = `-- name: GetLabelsForUser :many
+SELECT labels.id, labels.created_at, labels.updated_at, labels.user_id, labels.name, labels.color, labels.post_count, COUNT(post_labels.post_id) AS post_count
+FROM labels
+LEFT JOIN post_labels ON post_labels.label_id = labels.id
+WHERE labels.user_id = $1
+GROUP BY labels.id
+ORDER BY lower(labels.name)
+`
+
+type GetLabelsForUserRow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+	Color     string
+	PostCount int64
+}
+
+func (q *Queries) GetLabelsForUser(ctx context.Context, userID uuid.UUID) ([]GetLabelsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLabelsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLabelsForUserRow
+	for rows.Next() {
+		var i GetLabelsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+			&i.Color,
+			&i.PostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removePostLabel = `-- name: RemovePostLabel :execrows
+DELETE FROM post_labels
+WHERE label_id = $1 AND post_id = $2
+`
+
+type RemovePostLabelParams struct {
+	LabelID uuid.UUID
+	PostID  uuid.UUID
+}
+
+func (q *Queries) RemovePostLabel(ctx context.Context, arg RemovePostLabelParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removePostLabel, arg.LabelID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateLabel = `-- name: UpdateLabel :exec
+UPDATE labels
+SET name = $2, color = $3, updated_at = $4
+WHERE id = $1
+`
+
+type UpdateLabelParams struct {
+	ID        uuid.UUID
+	Name      string
+	Color     string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateLabel(ctx context.Context, arg UpdateLabelParams) error {
+	_, err := q.db.ExecContext(ctx, updateLabel,
+		arg.ID,
+		arg.Name,
+		arg.Color,
+		arg.UpdatedAt,
+	)
+	return err
+}
@@ -3,28 +3,165 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
 )
 
+type Collection struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	Slug        string
+	Name        string
+	Description sql.NullString
+	Kind        string
+	Visibility  string
+}
+
+type CollectionFeed struct {
+	CollectionID uuid.UUID
+	FeedID       uuid.UUID
+	Note         sql.NullString
+	CreatedAt    time.Time
+}
+
+type CollectionPost struct {
+	CollectionID uuid.UUID
+	PostID       uuid.UUID
+	Note         sql.NullString
+	CreatedAt    time.Time
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.UUID
+	LastFetchedAt        sql.NullTime
+	Kind                 string
+	LastFetchSucceededAt sql.NullTime
+	DeletedAt            sql.NullTime
+	SiteUrl              sql.NullString
+}
+
+type FeedBackfill struct {
+	FeedID       uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Status       string
+	NextUrl      sql.NullString
+	PagesFetched int32
+	MaxPages     int32
+	ItemsFound   int32
+	Error        sql.NullString
+}
+
+type FeedCredential struct {
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	AuthType  string
+	Secret    []byte
 }
 
 type FeedFollow struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	UserID           uuid.UUID
+	FeedID           uuid.UUID
+	FolderID         uuid.NullUUID
+	Position         int32
+	Title            sql.NullString
+	HideFromTimeline bool
+	FullContent      bool
+	SortOrder        string
+}
+
+type FeedProcessor struct {
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Config    json.RawMessage
+}
+
+type FeedReadMark struct {
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	ReadUntil time.Time
+	UpdatedAt time.Time
+}
+
+type FeedRetention struct {
+	FeedID     uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	MaxAgeDays sql.NullInt32
+	MaxItems   sql.NullInt32
+}
+
+type FeedSelector struct {
+	FeedID          uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ItemSelector    string
+	TitleSelector   string
+	LinkSelector    string
+	DateSelector    sql.NullString
+	SummarySelector sql.NullString
+}
+
+type FilterRule struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	Enabled    bool
+	MatchAny   bool
+	Conditions json.RawMessage
+	Actions    json.RawMessage
+}
+
+type Folder struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	ParentID  uuid.NullUUID
+	Name      string
+	Position  int32
+}
+
+type HiddenPost struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	RuleID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type InboundAddress struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
+	Token     string
+}
+
+type Label struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+	Color     string
 }
 
 type Notification struct {
@@ -39,6 +176,40 @@ type Notification struct {
 	Metadata    pqtype.NullRawMessage
 }
 
+type OpmlImport struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Status    string
+}
+
+type OpmlImportItem struct {
+	ID        uuid.UUID
+	ImportID  uuid.UUID
+	Position  int32
+	Url       string
+	Title     string
+	Folder    sql.NullString
+	Subfolder sql.NullString
+	Status    string
+	FeedID    uuid.NullUUID
+	Error     sql.NullString
+	UpdatedAt time.Time
+	Starred   bool
+	StarOnly  bool
+}
+
+type PageMonitor struct {
+	FeedID      uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Selector    sql.NullString
+	Content     sql.NullString
+	ContentHash sql.NullString
+	ChangedAt   sql.NullTime
+}
+
 type Post struct {
 	ID          uuid.UUID
 	CreatedAt   time.Time
@@ -48,6 +219,71 @@ type Post struct {
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      uuid.UUID
+	OriginalUrl sql.NullString
+	Author      sql.NullString
+	Categories  []string
+}
+
+type PostCluster struct {
+	PostID        uuid.UUID
+	ClusterID     uuid.UUID
+	CreatedAt     time.Time
+	UrlKey        string
+	TitleShingles []int64
+}
+
+type PostLabel struct {
+	LabelID   uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type PostPrune struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	FeedID       uuid.UUID
+	Reason       string
+	PostsRemoved int32
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type PostReadState struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Read      bool
+	UpdatedAt time.Time
+}
+
+type PublishedFeed struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Token     string
+	Title     string
+	Source    string
+	FolderID  uuid.NullUUID
+	LabelID   uuid.NullUUID
+}
+
+type SavedPost struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	PostID      uuid.NullUUID
+	FeedID      uuid.NullUUID
+	FeedName    string
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	Note        sql.NullString
 }
 
 type StarredFeed struct {
@@ -58,6 +294,16 @@ type StarredFeed struct {
 	FeedID    uuid.UUID
 }
 
+type TrendingPost struct {
+	PostID     uuid.UUID
+	Score      float64
+	Reads      int32
+	Saves      int32
+	Stars      int32
+	Coverage   int32
+	ComputedAt time.Time
+}
+
 type User struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
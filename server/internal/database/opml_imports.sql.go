@@ -0,0 +1,253 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createOPMLImport = `-- name: CreateOPMLImport :one
+INSERT INTO opml_imports (id, created_at, updated_at, user_id, status)
+VALUES ($1, $2, $3, $4, 'pending')
+RETURNING id, created_at, updated_at, user_id, status
+`
+
+type CreateOPMLImportParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+}
+
+func (q *Queries) CreateOPMLImport(ctx context.Context, arg CreateOPMLImportParams) (OpmlImport, error) {
+	row := q.db.QueryRowContext(ctx, createOPMLImport,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+	)
+	var i OpmlImport
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Status,
+	)
+	return i, err
+}
+
+const createOPMLImportItem = `-- name: CreateOPMLImportItem :exec
+INSERT INTO opml_import_items (id, import_id, position, url, title, folder, subfolder, starred, star_only, status, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'pending', $10)
+`
+
+type CreateOPMLImportItemParams struct {
+	ID        uuid.UUID
+	ImportID  uuid.UUID
+	Position  int32
+	Url       string
+	Title     string
+	Folder    sql.NullString
+	Subfolder sql.NullString
+	Starred   bool
+	StarOnly  bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) CreateOPMLImportItem(ctx context.Context, arg CreateOPMLImportItemParams) error {
+	_, err := q.db.ExecContext(ctx, createOPMLImportItem,
+		arg.ID,
+		arg.ImportID,
+		arg.Position,
+		arg.Url,
+		arg.Title,
+		arg.Folder,
+		arg.Subfolder,
+		arg.Starred,
+		arg.StarOnly,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const finishOPMLImportItem = `-- name: FinishOPMLImportItem :exec
+UPDATE opml_import_items
+SET status = $2, feed_id = $3, error = $4, updated_at = $5
+WHERE id = $1
+`
+
+type FinishOPMLImportItemParams struct {
+	ID        uuid.UUID
+	Status    string
+	FeedID    uuid.NullUUID
+	Error     sql.NullString
+	UpdatedAt time.Time
+}
+
+func (q *Queries) FinishOPMLImportItem(ctx context.Context, arg FinishOPMLImportItemParams) error {
+	_, err := q.db.ExecContext(ctx, finishOPMLImportItem,
+		arg.ID,
+		arg.Status,
+		arg.FeedID,
+		arg.Error,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const finishOPMLImports = `-- name: FinishOPMLImports :exec
+UPDATE opml_imports
+SET status = 'done', updated_at = $1
+WHERE status <> 'done'
+AND NOT EXISTS (
+    SELECT 1 FROM opml_import_items
+    WHERE opml_import_items.import_id = opml_imports.id AND opml_import_items.status = 'pending'
+)
+`
+
+func (q *Queries) FinishOPMLImports(ctx context.Context, updatedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, finishOPMLImports, updatedAt)
+	return err
+}
+
+const getOPMLImportForUser = `-- name: GetOPMLImportForUser :one
+SELECT id, created_at, updated_at, user_id, status FROM opml_imports
+WHERE id = $1 AND user_id = $2
+`
+
+type GetOPMLImportForUserParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetOPMLImportForUser(ctx context.Context, arg GetOPMLImportForUserParams) (OpmlImport, error) {
+	row := q.db.QueryRowContext(ctx, getOPMLImportForUser, arg.ID, arg.UserID)
+	var i OpmlImport
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getOPMLImportItems = `-- name: GetOPMLImportItems :many
+SELECT id, import_id, position, url, title, folder, subfolder, status, feed_id, error, updated_at, starred, star_only FROM opml_import_items
+WHERE import_id = $1
+ORDER BY position
+`
+
+func (q *Queries) GetOPMLImportItems(ctx context.Context, importID uuid.UUID) ([]OpmlImportItem, error) {
+	rows, err := q.db.QueryContext(ctx, getOPMLImportItems, importID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OpmlImportItem
+	for rows.Next() {
+		var i OpmlImportItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImportID,
+			&i.Position,
+			&i.Url,
+			&i.Title,
+			&i.Folder,
+			&i.Subfolder,
+			&i.Status,
+			&i.FeedID,
+			&i.Error,
+			&i.UpdatedAt,
+			&i.Starred,
+			&i.StarOnly,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingOPMLImportItems = `-- name: GetPendingOPMLImportItems :many
+SELECT opml_import_items.id, opml_import_items.import_id, opml_import_items.url, opml_import_items.title,
+    opml_import_items.folder, opml_import_items.subfolder, opml_import_items.starred, opml_import_items.star_only, opml_imports.user_id
+FROM opml_import_items
+JOIN opml_imports ON opml_imports.id = opml_import_items.import_id
+WHERE opml_import_items.status = 'pending'
+ORDER BY ROW_NUMBER() OVER (PARTITION BY opml_import_items.import_id ORDER BY opml_import_items.position),
+    opml_imports.created_at
+LIMIT $1
+`
+
+type GetPendingOPMLImportItemsRow struct {
+	ID        uuid.UUID
+	ImportID  uuid.UUID
+	Url       string
+	Title     string
+	Folder    sql.NullString
+	Subfolder sql.NullString
+	Starred   bool
+	StarOnly  bool
+	UserID    uuid.UUID
+}
+
+func (q *Queries) GetPendingOPMLImportItems(ctx context.Context, limit int32) ([]GetPendingOPMLImportItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingOPMLImportItems, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPendingOPMLImportItemsRow
+	for rows.Next() {
+		var i GetPendingOPMLImportItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ImportID,
+			&i.Url,
+			&i.Title,
+			&i.Folder,
+			&i.Subfolder,
+			&i.Starred,
+			&i.StarOnly,
+			&i.UserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOPMLImportRunning = `-- name: MarkOPMLImportRunning :exec
+UPDATE opml_imports
+SET status = 'running', updated_at = $2
+WHERE id = $1 AND status = 'pending'
+`
+
+type MarkOPMLImportRunningParams struct {
+	ID        uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) MarkOPMLImportRunning(ctx context.Context, arg MarkOPMLImportRunningParams) error {
+	_, err := q.db.ExecContext(ctx, markOPMLImportRunning, arg.ID, arg.UpdatedAt)
+	return err
+}
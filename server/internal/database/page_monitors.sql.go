@@ -0,0 +1,89 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createPageMonitor = `-- name: CreatePageMonitor :one
+INSERT INTO page_monitors (feed_id, created_at, updated_at, selector)
+VALUES ($1, $2, $3, $4)
+RETURNING feed_id, created_at, updated_at, selector, content, content_hash, changed_at
+`
+
+type CreatePageMonitorParams struct {
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Selector  sql.NullString
+}
+
+func (q *Queries) CreatePageMonitor(ctx context.Context, arg CreatePageMonitorParams) (PageMonitor, error) {
+	row := q.db.QueryRowContext(ctx, createPageMonitor,
+		arg.FeedID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Selector,
+	)
+	var i PageMonitor
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Selector,
+		&i.Content,
+		&i.ContentHash,
+		&i.ChangedAt,
+	)
+	return i, err
+}
+
+const getPageMonitor = `-- name: GetPageMonitor :one
+SELECT feed_id, created_at, updated_at, selector, content, content_hash, changed_at FROM page_monitors
+WHERE feed_id = $1
+`
+
+func (q *Queries) GetPageMonitor(ctx context.Context, feedID uuid.UUID) (PageMonitor, error) {
+	row := q.db.QueryRowContext(ctx, getPageMonitor, feedID)
+	var i PageMonitor
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Selector,
+		&i.Content,
+		&i.ContentHash,
+		&i.ChangedAt,
+	)
+	return i, err
+}
+
+const updatePageMonitorContent = `-- name: UpdatePageMonitorContent :exec
+UPDATE page_monitors
+SET content = $2,
+content_hash = $3,
+changed_at = $4,
+updated_at = NOW()
+WHERE feed_id = $1
+`
+
+type UpdatePageMonitorContentParams struct {
+	FeedID      uuid.UUID
+	Content     sql.NullString
+	ContentHash sql.NullString
+	ChangedAt   sql.NullTime
+}
+
+func (q *Queries) UpdatePageMonitorContent(ctx context.Context, arg UpdatePageMonitorContentParams) error {
+	_, err := q.db.ExecContext(ctx, updatePageMonitorContent,
+		arg.FeedID,
+		arg.Content,
+		arg.ContentHash,
+		arg.ChangedAt,
+	)
+	return err
+}
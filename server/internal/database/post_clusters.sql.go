@@ -0,0 +1,249 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createPostCluster = `-- name: CreatePostCluster :exec
+INSERT INTO post_clusters (post_id, cluster_id, created_at, url_key, title_shingles)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (post_id) DO NOTHING
+`
+
+type CreatePostClusterParams struct {
+	PostID        uuid.UUID
+	ClusterID     uuid.UUID
+	CreatedAt     time.Time
+	UrlKey        string
+	TitleShingles []int64
+}
+
+func (q *Queries) CreatePostCluster(ctx context.Context, arg CreatePostClusterParams) error {
+	_, err := q.db.ExecContext(ctx, createPostCluster,
+		arg.PostID,
+		arg.ClusterID,
+		arg.CreatedAt,
+		arg.UrlKey,
+		pq.Array(arg.TitleShingles),
+	)
+	return err
+}
+
+const getClusterCandidates = `-- name: GetClusterCandidates :many
+SELECT post_clusters.post_id, post_clusters.cluster_id, post_clusters.url_key, post_clusters.title_shingles
+FROM post_clusters
+JOIN posts ON posts.id = post_clusters.post_id
+WHERE posts.feed_id <> $1
+AND COALESCE(posts.published_at, posts.created_at) BETWEEN $2 AND $3
+AND (
+    post_clusters.url_key = $4
+    OR post_clusters.title_shingles && $5::BIGINT[]
+)
+ORDER BY post_clusters.created_at DESC
+LIMIT 200
+`
+
+type GetClusterCandidatesParams struct {
+	FeedID        uuid.UUID
+	WindowStart   time.Time
+	WindowEnd     time.Time
+	UrlKey        string
+	TitleShingles []int64
+}
+
+type GetClusterCandidatesRow struct {
+	PostID        uuid.UUID
+	ClusterID     uuid.UUID
+	UrlKey        string
+	TitleShingles []int64
+}
+
+func (q *Queries) GetClusterCandidates(ctx context.Context, arg GetClusterCandidatesParams) ([]GetClusterCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getClusterCandidates,
+		arg.FeedID,
+		arg.WindowStart,
+		arg.WindowEnd,
+		arg.UrlKey,
+		pq.Array(arg.TitleShingles),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetClusterCandidatesRow
+	for rows.Next() {
+		var i GetClusterCandidatesRow
+		if err := rows.Scan(
+			&i.PostID,
+			&i.ClusterID,
+			&i.UrlKey,
+			pq.Array(&i.TitleShingles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClusterMembersVisibleToUser = `-- name: GetClusterMembersVisibleToUser :many
+SELECT post_clusters.cluster_id, posts.id AS post_id, posts.url, feeds.id AS feed_id, feeds.name AS feed_name
+FROM post_clusters
+JOIN posts ON posts.id = post_clusters.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE post_clusters.cluster_id = ANY($1::UUID[])
+AND feeds.deleted_at IS NULL
+AND (
+    feeds.user_id = $2
+    OR (
+        feeds.kind <> 'newsletter'
+        AND NOT EXISTS (
+            SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+        )
+    )
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at) ASC
+`
+
+type GetClusterMembersVisibleToUserParams struct {
+	ClusterIds []uuid.UUID
+	UserID     uuid.UUID
+}
+
+type GetClusterMembersVisibleToUserRow struct {
+	ClusterID uuid.UUID
+	PostID    uuid.UUID
+	Url       string
+	FeedID    uuid.UUID
+	FeedName  string
+}
+
+func (q *Queries) GetClusterMembersVisibleToUser(ctx context.Context, arg GetClusterMembersVisibleToUserParams) ([]GetClusterMembersVisibleToUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getClusterMembersVisibleToUser, pq.Array(arg.ClusterIds), arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetClusterMembersVisibleToUserRow
+	for rows.Next() {
+		var i GetClusterMembersVisibleToUserRow
+		if err := rows.Scan(
+			&i.ClusterID,
+			&i.PostID,
+			&i.Url,
+			&i.FeedID,
+			&i.FeedName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostClusters = `-- name: GetPostClusters :many
+SELECT post_id, cluster_id FROM post_clusters
+WHERE post_id = ANY($1::UUID[])
+`
+
+type GetPostClustersRow struct {
+	PostID    uuid.UUID
+	ClusterID uuid.UUID
+}
+
+func (q *Queries) GetPostClusters(ctx context.Context, postIds []uuid.UUID) ([]GetPostClustersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostClusters, pq.Array(postIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostClustersRow
+	for rows.Next() {
+		var i GetPostClustersRow
+		if err := rows.Scan(
+			&i.PostID,
+			&i.ClusterID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnclusteredPosts = `-- name: GetUnclusteredPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories FROM posts
+LEFT JOIN post_clusters ON post_clusters.post_id = posts.id
+WHERE post_clusters.post_id IS NULL
+AND (
+    $2::TIMESTAMP IS NULL
+    OR (posts.created_at, posts.id) > ($2::TIMESTAMP, $3::UUID)
+)
+ORDER BY posts.created_at ASC, posts.id ASC
+LIMIT $1
+`
+
+type GetUnclusteredPostsParams struct {
+	Limit     int32
+	AfterTime sql.NullTime
+	AfterID   uuid.NullUUID
+}
+
+func (q *Queries) GetUnclusteredPosts(ctx context.Context, arg GetUnclusteredPostsParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getUnclusteredPosts, arg.Limit, arg.AfterTime, arg.AfterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
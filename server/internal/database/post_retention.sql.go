@@ -0,0 +1,224 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createPostPrune = `-- name: CreatePostPrune :exec
+INSERT INTO post_prunes (id, created_at, feed_id, reason, posts_removed)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreatePostPruneParams struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	FeedID       uuid.UUID
+	Reason       string
+	PostsRemoved int32
+}
+
+func (q *Queries) CreatePostPrune(ctx context.Context, arg CreatePostPruneParams) error {
+	_, err := q.db.ExecContext(ctx, createPostPrune,
+		arg.ID,
+		arg.CreatedAt,
+		arg.FeedID,
+		arg.Reason,
+		arg.PostsRemoved,
+	)
+	return err
+}
+
+const getFeedRetention = `-- name: GetFeedRetention :one
+SELECT feed_id, created_at, updated_at, max_age_days, max_items FROM feed_retention
+WHERE feed_id = $1
+`
+
+func (q *Queries) GetFeedRetention(ctx context.Context, feedID uuid.UUID) (FeedRetention, error) {
+	row := q.db.QueryRowContext(ctx, getFeedRetention, feedID)
+	var i FeedRetention
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MaxAgeDays,
+		&i.MaxItems,
+	)
+	return i, err
+}
+
+const getFeedRetentionPolicies = `-- name: GetFeedRetentionPolicies :many
+SELECT feeds.id, feeds.name, feed_retention.max_age_days, feed_retention.max_items
+FROM feeds
+LEFT JOIN feed_retention ON feed_retention.feed_id = feeds.id
+ORDER BY feeds.created_at
+`
+
+type GetFeedRetentionPoliciesRow struct {
+	ID         uuid.UUID
+	Name       string
+	MaxAgeDays sql.NullInt32
+	MaxItems   sql.NullInt32
+}
+
+func (q *Queries) GetFeedRetentionPolicies(ctx context.Context) ([]GetFeedRetentionPoliciesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedRetentionPolicies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedRetentionPoliciesRow
+	for rows.Next() {
+		var i GetFeedRetentionPoliciesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.MaxAgeDays,
+			&i.MaxItems,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentPostPrunesForFeed = `-- name: GetRecentPostPrunesForFeed :many
+SELECT id, created_at, feed_id, reason, posts_removed FROM post_prunes
+WHERE feed_id = $1
+ORDER BY created_at DESC
+LIMIT 20
+`
+
+func (q *Queries) GetRecentPostPrunesForFeed(ctx context.Context, feedID uuid.UUID) ([]PostPrune, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPostPrunesForFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostPrune
+	for rows.Next() {
+		var i PostPrune
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.FeedID,
+			&i.Reason,
+			&i.PostsRemoved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pruneFeedPostsBeyondCount = `-- name: PruneFeedPostsBeyondCount :execrows
+DELETE FROM posts
+WHERE id IN (
+    SELECT p.id FROM posts p
+    WHERE p.feed_id = $1
+    AND NOT EXISTS (SELECT 1 FROM starred_feeds sf WHERE sf.feed_id = p.feed_id)
+    AND NOT EXISTS (SELECT 1 FROM saved_posts sp WHERE sp.post_id = p.id)
+    AND NOT EXISTS (SELECT 1 FROM post_labels pl WHERE pl.post_id = p.id)
+    AND NOT EXISTS (SELECT 1 FROM collection_posts cp WHERE cp.post_id = p.id)
+    ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+    OFFSET $2
+    LIMIT $3
+)
+`
+
+type PruneFeedPostsBeyondCountParams struct {
+	FeedID    uuid.UUID
+	Keep      int32
+	BatchSize int32
+}
+
+func (q *Queries) PruneFeedPostsBeyondCount(ctx context.Context, arg PruneFeedPostsBeyondCountParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneFeedPostsBeyondCount, arg.FeedID, arg.Keep, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const pruneFeedPostsOlderThan = `-- name: PruneFeedPostsOlderThan :execrows
+DELETE FROM posts
+WHERE id IN (
+    SELECT p.id FROM posts p
+    WHERE p.feed_id = $1
+    AND COALESCE(p.published_at, p.created_at) < $2
+    AND NOT EXISTS (SELECT 1 FROM starred_feeds sf WHERE sf.feed_id = p.feed_id)
+    AND NOT EXISTS (SELECT 1 FROM saved_posts sp WHERE sp.post_id = p.id)
+    AND NOT EXISTS (SELECT 1 FROM post_labels pl WHERE pl.post_id = p.id)
+    AND NOT EXISTS (SELECT 1 FROM collection_posts cp WHERE cp.post_id = p.id)
+    LIMIT $3
+)
+`
+
+type PruneFeedPostsOlderThanParams struct {
+	FeedID    uuid.UUID
+	Cutoff    time.Time
+	BatchSize int32
+}
+
+func (q *Queries) PruneFeedPostsOlderThan(ctx context.Context, arg PruneFeedPostsOlderThanParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, pruneFeedPostsOlderThan, arg.FeedID, arg.Cutoff, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertFeedRetention = `-- name: UpsertFeedRetention :one
+INSERT INTO feed_retention (feed_id, created_at, updated_at, max_age_days, max_items)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+max_age_days = EXCLUDED.max_age_days,
+max_items = EXCLUDED.max_items
+RETURNING feed_id, created_at, updated_at, max_age_days, max_items
+`
+
+type UpsertFeedRetentionParams struct {
+	FeedID     uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	MaxAgeDays sql.NullInt32
+	MaxItems   sql.NullInt32
+}
+
+func (q *Queries) UpsertFeedRetention(ctx context.Context, arg UpsertFeedRetentionParams) (FeedRetention, error) {
+	row := q.db.QueryRowContext(ctx, upsertFeedRetention,
+		arg.FeedID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.MaxAgeDays,
+		arg.MaxItems,
+	)
+	var i FeedRetention
+	err := row.Scan(
+		&i.FeedID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MaxAgeDays,
+		&i.MaxItems,
+	)
+	return i, err
+}
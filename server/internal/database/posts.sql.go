@@ -8,12 +8,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, original_url, author, categories)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, original_url, author, categories
 `
 
 type CreatePostParams struct {
@@ -25,6 +26,9 @@ type CreatePostParams struct {
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      uuid.UUID
+	OriginalUrl sql.NullString
+	Author      sql.NullString
+	Categories  []string
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -37,6 +41,9 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.OriginalUrl,
+		arg.Author,
+		pq.Array(arg.Categories),
 	)
 	var i Post
 	err := row.Scan(
@@ -48,25 +55,196 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.OriginalUrl,
+		&i.Author,
+		pq.Array(&i.Categories),
+	)
+	return i, err
+}
+
+const getPost = `-- name: GetPost :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, original_url, author, categories FROM posts
+WHERE id = $1
+`
+
+func (q *Queries) GetPost(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.OriginalUrl,
+		&i.Author,
+		pq.Array(&i.Categories),
+	)
+	return i, err
+}
+
+const getPostNeighbours = `-- name: GetPostNeighbours :one
+SELECT
+    previous_in_feed.id AS previous_in_feed,
+    next_in_feed.id AS next_in_feed,
+    previous_in_timeline.id AS previous_in_timeline,
+    next_in_timeline.id AS next_in_timeline
+FROM posts post
+LEFT JOIN LATERAL (
+    SELECT newer.id FROM posts newer
+    WHERE newer.feed_id = post.feed_id
+    AND (COALESCE(newer.published_at, newer.created_at), newer.id) > (COALESCE(post.published_at, post.created_at), post.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = newer.id
+    )
+    ORDER BY COALESCE(newer.published_at, newer.created_at), newer.id
+    LIMIT 1
+) previous_in_feed ON TRUE
+LEFT JOIN LATERAL (
+    SELECT older.id FROM posts older
+    WHERE older.feed_id = post.feed_id
+    AND (COALESCE(older.published_at, older.created_at), older.id) < (COALESCE(post.published_at, post.created_at), post.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = older.id
+    )
+    ORDER BY COALESCE(older.published_at, older.created_at) DESC, older.id DESC
+    LIMIT 1
+) next_in_feed ON TRUE
+LEFT JOIN LATERAL (
+    SELECT newer.id FROM posts newer
+    JOIN feed_follows ON feed_follows.feed_id = newer.feed_id
+    WHERE feed_follows.user_id = $1
+    AND NOT feed_follows.hide_from_timeline
+    AND EXISTS (
+        SELECT 1 FROM feed_follows own
+        WHERE own.user_id = $1 AND own.feed_id = post.feed_id
+    )
+    AND (COALESCE(newer.published_at, newer.created_at), newer.id) > (COALESCE(post.published_at, post.created_at), post.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = newer.id
+    )
+    ORDER BY COALESCE(newer.published_at, newer.created_at), newer.id
+    LIMIT 1
+) previous_in_timeline ON TRUE
+LEFT JOIN LATERAL (
+    SELECT older.id FROM posts older
+    JOIN feed_follows ON feed_follows.feed_id = older.feed_id
+    WHERE feed_follows.user_id = $1
+    AND NOT feed_follows.hide_from_timeline
+    AND EXISTS (
+        SELECT 1 FROM feed_follows own
+        WHERE own.user_id = $1 AND own.feed_id = post.feed_id
+    )
+    AND (COALESCE(older.published_at, older.created_at), older.id) < (COALESCE(post.published_at, post.created_at), post.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = older.id
+    )
+    ORDER BY COALESCE(older.published_at, older.created_at) DESC, older.id DESC
+    LIMIT 1
+) next_in_timeline ON TRUE
+WHERE post.id = $2
+`
+
+type GetPostNeighboursParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+type GetPostNeighboursRow struct {
+	PreviousInFeed     uuid.NullUUID
+	NextInFeed         uuid.NullUUID
+	PreviousInTimeline uuid.NullUUID
+	NextInTimeline     uuid.NullUUID
+}
+
+func (q *Queries) GetPostNeighbours(ctx context.Context, arg GetPostNeighboursParams) (GetPostNeighboursRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostNeighbours, arg.UserID, arg.PostID)
+	var i GetPostNeighboursRow
+	err := row.Scan(
+		&i.PreviousInFeed,
+		&i.NextInFeed,
+		&i.PreviousInTimeline,
+		&i.NextInTimeline,
 	)
 	return i, err
 }
 
 const getPostsByFeedID = `-- name: GetPostsByFeedID :many
-SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, original_url, author, categories FROM posts
 WHERE feed_id = $1
-ORDER BY published_at DESC
-LIMIT $2 OFFSET $3
+AND (
+    $3::TIMESTAMP IS NULL
+    OR (
+        NOT $4::BOOLEAN
+        AND (COALESCE(published_at, created_at), id) < ($3::TIMESTAMP, $5::UUID)
+    )
+    OR (
+        $4::BOOLEAN
+        AND (COALESCE(published_at, created_at), id) > ($3::TIMESTAMP, $5::UUID)
+    )
+)
+AND (
+    NOT $6::BOOLEAN
+    OR NOT COALESCE(
+        (
+            SELECT post_read_states.read FROM post_read_states
+            WHERE post_read_states.user_id = $7 AND post_read_states.post_id = posts.id
+        ),
+        COALESCE(posts.published_at, posts.created_at) <= (
+            SELECT feed_read_marks.read_until FROM feed_read_marks
+            WHERE feed_read_marks.user_id = $7 AND feed_read_marks.feed_id = posts.feed_id
+        ),
+        FALSE
+    )
+)
+AND (
+    $8::UUID IS NULL
+    OR EXISTS (
+        SELECT 1 FROM post_labels
+        WHERE post_labels.post_id = posts.id AND post_labels.label_id = $8::UUID
+    )
+)
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $7 AND hidden_posts.post_id = posts.id
+)
+ORDER BY
+    CASE WHEN $4::BOOLEAN THEN COALESCE(published_at, created_at) END,
+    CASE WHEN $4::BOOLEAN THEN id END,
+    COALESCE(published_at, created_at) DESC,
+    id DESC
+LIMIT $2
 `
 
 type GetPostsByFeedIDParams struct {
-	FeedID uuid.UUID
-	Limit  int32
-	Offset int32
+	FeedID      uuid.UUID
+	Limit       int32
+	CursorTime  sql.NullTime
+	OldestFirst bool
+	CursorID    uuid.NullUUID
+	UnreadOnly  bool
+	UserID      uuid.UUID
+	LabelID     uuid.NullUUID
 }
 
 func (q *Queries) GetPostsByFeedID(ctx context.Context, arg GetPostsByFeedIDParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsByFeedID, arg.FeedID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, getPostsByFeedID,
+		arg.FeedID,
+		arg.Limit,
+		arg.CursorTime,
+		arg.OldestFirst,
+		arg.CursorID,
+		arg.UnreadOnly,
+		arg.UserID,
+		arg.LabelID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +261,9 @@ func (q *Queries) GetPostsByFeedID(ctx context.Context, arg GetPostsByFeedIDPara
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
 		); err != nil {
 			return nil, err
 		}
@@ -98,20 +279,140 @@ func (q *Queries) GetPostsByFeedID(ctx context.Context, arg GetPostsByFeedIDPara
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
+AND (
+    $3::UUID IS NULL
+    OR feed_follows.folder_id IN (
+        SELECT folders.id FROM folders
+        WHERE folders.id = $3::UUID OR folders.parent_id = $3::UUID
+    )
+)
+AND (
+    $4::TIMESTAMP IS NULL
+    OR (COALESCE(posts.published_at, posts.created_at), posts.id) < ($4::TIMESTAMP, $5::UUID)
+)
+AND (
+    NOT $6::BOOLEAN
+    OR NOT COALESCE(
+        (
+            SELECT post_read_states.read FROM post_read_states
+            WHERE post_read_states.user_id = $1 AND post_read_states.post_id = posts.id
+        ),
+        COALESCE(posts.published_at, posts.created_at) <= (
+            SELECT feed_read_marks.read_until FROM feed_read_marks
+            WHERE feed_read_marks.user_id = $1 AND feed_read_marks.feed_id = posts.feed_id
+        ),
+        FALSE
+    )
+)
+AND (
+    $7::UUID IS NULL
+    OR EXISTS (
+        SELECT 1 FROM post_labels
+        WHERE post_labels.post_id = posts.id AND post_labels.label_id = $7::UUID
+    )
+)
+AND (
+    NOT feed_follows.hide_from_timeline
+    OR $3::UUID IS NOT NULL
+    OR $7::UUID IS NOT NULL
+)
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
 LIMIT $2
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID     uuid.UUID
+	Limit      int32
+	FolderID   uuid.NullUUID
+	CursorTime sql.NullTime
+	CursorID   uuid.NullUUID
+	UnreadOnly bool
+	LabelID    uuid.NullUUID
 }
 
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.Limit,
+		arg.FolderID,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.UnreadOnly,
+		arg.LabelID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsInStarredFeedsForUser = `-- name: GetPostsInStarredFeedsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories FROM posts
+JOIN starred_feeds ON starred_feeds.feed_id = posts.feed_id
+WHERE starred_feeds.user_id = $1
+AND COALESCE(posts.published_at, posts.created_at) > $3
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+)
+AND (
+    $4::TIMESTAMP IS NULL
+    OR (COALESCE(posts.published_at, posts.created_at), posts.id) < ($4::TIMESTAMP, $5::UUID)
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
+LIMIT $2
+`
+
+type GetPostsInStarredFeedsForUserParams struct {
+	UserID     uuid.UUID
+	Limit      int32
+	Since      time.Time
+	CursorTime sql.NullTime
+	CursorID   uuid.NullUUID
+}
+
+func (q *Queries) GetPostsInStarredFeedsForUser(ctx context.Context, arg GetPostsInStarredFeedsForUserParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsInStarredFeedsForUser,
+		arg.UserID,
+		arg.Limit,
+		arg.Since,
+		arg.CursorTime,
+		arg.CursorID,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -128,6 +429,9 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
 		); err != nil {
 			return nil, err
 		}
@@ -143,7 +447,7 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 }
 
 const getRecentPosts = `-- name: GetRecentPosts :many
-SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, original_url, author, categories FROM posts
 WHERE created_at > $1
 ORDER BY created_at DESC
 `
@@ -166,6 +470,57 @@ func (q *Queries) GetRecentPosts(ctx context.Context, createdAt time.Time) ([]Po
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentPostsForUser = `-- name: GetRecentPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
+LIMIT $2
+`
+
+type GetRecentPostsForUserParams struct {
+	UserID uuid.UUID
+	Limit  int32
+}
+
+func (q *Queries) GetRecentPostsForUser(ctx context.Context, arg GetRecentPostsForUserParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPostsForUser, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
 		); err != nil {
 			return nil, err
 		}
@@ -181,7 +536,7 @@ func (q *Queries) GetRecentPosts(ctx context.Context, createdAt time.Time) ([]Po
 }
 
 const getRecentPostsInStarredFeeds = `-- name: GetRecentPostsInStarredFeeds :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, f.name as feed_name FROM posts p
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.original_url, p.author, p.categories, f.name as feed_name FROM posts p
 JOIN feeds f ON p.feed_id = f.id
 JOIN starred_feeds sf ON f.id = sf.feed_id
 WHERE p.created_at > $1
@@ -197,6 +552,9 @@ type GetRecentPostsInStarredFeedsRow struct {
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      uuid.UUID
+	OriginalUrl sql.NullString
+	Author      sql.NullString
+	Categories  []string
 	FeedName    string
 }
 
@@ -218,6 +576,9 @@ func (q *Queries) GetRecentPostsInStarredFeeds(ctx context.Context, createdAt ti
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
 			&i.FeedName,
 		); err != nil {
 			return nil, err
@@ -232,3 +593,23 @@ func (q *Queries) GetRecentPostsInStarredFeeds(ctx context.Context, createdAt ti
 	}
 	return items, nil
 }
+
+const postExistsForURL = `-- name: PostExistsForURL :one
+SELECT EXISTS (
+    SELECT 1 FROM posts
+    WHERE feed_id = $1
+    AND (original_url = $2 OR url = $2)
+)
+`
+
+type PostExistsForURLParams struct {
+	FeedID uuid.UUID
+	Url    string
+}
+
+func (q *Queries) PostExistsForURL(ctx context.Context, arg PostExistsForURLParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, postExistsForURL, arg.FeedID, arg.Url)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
@@ -6,10 +6,11 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const getPostsByFeed = `-- name: GetPostsByFeed :many
-SELECT id, created_at, updated_at, title, url, description, published_at, feed_id 
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, original_url, author, categories
 FROM posts
 WHERE feed_id = $1
 ORDER BY published_at DESC NULLS LAST, created_at DESC
@@ -34,6 +35,9 @@ func (q *Queries) GetPostsByFeed(ctx context.Context, feedID uuid.UUID) ([]Post,
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
 		); err != nil {
 			return nil, err
 		}
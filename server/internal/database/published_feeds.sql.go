@@ -0,0 +1,133 @@
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createPublishedFeed = `-- name: CreatePublishedFeed :one
+INSERT INTO published_feeds (id, created_at, updated_at, user_id, token, title, source, folder_id, label_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, created_at, updated_at, user_id, token, title, source, folder_id, label_id
+`
+
+type CreatePublishedFeedParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Token     string
+	Title     string
+	Source    string
+	FolderID  uuid.NullUUID
+	LabelID   uuid.NullUUID
+}
+
+func (q *Queries) CreatePublishedFeed(ctx context.Context, arg CreatePublishedFeedParams) (PublishedFeed, error) {
+	row := q.db.QueryRowContext(ctx, createPublishedFeed,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.Token,
+		arg.Title,
+		arg.Source,
+		arg.FolderID,
+		arg.LabelID,
+	)
+	var i PublishedFeed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Token,
+		&i.Title,
+		&i.Source,
+		&i.FolderID,
+		&i.LabelID,
+	)
+	return i, err
+}
+
+const deletePublishedFeed = `-- name: DeletePublishedFeed :execrows
+DELETE FROM published_feeds
+WHERE id = $1 AND user_id = $2
+`
+
+type DeletePublishedFeedParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeletePublishedFeed(ctx context.Context, arg DeletePublishedFeedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePublishedFeed, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPublishedFeedByToken = `-- name: GetPublishedFeedByToken :one
+SELECT id, created_at, updated_at, user_id, token, title, source, folder_id, label_id FROM published_feeds
+WHERE token = $1
+`
+
+func (q *Queries) GetPublishedFeedByToken(ctx context.Context, token string) (PublishedFeed, error) {
+	row := q.db.QueryRowContext(ctx, getPublishedFeedByToken, token)
+	var i PublishedFeed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Token,
+		&i.Title,
+		&i.Source,
+		&i.FolderID,
+		&i.LabelID,
+	)
+	return i, err
+}
+
+const getPublishedFeedsForUser = `-- name: GetPublishedFeedsForUser :many
+SELECT id, created_at, updated_at, user_id, token, title, source, folder_id, label_id FROM published_feeds
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetPublishedFeedsForUser(ctx context.Context, userID uuid.UUID) ([]PublishedFeed, error) {
+	rows, err := q.db.QueryContext(ctx, getPublishedFeedsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PublishedFeed
+	for rows.Next() {
+		var i PublishedFeed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Token,
+			&i.Title,
+			&i.Source,
+			&i.FolderID,
+			&i.LabelID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
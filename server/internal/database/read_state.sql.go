@@ -0,0 +1,216 @@
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const clearPostReadStatesUntil = `-- name: ClearPostReadStatesUntil :exec
+DELETE FROM post_read_states
+USING posts, feed_read_marks
+WHERE post_read_states.post_id = posts.id
+AND feed_read_marks.user_id = post_read_states.user_id
+AND feed_read_marks.feed_id = posts.feed_id
+AND post_read_states.user_id = $1
+AND COALESCE(posts.published_at, posts.created_at) <= $2::TIMESTAMP
+AND ($3::UUID IS NULL OR posts.feed_id = $3::UUID)
+`
+
+type ClearPostReadStatesUntilParams struct {
+	UserID    uuid.UUID
+	ReadUntil time.Time
+	FeedID    uuid.NullUUID
+}
+
+func (q *Queries) ClearPostReadStatesUntil(ctx context.Context, arg ClearPostReadStatesUntilParams) error {
+	_, err := q.db.ExecContext(ctx, clearPostReadStatesUntil, arg.UserID, arg.ReadUntil, arg.FeedID)
+	return err
+}
+
+const getPostReadForUser = `-- name: GetPostReadForUser :one
+SELECT COALESCE(
+    (
+        SELECT post_read_states.read FROM post_read_states
+        WHERE post_read_states.user_id = $1 AND post_read_states.post_id = posts.id
+    ),
+    COALESCE(posts.published_at, posts.created_at) <= (
+        SELECT feed_read_marks.read_until FROM feed_read_marks
+        WHERE feed_read_marks.user_id = $1 AND feed_read_marks.feed_id = posts.feed_id
+    ),
+    FALSE
+)::BOOLEAN AS read
+FROM posts
+WHERE posts.id = $2
+`
+
+type GetPostReadForUserParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) GetPostReadForUser(ctx context.Context, arg GetPostReadForUserParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, getPostReadForUser, arg.UserID, arg.PostID)
+	var read bool
+	err := row.Scan(&read)
+	return read, err
+}
+
+const getUnreadCountsForUser = `-- name: GetUnreadCountsForUser :many
+SELECT feed_follows.feed_id, feed_follows.folder_id, feed_follows.hide_from_timeline, COUNT(posts.id) FILTER (
+    WHERE NOT COALESCE(
+        post_read_states.read,
+        COALESCE(posts.published_at, posts.created_at) <= feed_read_marks.read_until,
+        FALSE
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = feed_follows.user_id AND hidden_posts.post_id = posts.id
+    )
+) AS unread
+FROM feed_follows
+LEFT JOIN posts ON posts.feed_id = feed_follows.feed_id
+LEFT JOIN feed_read_marks ON feed_read_marks.user_id = feed_follows.user_id AND feed_read_marks.feed_id = feed_follows.feed_id
+LEFT JOIN post_read_states ON post_read_states.user_id = feed_follows.user_id AND post_read_states.post_id = posts.id
+WHERE feed_follows.user_id = $1
+GROUP BY feed_follows.feed_id, feed_follows.folder_id, feed_follows.hide_from_timeline
+`
+
+type GetUnreadCountsForUserRow struct {
+	FeedID           uuid.UUID
+	FolderID         uuid.NullUUID
+	HideFromTimeline bool
+	Unread           int64
+}
+
+func (q *Queries) GetUnreadCountsForUser(ctx context.Context, userID uuid.UUID) ([]GetUnreadCountsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadCountsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnreadCountsForUserRow
+	for rows.Next() {
+		var i GetUnreadCountsForUserRow
+		if err := rows.Scan(
+			&i.FeedID,
+			&i.FolderID,
+			&i.HideFromTimeline,
+			&i.Unread,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markFeedReadUntil = `-- name: MarkFeedReadUntil :exec
+INSERT INTO feed_read_marks (user_id, feed_id, read_until, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, feed_id) DO UPDATE
+SET read_until = GREATEST(feed_read_marks.read_until, EXCLUDED.read_until), updated_at = EXCLUDED.updated_at
+`
+
+type MarkFeedReadUntilParams struct {
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	ReadUntil time.Time
+	UpdatedAt time.Time
+}
+
+func (q *Queries) MarkFeedReadUntil(ctx context.Context, arg MarkFeedReadUntilParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedReadUntil,
+		arg.UserID,
+		arg.FeedID,
+		arg.ReadUntil,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const markFollowedFeedsReadUntil = `-- name: MarkFollowedFeedsReadUntil :exec
+INSERT INTO feed_read_marks (user_id, feed_id, read_until, updated_at)
+SELECT feed_follows.user_id, feed_follows.feed_id, $1::TIMESTAMP, $2::TIMESTAMP
+FROM feed_follows
+WHERE feed_follows.user_id = $3
+ON CONFLICT (user_id, feed_id) DO UPDATE
+SET read_until = GREATEST(feed_read_marks.read_until, EXCLUDED.read_until), updated_at = EXCLUDED.updated_at
+`
+
+type MarkFollowedFeedsReadUntilParams struct {
+	ReadUntil time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+}
+
+func (q *Queries) MarkFollowedFeedsReadUntil(ctx context.Context, arg MarkFollowedFeedsReadUntilParams) error {
+	_, err := q.db.ExecContext(ctx, markFollowedFeedsReadUntil, arg.ReadUntil, arg.UpdatedAt, arg.UserID)
+	return err
+}
+
+const markPostReadIfUnset = `-- name: MarkPostReadIfUnset :exec
+INSERT INTO post_read_states (user_id, post_id, read, updated_at)
+VALUES ($1, $2, TRUE, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING
+`
+
+type MarkPostReadIfUnsetParams struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	UpdatedAt time.Time
+}
+
+func (q *Queries) MarkPostReadIfUnset(ctx context.Context, arg MarkPostReadIfUnsetParams) error {
+	_, err := q.db.ExecContext(ctx, markPostReadIfUnset, arg.UserID, arg.PostID, arg.UpdatedAt)
+	return err
+}
+
+const recordPostRead = `-- name: RecordPostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING
+`
+
+type RecordPostReadParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+func (q *Queries) RecordPostRead(ctx context.Context, arg RecordPostReadParams) error {
+	_, err := q.db.ExecContext(ctx, recordPostRead, arg.UserID, arg.PostID, arg.ReadAt)
+	return err
+}
+
+const setPostReadState = `-- name: SetPostReadState :exec
+INSERT INTO post_read_states (user_id, post_id, read, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = EXCLUDED.read, updated_at = EXCLUDED.updated_at
+`
+
+type SetPostReadStateParams struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	Read      bool
+	UpdatedAt time.Time
+}
+
+func (q *Queries) SetPostReadState(ctx context.Context, arg SetPostReadStateParams) error {
+	_, err := q.db.ExecContext(ctx, setPostReadState,
+		arg.UserID,
+		arg.PostID,
+		arg.Read,
+		arg.UpdatedAt,
+	)
+	return err
+}
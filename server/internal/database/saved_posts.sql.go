@@ -0,0 +1,216 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteSavedPost = `-- name: DeleteSavedPost :execrows
+DELETE FROM saved_posts
+WHERE user_id = $1 AND post_id = $2
+`
+
+type DeleteSavedPostParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) DeleteSavedPost(ctx context.Context, arg DeleteSavedPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSavedPost, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSavedPostByID = `-- name: DeleteSavedPostByID :execrows
+DELETE FROM saved_posts
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteSavedPostByIDParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteSavedPostByID(ctx context.Context, arg DeleteSavedPostByIDParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSavedPostByID, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getSavedPostForUser = `-- name: GetSavedPostForUser :one
+SELECT id, created_at, updated_at, user_id, post_id, feed_id, feed_name, title, url, description, published_at, note FROM saved_posts
+WHERE user_id = $1 AND post_id = $2
+`
+
+type GetSavedPostForUserParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) GetSavedPostForUser(ctx context.Context, arg GetSavedPostForUserParams) (SavedPost, error) {
+	row := q.db.QueryRowContext(ctx, getSavedPostForUser, arg.UserID, arg.PostID)
+	var i SavedPost
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.PostID,
+		&i.FeedID,
+		&i.FeedName,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.Note,
+	)
+	return i, err
+}
+
+const getSavedPostsForUser = `-- name: GetSavedPostsForUser :many
+SELECT id, created_at, updated_at, user_id, post_id, feed_id, feed_name, title, url, description, published_at, note FROM saved_posts
+WHERE user_id = $1
+AND (
+    $3::TEXT IS NULL
+    OR to_tsvector('english', title || ' ' || COALESCE(description, '') || ' ' || COALESCE(note, ''))
+        @@ to_tsquery('english', $3::TEXT)
+)
+AND (
+    $4::TIMESTAMP IS NULL
+    OR (created_at, id) < ($4::TIMESTAMP, $5::UUID)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+`
+
+type GetSavedPostsForUserParams struct {
+	UserID     uuid.UUID
+	Limit      int32
+	Query      sql.NullString
+	CursorTime sql.NullTime
+	CursorID   uuid.NullUUID
+}
+
+func (q *Queries) GetSavedPostsForUser(ctx context.Context, arg GetSavedPostsForUserParams) ([]SavedPost, error) {
+	rows, err := q.db.QueryContext(ctx, getSavedPostsForUser,
+		arg.UserID,
+		arg.Limit,
+		arg.Query,
+		arg.CursorTime,
+		arg.CursorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SavedPost
+	for rows.Next() {
+		var i SavedPost
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.PostID,
+			&i.FeedID,
+			&i.FeedName,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const savePost = `-- name: SavePost :one
+INSERT INTO saved_posts (id, created_at, updated_at, user_id, post_id, feed_id, feed_name, title, url, description, published_at, note)
+SELECT $1, $2, $2, $3, posts.id, posts.feed_id, feeds.name, posts.title, posts.url, posts.description, posts.published_at, $4
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.id = $5
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    note = CASE WHEN $6::BOOLEAN THEN saved_posts.note ELSE EXCLUDED.note END
+RETURNING id, created_at, updated_at, user_id, post_id, feed_id, feed_name, title, url, description, published_at, note
+`
+
+type SavePostParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Note      sql.NullString
+	PostID    uuid.UUID
+	KeepNote  bool
+}
+
+func (q *Queries) SavePost(ctx context.Context, arg SavePostParams) (SavedPost, error) {
+	row := q.db.QueryRowContext(ctx, savePost,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UserID,
+		arg.Note,
+		arg.PostID,
+		arg.KeepNote,
+	)
+	var i SavedPost
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.PostID,
+		&i.FeedID,
+		&i.FeedName,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.Note,
+	)
+	return i, err
+}
+
+const savePostIfUnsaved = `-- name: SavePostIfUnsaved :exec
+INSERT INTO saved_posts (id, created_at, updated_at, user_id, post_id, feed_id, feed_name, title, url, description, published_at)
+SELECT $1, $2, $2, $3, posts.id, posts.feed_id, feeds.name, posts.title, posts.url, posts.description, posts.published_at
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.id = $4
+ON CONFLICT (user_id, post_id) DO NOTHING
+`
+
+type SavePostIfUnsavedParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+}
+
+func (q *Queries) SavePostIfUnsaved(ctx context.Context, arg SavePostIfUnsavedParams) error {
+	_, err := q.db.ExecContext(ctx, savePostIfUnsaved,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UserID,
+		arg.PostID,
+	)
+	return err
+}
@@ -0,0 +1,139 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const searchPosts = `-- name: SearchPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories, feeds.name AS feed_name, results.rank,
+    ts_headline('english', posts.title, to_tsquery('english', $2),
+        'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS title_highlight,
+    ts_headline('english', regexp_replace(COALESCE(posts.description, ''), '<[^>]*>', ' ', 'g'), to_tsquery('english', $2),
+        'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2') AS snippet
+FROM (
+    SELECT posts.id, ts_rank_cd(post_search.search_vector, to_tsquery('english', $2)) AS rank
+    FROM posts
+    JOIN post_search ON post_search.post_id = posts.id
+    JOIN feeds ON feeds.id = posts.feed_id
+    WHERE post_search.search_vector @@ to_tsquery('english', $2)
+    AND feeds.deleted_at IS NULL
+    AND ($3::UUID IS NULL OR posts.feed_id = $3::UUID)
+    AND ($4::TIMESTAMP IS NULL OR COALESCE(posts.published_at, posts.created_at) >= $4::TIMESTAMP)
+    AND ($5::TIMESTAMP IS NULL OR COALESCE(posts.published_at, posts.created_at) < $5::TIMESTAMP)
+    AND (
+        EXISTS (
+            SELECT 1 FROM feed_follows
+            WHERE feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $6
+        )
+        OR (
+            NOT $7::BOOLEAN
+            AND (
+                feeds.user_id = $6
+                OR (
+                    feeds.kind <> 'newsletter'
+                    AND NOT EXISTS (
+                        SELECT 1 FROM feed_credentials WHERE feed_credentials.feed_id = feeds.id
+                    )
+                )
+            )
+        )
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = $6 AND hidden_posts.post_id = posts.id
+    )
+) results
+JOIN posts ON posts.id = results.id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE (
+    $8::REAL IS NULL
+    OR (results.rank, results.id) < ($8::REAL, $9::UUID)
+)
+ORDER BY results.rank DESC, results.id DESC
+LIMIT $1
+`
+
+type SearchPostsParams struct {
+	Limit        int32
+	Query        string
+	FeedID       uuid.NullUUID
+	Since        sql.NullTime
+	Until        sql.NullTime
+	UserID       uuid.UUID
+	FollowedOnly bool
+	CursorRank   sql.NullFloat64
+	CursorID     uuid.NullUUID
+}
+
+type SearchPostsRow struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Title          string
+	Url            string
+	Description    sql.NullString
+	PublishedAt    sql.NullTime
+	FeedID         uuid.UUID
+	OriginalUrl    sql.NullString
+	Author         sql.NullString
+	Categories     []string
+	FeedName       string
+	Rank           float32
+	TitleHighlight string
+	Snippet        string
+}
+
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPosts,
+		arg.Limit,
+		arg.Query,
+		arg.FeedID,
+		arg.Since,
+		arg.Until,
+		arg.UserID,
+		arg.FollowedOnly,
+		arg.CursorRank,
+		arg.CursorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsRow
+	for rows.Next() {
+		var i SearchPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FeedName,
+			&i.Rank,
+			&i.TitleHighlight,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
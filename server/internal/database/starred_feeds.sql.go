@@ -68,6 +68,40 @@ func (q *Queries) CreateStarredFeed(ctx context.Context, arg CreateStarredFeedPa
 	return i, err
 }
 
+const deleteOtherStarredFeedsForFeed = `-- name: DeleteOtherStarredFeedsForFeed :many
+DELETE FROM starred_feeds
+WHERE feed_id = $1 AND user_id <> $2
+RETURNING user_id
+`
+
+type DeleteOtherStarredFeedsForFeedParams struct {
+	FeedID uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteOtherStarredFeedsForFeed(ctx context.Context, arg DeleteOtherStarredFeedsForFeedParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, deleteOtherStarredFeedsForFeed, arg.FeedID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const deleteStarredFeed = `-- name: DeleteStarredFeed :exec
 DELETE FROM starred_feeds
 WHERE user_id = $1 AND feed_id = $2
@@ -83,6 +117,30 @@ func (q *Queries) DeleteStarredFeed(ctx context.Context, arg DeleteStarredFeedPa
 	return err
 }
 
+const deleteStarredFeedsForFeed = `-- name: DeleteStarredFeedsForFeed :exec
+DELETE FROM starred_feeds
+WHERE feed_id = $1
+`
+
+func (q *Queries) DeleteStarredFeedsForFeed(ctx context.Context, feedID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteStarredFeedsForFeed, feedID)
+	return err
+}
+
+const feedHasStars = `-- name: FeedHasStars :one
+SELECT EXISTS(
+    SELECT 1 FROM starred_feeds
+    WHERE feed_id = $1
+) AS has_stars
+`
+
+func (q *Queries) FeedHasStars(ctx context.Context, feedID uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, feedHasStars, feedID)
+	var has_stars bool
+	err := row.Scan(&has_stars)
+	return has_stars, err
+}
+
 const getStarredFeed = `-- name: GetStarredFeed :one
 SELECT id, user_id, feed_id, created_at, updated_at FROM starred_feeds
 WHERE user_id = $1 AND feed_id = $2
@@ -115,7 +173,7 @@ func (q *Queries) GetStarredFeed(ctx context.Context, arg GetStarredFeedParams)
 }
 
 const getStarredFeedsForUser = `-- name: GetStarredFeedsForUser :many
-SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id, f.last_fetched_at
+SELECT f.id, f.created_at, f.updated_at, f.name, f.url, f.user_id, f.last_fetched_at, f.kind, f.last_fetch_succeeded_at, f.deleted_at, f.site_url
 FROM feeds f
 JOIN starred_feeds sf ON f.id = sf.feed_id
 WHERE sf.user_id = $1
@@ -139,6 +197,10 @@ func (q *Queries) GetStarredFeedsForUser(ctx context.Context, userID uuid.UUID)
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Kind,
+			&i.LastFetchSucceededAt,
+			&i.DeletedAt,
+			&i.SiteUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -191,3 +253,26 @@ func (q *Queries) GetUsersStarringFeed(ctx context.Context, feedID uuid.UUID) ([
 	}
 	return items, nil
 }
+
+const starFeedIfUnstarred = `-- name: StarFeedIfUnstarred :exec
+INSERT INTO starred_feeds (id, user_id, feed_id, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $4)
+ON CONFLICT (user_id, feed_id) DO NOTHING
+`
+
+type StarFeedIfUnstarredParams struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) StarFeedIfUnstarred(ctx context.Context, arg StarFeedIfUnstarredParams) error {
+	_, err := q.db.ExecContext(ctx, starFeedIfUnstarred,
+		arg.ID,
+		arg.UserID,
+		arg.FeedID,
+		arg.CreatedAt,
+	)
+	return err
+}
@@ -0,0 +1,205 @@
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const deleteStaleTrendingPosts = `-- name: DeleteStaleTrendingPosts :exec
+DELETE FROM trending_posts
+WHERE computed_at < $1
+`
+
+func (q *Queries) DeleteStaleTrendingPosts(ctx context.Context, computedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteStaleTrendingPosts, computedAt)
+	return err
+}
+
+const getTrendingCandidates = `-- name: GetTrendingCandidates :many
+SELECT posts.id, posts.feed_id,
+    COALESCE(post_clusters.cluster_id, posts.id)::UUID AS cluster_id,
+    COALESCE(posts.published_at, posts.created_at)::TIMESTAMP AS posted_at,
+    (SELECT COUNT(*) FROM post_reads pr WHERE pr.post_id = posts.id) AS reads,
+    (SELECT COUNT(*) FROM saved_posts sp WHERE sp.post_id = posts.id) AS saves,
+    (SELECT COUNT(*) FROM starred_feeds sf WHERE sf.feed_id = posts.feed_id) AS stars
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_clusters ON post_clusters.post_id = posts.id
+WHERE COALESCE(posts.published_at, posts.created_at) > $1
+AND feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+`
+
+type GetTrendingCandidatesRow struct {
+	ID        uuid.UUID
+	FeedID    uuid.UUID
+	ClusterID uuid.UUID
+	PostedAt  time.Time
+	Reads     int64
+	Saves     int64
+	Stars     int64
+}
+
+func (q *Queries) GetTrendingCandidates(ctx context.Context, since time.Time) ([]GetTrendingCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTrendingCandidates, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTrendingCandidatesRow
+	for rows.Next() {
+		var i GetTrendingCandidatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FeedID,
+			&i.ClusterID,
+			&i.PostedAt,
+			&i.Reads,
+			&i.Saves,
+			&i.Stars,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrendingPosts = `-- name: GetTrendingPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories,
+    feeds.name AS feed_name,
+    trending_posts.score, trending_posts.reads, trending_posts.saves, trending_posts.stars, trending_posts.coverage
+FROM trending_posts
+JOIN posts ON posts.id = trending_posts.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $3 AND hidden_posts.post_id = posts.id
+)
+AND (
+    $2::TEXT IS NULL
+    OR EXISTS (
+        SELECT 1 FROM unnest(posts.categories) AS category
+        WHERE lower(category) = lower($2::TEXT)
+    )
+)
+ORDER BY trending_posts.score DESC, posts.id
+LIMIT $1
+`
+
+type GetTrendingPostsParams struct {
+	Limit    int32
+	Category sql.NullString
+	UserID   uuid.UUID
+}
+
+type GetTrendingPostsRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	OriginalUrl sql.NullString
+	Author      sql.NullString
+	Categories  []string
+	FeedName    string
+	Score       float64
+	Reads       int32
+	Saves       int32
+	Stars       int32
+	Coverage    int32
+}
+
+func (q *Queries) GetTrendingPosts(ctx context.Context, arg GetTrendingPostsParams) ([]GetTrendingPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTrendingPosts, arg.Limit, arg.Category, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTrendingPostsRow
+	for rows.Next() {
+		var i GetTrendingPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.OriginalUrl,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.FeedName,
+			&i.Score,
+			&i.Reads,
+			&i.Saves,
+			&i.Stars,
+			&i.Coverage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTrendingPost = `-- name: UpsertTrendingPost :exec
+INSERT INTO trending_posts (post_id, score, reads, saves, stars, coverage, computed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (post_id) DO UPDATE
+SET score = EXCLUDED.score, reads = EXCLUDED.reads, saves = EXCLUDED.saves,
+    stars = EXCLUDED.stars, coverage = EXCLUDED.coverage, computed_at = EXCLUDED.computed_at
+`
+
+type UpsertTrendingPostParams struct {
+	PostID     uuid.UUID
+	Score      float64
+	Reads      int32
+	Saves      int32
+	Stars      int32
+	Coverage   int32
+	ComputedAt time.Time
+}
+
+func (q *Queries) UpsertTrendingPost(ctx context.Context, arg UpsertTrendingPostParams) error {
+	_, err := q.db.ExecContext(ctx, upsertTrendingPost,
+		arg.PostID,
+		arg.Score,
+		arg.Reads,
+		arg.Saves,
+		arg.Stars,
+		arg.Coverage,
+		arg.ComputedAt,
+	)
+	return err
+}
@@ -0,0 +1,45 @@
+package secrets
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	box, err := NewBox(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewBox: %v", err)
+	}
+
+	sealed, err := box.Seal([]byte("hunter2"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("hunter2")) {
+		t.Fatal("Expected sealed value not to contain the plaintext")
+	}
+
+	opened, err := box.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if string(opened) != "hunter2" {
+		t.Errorf("Expected 'hunter2', got '%s'", opened)
+	}
+}
+
+func TestOpenRejectsTamperedSecret(t *testing.T) {
+	box, _ := NewBox(bytes.Repeat([]byte{7}, 32))
+	sealed, _ := box.Seal([]byte("hunter2"))
+	sealed[len(sealed)-1] ^= 0xff
+
+	if _, err := box.Open(sealed); err == nil {
+		t.Fatal("Expected tampered secret to fail to open")
+	}
+}
+
+func TestNewBoxFromStringRejectsShortKey(t *testing.T) {
+	if _, err := NewBoxFromString("c2hvcnQ="); err != ErrInvalidKey {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+}
@@ -14,13 +14,20 @@ import (
 	"github.com/joho/godotenv"
 
 	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/Sreenesh123/rssagg/internal/secrets"
 
 	_ "github.com/lib/pq"
 )
 
 type apiConfig struct {
 	DB *database.Queries
+	DBConn *sql.DB
     NotificationService *NotificationService
+	Credentials *secrets.Box
+	InboundDomain string
+	BackfillMaxPages int
+	Retention RetentionPolicy
+	FeedStats *FeedStatsCache
 }
 
 func main() {
@@ -42,6 +49,16 @@ func main() {
 		log.Fatal(err)
 	}
 	dbQueries := database.New(db)
+
+	var credentialsBox *secrets.Box
+	if key := os.Getenv("FEED_CREDENTIALS_KEY"); key != "" {
+		credentialsBox, err = secrets.NewBoxFromString(key)
+		if err != nil {
+			log.Fatalf("Invalid FEED_CREDENTIALS_KEY: %v", err)
+		}
+	} else {
+		log.Println("FEED_CREDENTIALS_KEY is not set, authenticated feeds are disabled")
+	}
     
     emailConfig := &EmailConfig{
         Host:      os.Getenv("EMAIL_HOST"),
@@ -65,9 +82,73 @@ func main() {
     notificationService.StartNotificationWorker(ctx)
     notificationService.StartStarredFeedNotificationWorker(ctx)
 
+	if value := os.Getenv("FETCH_ALLOW_PRIVATE_ADDRESSES"); value != "" {
+		allowPrivateFetches, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("Invalid FETCH_ALLOW_PRIVATE_ADDRESSES: %v", err)
+		}
+	}
+
+	backfillMaxPages := 0
+	if value := os.Getenv("BACKFILL_MAX_PAGES"); value != "" {
+		backfillMaxPages, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Invalid BACKFILL_MAX_PAGES: %v", err)
+		}
+	}
+
+	var retention RetentionPolicy
+	if value := os.Getenv("RETENTION_MAX_AGE_DAYS"); value != "" {
+		retention.MaxAgeDays, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Invalid RETENTION_MAX_AGE_DAYS: %v", err)
+		}
+	}
+	if value := os.Getenv("RETENTION_MAX_ITEMS"); value != "" {
+		retention.MaxItems, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Invalid RETENTION_MAX_ITEMS: %v", err)
+		}
+	}
+	if err := retention.validate(); err != nil {
+		log.Fatalf("Invalid retention policy: %v", err)
+	}
+
+	pipeline := ItemPipeline{TrackingParams: defaultTrackingParams, Retention: retention}
+	if value := os.Getenv("ITEM_PROCESSORS"); value != "" {
+		pipeline.Processors, err = parseProcessorConfigs([]byte(value))
+		if err != nil {
+			log.Fatalf("Invalid ITEM_PROCESSORS: %v", err)
+		}
+	}
+	if value := os.Getenv("TRACKING_PARAMS"); value != "" {
+		pipeline.TrackingParams = parseTrackingParams(value)
+	}
+
+	var inboundDomain string
+	if smtpAddr := os.Getenv("SMTP_LISTEN_ADDR"); smtpAddr != "" {
+		inboundDomain = os.Getenv("SMTP_DOMAIN")
+		if inboundDomain == "" {
+			log.Fatal("SMTP_DOMAIN environment variable must be set when SMTP_LISTEN_ADDR is")
+		}
+		receiver := NewSMTPReceiver(dbQueries, inboundDomain, pipeline)
+		go func() {
+			log.Fatal(receiver.ListenAndServe(smtpAddr))
+		}()
+	}
+
+	feedStats := NewFeedStatsCache(dbQueries, 15*time.Minute)
+	feedStats.StartRefresher(ctx, 5*time.Minute)
+
 	apiCfg := apiConfig{
 		DB: dbQueries,
+		DBConn: db,
         NotificationService: notificationService,
+		Credentials: credentialsBox,
+		InboundDomain: inboundDomain,
+		BackfillMaxPages: backfillMaxPages,
+		Retention: retention,
+		FeedStats: feedStats,
 	}
 
  
@@ -76,7 +157,7 @@ func main() {
 
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: true,
@@ -92,12 +173,81 @@ func main() {
 
 	v1Router.Post("/feeds", apiCfg.middlewareAuth(apiCfg.handlerFeedCreate))
 	v1Router.Get("/feeds", apiCfg.handlerGetFeeds)
+	v1Router.Post("/feeds/test-selectors", apiCfg.middlewareAuth(apiCfg.handlerSelectorsTest))
+	v1Router.Get("/feeds/{feedID}", apiCfg.middlewareAuth(apiCfg.handlerFeedGet))
+	v1Router.Patch("/feeds/{feedID}", apiCfg.middlewareAuth(apiCfg.handlerFeedUpdate))
+	v1Router.Delete("/feeds/{feedID}", apiCfg.middlewareAuth(apiCfg.handlerFeedDelete))
+	v1Router.Put("/feeds/{feedID}/credentials", apiCfg.middlewareAuth(apiCfg.handlerFeedCredentialsUpdate))
+	v1Router.Delete("/feeds/{feedID}/credentials", apiCfg.middlewareAuth(apiCfg.handlerFeedCredentialsDelete))
+	v1Router.Post("/feeds/{feedID}/backfill", apiCfg.middlewareAuth(apiCfg.handlerFeedBackfillStart))
+	v1Router.Get("/feeds/{feedID}/backfill", apiCfg.middlewareAuth(apiCfg.handlerFeedBackfillGet))
+	v1Router.Get("/feeds/{feedID}/stats", apiCfg.middlewareAuth(apiCfg.handlerFeedStatsGet))
+	v1Router.Get("/feeds/{feedID}/retention", apiCfg.middlewareAuth(apiCfg.handlerFeedRetentionGet))
+	v1Router.Put("/feeds/{feedID}/retention", apiCfg.middlewareAuth(apiCfg.handlerFeedRetentionUpdate))
+	v1Router.Get("/feeds/{feedID}/processors", apiCfg.middlewareAuth(apiCfg.handlerFeedProcessorsGet))
+	v1Router.Put("/feeds/{feedID}/processors", apiCfg.middlewareAuth(apiCfg.handlerFeedProcessorsUpdate))
+	v1Router.Delete("/feeds/{feedID}/processors", apiCfg.middlewareAuth(apiCfg.handlerFeedProcessorsDelete))
 
 	v1Router.Get("/posts", apiCfg.middlewareAuth(apiCfg.handlerGetPosts))
+	v1Router.Get("/search", apiCfg.middlewareAuth(apiCfg.handlerSearchPosts))
+	v1Router.Post("/posts/read", apiCfg.middlewareAuth(apiCfg.handlerPostsMarkAllRead))
+	v1Router.Get("/posts/unread-counts", apiCfg.middlewareAuth(apiCfg.handlerUnreadCountsGet))
+	v1Router.Get("/posts/{postID}", apiCfg.middlewareAuth(apiCfg.handlerGetPost))
+	v1Router.Put("/posts/{postID}/read", apiCfg.middlewareAuth(apiCfg.handlerPostMarkRead))
+	v1Router.Delete("/posts/{postID}/read", apiCfg.middlewareAuth(apiCfg.handlerPostMarkUnread))
+	v1Router.Post("/feeds/{feedID}/read", apiCfg.middlewareAuth(apiCfg.handlerFeedMarkAllRead))
+	v1Router.Post("/posts/{postID}/save", apiCfg.middlewareAuth(apiCfg.handlerPostSave))
+	v1Router.Delete("/posts/{postID}/save", apiCfg.middlewareAuth(apiCfg.handlerPostUnsave))
+	v1Router.Get("/saved", apiCfg.middlewareAuth(apiCfg.handlerSavedPostsGet))
+	v1Router.Delete("/saved/{savedID}", apiCfg.middlewareAuth(apiCfg.handlerSavedPostDelete))
+	v1Router.Put("/posts/{postID}/labels/{labelID}", apiCfg.middlewareAuth(apiCfg.handlerPostLabelAdd))
+	v1Router.Delete("/posts/{postID}/labels/{labelID}", apiCfg.middlewareAuth(apiCfg.handlerPostLabelRemove))
+
+	v1Router.Get("/labels", apiCfg.middlewareAuth(apiCfg.handlerLabelsGet))
+	v1Router.Post("/labels", apiCfg.middlewareAuth(apiCfg.handlerLabelCreate))
+	v1Router.Patch("/labels/{labelID}", apiCfg.middlewareAuth(apiCfg.handlerLabelUpdate))
+	v1Router.Delete("/labels/{labelID}", apiCfg.middlewareAuth(apiCfg.handlerLabelDelete))
+	v1Router.Post("/labels/{labelID}/merge", apiCfg.middlewareAuth(apiCfg.handlerLabelMerge))
 
 	v1Router.Get("/feed_follows", apiCfg.middlewareAuth(apiCfg.handlerFeedFollowsGet))
 	v1Router.Post("/feed_follows", apiCfg.middlewareAuth(apiCfg.handlerFeedFollowCreate))
 	v1Router.Delete("/feed_follows/{feedFollowID}", apiCfg.middlewareAuth(apiCfg.handlerFeedFollowDelete))
+	v1Router.Patch("/feed_follows/{feedFollowID}", apiCfg.middlewareAuth(apiCfg.handlerFeedFollowUpdate))
+
+	v1Router.Get("/folders", apiCfg.middlewareAuth(apiCfg.handlerFoldersGet))
+	v1Router.Post("/folders", apiCfg.middlewareAuth(apiCfg.handlerFolderCreate))
+	v1Router.Patch("/folders/{folderID}", apiCfg.middlewareAuth(apiCfg.handlerFolderUpdate))
+	v1Router.Delete("/folders/{folderID}", apiCfg.middlewareAuth(apiCfg.handlerFolderDelete))
+
+	v1Router.Get("/filter_rules", apiCfg.middlewareAuth(apiCfg.handlerFilterRulesGet))
+	v1Router.Post("/filter_rules", apiCfg.middlewareAuth(apiCfg.handlerFilterRuleCreate))
+	v1Router.Post("/filter_rules/test", apiCfg.middlewareAuth(apiCfg.handlerFilterRuleTest))
+	v1Router.Patch("/filter_rules/{ruleID}", apiCfg.middlewareAuth(apiCfg.handlerFilterRuleUpdate))
+	v1Router.Delete("/filter_rules/{ruleID}", apiCfg.middlewareAuth(apiCfg.handlerFilterRuleDelete))
+	v1Router.Post("/filter_rules/{ruleID}/test", apiCfg.middlewareAuth(apiCfg.handlerFilterRuleTestSaved))
+
+	v1Router.Get("/published_feeds", apiCfg.middlewareAuth(apiCfg.handlerPublishedFeedsGet))
+	v1Router.Post("/published_feeds", apiCfg.middlewareAuth(apiCfg.handlerPublishedFeedCreate))
+	v1Router.Delete("/published_feeds/{publishedFeedID}", apiCfg.middlewareAuth(apiCfg.handlerPublishedFeedDelete))
+	v1Router.Get("/published/{token}.{format}", apiCfg.handlerPublishedFeedServe)
+
+	v1Router.Get("/collections", apiCfg.middlewareAuth(apiCfg.handlerCollectionsGet))
+	v1Router.Post("/collections", apiCfg.middlewareAuth(apiCfg.handlerCollectionCreate))
+	v1Router.Get("/collections/{collectionID}", apiCfg.middlewareAuth(apiCfg.handlerCollectionGet))
+	v1Router.Patch("/collections/{collectionID}", apiCfg.middlewareAuth(apiCfg.handlerCollectionUpdate))
+	v1Router.Delete("/collections/{collectionID}", apiCfg.middlewareAuth(apiCfg.handlerCollectionDelete))
+	v1Router.Post("/collections/{collectionID}/revoke", apiCfg.middlewareAuth(apiCfg.handlerCollectionRevoke))
+	v1Router.Post("/collections/{collectionID}/items", apiCfg.middlewareAuth(apiCfg.handlerCollectionItemAdd))
+	v1Router.Delete("/collections/{collectionID}/items/{itemID}", apiCfg.middlewareAuth(apiCfg.handlerCollectionItemRemove))
+	v1Router.Get("/shared", apiCfg.handlerPublicCollectionsGet)
+	v1Router.Get("/shared/{slug}", apiCfg.handlerSharedCollectionGet)
+	v1Router.Get("/shared/{slug}.rss", apiCfg.handlerSharedCollectionRSS)
+
+	v1Router.Get("/trending", apiCfg.middlewareAuth(apiCfg.handlerTrendingGet))
+
+	v1Router.Post("/import/opml", apiCfg.middlewareAuth(apiCfg.handlerOPMLImport))
+	v1Router.Get("/import/opml/{importID}", apiCfg.middlewareAuth(apiCfg.handlerOPMLImportGet))
+	v1Router.Get("/export/opml", apiCfg.middlewareAuth(apiCfg.handlerOPMLExport))
 	
 	v1Router.Post("/summarize", apiCfg.middlewareAuth(apiCfg.handlerSummarize))
     v1Router.Post("/starred-feeds", apiCfg.middlewareAuth(apiCfg.CreateStarredFeedHandler))
@@ -108,6 +258,10 @@ func main() {
 	v1Router.Get("/notification-settings", apiCfg.middlewareAuth(apiCfg.GetNotificationSettingsHandler))
     v1Router.Patch("/notification-settings", apiCfg.middlewareAuth(apiCfg.UpdateNotificationSettingsHandler))
 
+	v1Router.Post("/inbound-addresses", apiCfg.middlewareAuth(apiCfg.handlerInboundAddressCreate))
+	v1Router.Get("/inbound-addresses", apiCfg.middlewareAuth(apiCfg.handlerInboundAddressesGet))
+	v1Router.Delete("/inbound-addresses/{addressID}", apiCfg.middlewareAuth(apiCfg.handlerInboundAddressDelete))
+
 	v1Router.Get("/healthz", handlerReadiness)
 	v1Router.Get("/err", handlerErr)
 
@@ -119,7 +273,19 @@ func main() {
 
 	const collectionConcurrency = 10
 	const collectionInterval = time.Minute
-	go startScraping(dbQueries, collectionConcurrency, collectionInterval)
+	go startScraping(dbQueries, credentialsBox, pipeline, collectionConcurrency, collectionInterval)
+	const clusterInterval = time.Minute
+	go startClustering(dbQueries, clusterInterval)
+	const pruneInterval = time.Hour
+	go startPruning(dbQueries, retention, pruneInterval)
+	const trendingInterval = 15 * time.Minute
+	go startTrending(dbQueries, trendingInterval)
+	const opmlImportInterval = 10 * time.Second
+	go startImportingOPML(dbQueries, backfillMaxPages, opmlImportInterval)
+	if backfillMaxPages > 0 {
+		const backfillInterval = 10 * time.Second
+		go startBackfilling(dbQueries, credentialsBox, pipeline, backfillInterval)
+	}
 
 	log.Printf("Serving on port: %s\n", port)
 	log.Fatal(srv.ListenAndServe())
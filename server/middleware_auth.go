@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -14,53 +15,57 @@ type authedHandler func(http.ResponseWriter, *http.Request, database.User)
 
 func (cfg *apiConfig) middlewareAuth(next authedHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			respondWithError(w, http.StatusUnauthorized, "Missing Authorization header")
+		user, err := cfg.authenticate(r)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, err.Error())
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			respondWithError(w, http.StatusUnauthorized, "Malformed token")
-			return
-		}
+		next(w, r, user)
+	}
+}
 
-		secret := os.Getenv("JWT_SECRET")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method")
-			}
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
-			respondWithError(w, http.StatusUnauthorized, "Invalid token")
-			return
-		}
+// authenticate resolves the user behind the request's bearer token. Public
+// handlers use it directly to tailor responses for signed-in users.
+func (cfg *apiConfig) authenticate(r *http.Request) (database.User, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return database.User{}, errors.New("Missing Authorization header")
+	}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			respondWithError(w, http.StatusUnauthorized, "Invalid claims")
-			return
-		}
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		return database.User{}, errors.New("Malformed token")
+	}
 
-		userIDStr, ok := claims["user_id"].(string)
-		if !ok {
-			respondWithError(w, http.StatusUnauthorized, "Invalid user ID in token")
-			return
-		}
-		userID, err := uuid.Parse(userIDStr)
-		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, "Malformed user ID")
-			return
+	secret := os.Getenv("JWT_SECRET")
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
 		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return database.User{}, errors.New("Invalid token")
+	}
 
-		user, err := cfg.DB.GetUserByID(r.Context(), userID)
-		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, "User not found")
-			return
-		}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return database.User{}, errors.New("Invalid claims")
+	}
 
-		next(w, r, user)
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return database.User{}, errors.New("Invalid user ID in token")
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return database.User{}, errors.New("Malformed user ID")
+	}
+
+	user, err := cfg.DB.GetUserByID(r.Context(), userID)
+	if err != nil {
+		return database.User{}, errors.New("User not found")
 	}
+	return user, nil
 }
@@ -27,24 +27,30 @@ func databaseUserToUser(user database.User) User {
 }
 
 type Feed struct {
-	ID            uuid.UUID  `json:"id"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	Name          string     `json:"name"`
-	Url           string     `json:"url"`
-	UserID        uuid.UUID  `json:"user_id"`
-	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	ID                   uuid.UUID  `json:"id"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+	Name                 string     `json:"name"`
+	Url                  string     `json:"url"`
+	UserID               uuid.UUID  `json:"user_id"`
+	LastFetchedAt        *time.Time `json:"last_fetched_at"`
+	Kind                 string     `json:"kind"`
+	LastFetchSucceededAt *time.Time `json:"last_fetch_succeeded_at"`
+	SiteUrl              *string    `json:"site_url"`
 }
 
 func databaseFeedToFeed(feed database.Feed) Feed {
 	return Feed{
-		ID:            feed.ID,
-		CreatedAt:     feed.CreatedAt,
-		UpdatedAt:     feed.UpdatedAt,
-		Name:          feed.Name,
-		Url:           feed.Url,
-		UserID:        feed.UserID,
-		LastFetchedAt: nullTimeToTimePtr(feed.LastFetchedAt),
+		ID:                   feed.ID,
+		CreatedAt:            feed.CreatedAt,
+		UpdatedAt:            feed.UpdatedAt,
+		Name:                 feed.Name,
+		Url:                  feed.Url,
+		UserID:               feed.UserID,
+		LastFetchedAt:        nullTimeToTimePtr(feed.LastFetchedAt),
+		Kind:                 feed.Kind,
+		LastFetchSucceededAt: nullTimeToTimePtr(feed.LastFetchSucceededAt),
+		SiteUrl:              nullStringToStringPtr(feed.SiteUrl),
 	}
 }
 
@@ -57,20 +63,32 @@ func databaseFeedsToFeeds(feeds []database.Feed) []Feed {
 }
 
 type FeedFollow struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UserID    uuid.UUID `json:"user_id"`
-	FeedID    uuid.UUID `json:"feed_id"`
+	ID               uuid.UUID  `json:"id"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	UserID           uuid.UUID  `json:"user_id"`
+	FeedID           uuid.UUID  `json:"feed_id"`
+	FolderID         *uuid.UUID `json:"folder_id"`
+	Position         int32      `json:"position"`
+	Title            *string    `json:"title"`
+	HideFromTimeline bool       `json:"hide_from_timeline"`
+	FullContent      bool       `json:"full_content"`
+	SortOrder        string     `json:"sort_order"`
 }
 
 func databaseFeedFollowToFeedFollow(feedFollow database.FeedFollow) FeedFollow {
 	return FeedFollow{
-		ID:        feedFollow.ID,
-		CreatedAt: feedFollow.CreatedAt,
-		UpdatedAt: feedFollow.UpdatedAt,
-		UserID:    feedFollow.UserID,
-		FeedID:    feedFollow.FeedID,
+		ID:               feedFollow.ID,
+		CreatedAt:        feedFollow.CreatedAt,
+		UpdatedAt:        feedFollow.UpdatedAt,
+		UserID:           feedFollow.UserID,
+		FeedID:           feedFollow.FeedID,
+		FolderID:         nullUUIDToUUIDPtr(feedFollow.FolderID),
+		Position:         feedFollow.Position,
+		Title:            nullStringToStringPtr(feedFollow.Title),
+		HideFromTimeline: feedFollow.HideFromTimeline,
+		FullContent:      feedFollow.FullContent,
+		SortOrder:        feedFollow.SortOrder,
 	}
 }
 
@@ -91,6 +109,9 @@ type Post struct {
 	Description *string    `json:"description"`
 	PublishedAt *time.Time `json:"published_at"`
 	FeedID      uuid.UUID  `json:"feed_id"`
+	OriginalUrl *string    `json:"original_url"`
+	Author      *string    `json:"author"`
+	Categories  []string   `json:"categories"`
 }
 
 func databasePostToPost(post database.Post) Post {
@@ -103,6 +124,9 @@ func databasePostToPost(post database.Post) Post {
 		Description: nullStringToStringPtr(post.Description),
 		PublishedAt: nullTimeToTimePtr(post.PublishedAt),
 		FeedID:      post.FeedID,
+		OriginalUrl: nullStringToStringPtr(post.OriginalUrl),
+		Author:      nullStringToStringPtr(post.Author),
+		Categories:  post.Categories,
 	}
 }
 
@@ -126,4 +150,32 @@ func nullStringToStringPtr(s sql.NullString) *string {
 		return &s.String
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func nullStringFromString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
+
+func nullInt32ToInt32Ptr(n sql.NullInt32) *int32 {
+	if n.Valid {
+		return &n.Int32
+	}
+	return nil
+}
+
+func int32PtrToNullInt32(n *int32) sql.NullInt32 {
+	if n == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *n, Valid: true}
+}
+
+func nullUUIDToUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if id.Valid {
+		return &id.UUID
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html/charset"
+)
+
+// contentPolicy sanitises HTML taken from mail and web pages.
+var contentPolicy = bluemonday.UGCPolicy()
+
+type Newsletter struct {
+	Subject   string
+	From      string
+	MessageID string
+	Date      sql.NullTime
+	HTML      string
+	Text      string
+}
+
+// parseNewsletter reads a raw RFC 5322 message and keeps the first HTML and
+// plain text bodies it finds, skipping attachments.
+func parseNewsletter(r io.Reader) (*Newsletter, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	newsletter := &Newsletter{
+		Subject:   strings.TrimSpace(subject),
+		MessageID: strings.Trim(msg.Header.Get("Message-Id"), " <>"),
+	}
+	parser := mail.AddressParser{WordDecoder: &decoder}
+	if from, err := parser.Parse(msg.Header.Get("From")); err == nil {
+		newsletter.From = from.Name
+		if newsletter.From == "" {
+			newsletter.From = from.Address
+		}
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		newsletter.Date = sql.NullTime{Time: date.UTC(), Valid: true}
+	}
+
+	err = newsletter.readPart(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+	return newsletter, nil
+}
+
+func (n *Newsletter) readPart(contentType, transferEncoding string, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(part.Header.Get("Content-Disposition"), "attachment") {
+				continue
+			}
+			err = n.readPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if mediaType != "text/html" && mediaType != "text/plain" {
+		return nil
+	}
+	if (mediaType == "text/html" && n.HTML != "") || (mediaType == "text/plain" && n.Text != "") {
+		return nil
+	}
+
+	switch strings.ToLower(transferEncoding) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	if label := params["charset"]; label != "" {
+		if converted, err := charset.NewReaderLabel(label, body); err == nil {
+			body = converted
+		}
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	if mediaType == "text/html" {
+		n.HTML = string(content)
+	} else {
+		n.Text = string(content)
+	}
+	return nil
+}
+
+// Content is the sanitised HTML body, falling back to the plain text one.
+func (n *Newsletter) Content() string {
+	if n.HTML != "" {
+		return strings.TrimSpace(contentPolicy.Sanitize(n.HTML))
+	}
+	return strings.TrimSpace(n.Text)
+}
+
+// newsletterItem turns a newsletter into an item of the feed behind the
+// address it was sent to.
+func newsletterItem(feedID uuid.UUID, newsletter *Newsletter, received time.Time) RSSItem {
+	messageID := newsletter.MessageID
+	if messageID == "" {
+		messageID = uuid.New().String()
+	}
+	title := newsletter.Subject
+	if title == "" {
+		title = "(no subject)"
+	}
+	publishedAt := received
+	if newsletter.Date.Valid {
+		publishedAt = newsletter.Date.Time
+	}
+
+	// The same message can reach several users, so scope its mid: URL to the feed.
+	return RSSItem{
+		Title:       title,
+		Link:        fmt.Sprintf("mid:%s#%s", url.PathEscape(messageID), feedID),
+		Description: newsletter.Content(),
+		PubDate:     publishedAt.Format(time.RFC1123Z),
+		Author:      newsletter.From,
+	}
+}
+
+// deliverNewsletter stores a newsletter as a post in the address's feed,
+// through the same processors as fetched items.
+func deliverNewsletter(ctx context.Context, db *database.Queries, pipeline ItemPipeline, address database.InboundAddress, newsletter *Newsletter) error {
+	feed, err := db.GetFeed(ctx, address.FeedID)
+	if err != nil {
+		return err
+	}
+	items, err := pipeline.process(db, feed, "", []RSSItem{newsletterItem(feed.ID, newsletter, time.Now().UTC())})
+	if err != nil {
+		return err
+	}
+	_, err = savePosts(db, feed, items, true)
+	return err
+}
+
+func inboundAddress(token, domain string) string {
+	return "u-" + token + "@" + domain
+}
+
+// inboundToken extracts the token from a u-<token>@<domain> address, or
+// returns "" when the address isn't one of ours.
+func inboundToken(address, domain string) string {
+	address = strings.ToLower(strings.TrimSpace(address))
+	local, host, ok := strings.Cut(address, "@")
+	if !ok || host != strings.ToLower(domain) || !strings.HasPrefix(local, "u-") {
+		return ""
+	}
+	return strings.TrimPrefix(local, "u-")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseNewsletter(t *testing.T) {
+	raw := strings.Join([]string{
+		"From: Weekly <news@example.com>",
+		"To: u-abc123@feeds.example.com",
+		"Subject: =?UTF-8?Q?Caf=C3=A9_weekly?=",
+		"Message-ID: <issue-42@example.com>",
+		"Date: Mon, 04 Mar 2024 09:30:00 +0000",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/alternative; boundary="b1"`,
+		"",
+		"--b1",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		"Plain version",
+		"--b1",
+		"Content-Type: text/html; charset=utf-8",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		`<p>Hello <a href=3D"https://example.com">there</a></p><script>alert(1)</script>`,
+		"--b1--",
+		"",
+	}, "\r\n")
+
+	newsletter, err := parseNewsletter(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parseNewsletter: %v", err)
+	}
+	if newsletter.Subject != "Café weekly" {
+		t.Errorf("Expected decoded subject, got %q", newsletter.Subject)
+	}
+	if newsletter.From != "Weekly" {
+		t.Errorf("Expected sender name, got %q", newsletter.From)
+	}
+	if newsletter.MessageID != "issue-42@example.com" {
+		t.Errorf("Expected bare message ID, got %q", newsletter.MessageID)
+	}
+	if !newsletter.Date.Valid {
+		t.Error("Expected Date header to be parsed")
+	}
+	if newsletter.Text != "Plain version" {
+		t.Errorf("Expected plain text part, got %q", newsletter.Text)
+	}
+
+	content := newsletter.Content()
+	if strings.Contains(content, "<script>") {
+		t.Errorf("Expected scripts to be stripped, got %q", content)
+	}
+	if !strings.Contains(content, `href="https://example.com"`) {
+		t.Errorf("Expected decoded link to survive sanitising, got %q", content)
+	}
+}
+
+func TestInboundToken(t *testing.T) {
+	if token := inboundToken("U-ABC123@Feeds.Example.com", "feeds.example.com"); token != "abc123" {
+		t.Errorf("Expected token 'abc123', got %q", token)
+	}
+	if token := inboundToken("u-abc123@elsewhere.com", "feeds.example.com"); token != "" {
+		t.Errorf("Expected foreign domain to be rejected, got %q", token)
+	}
+}
+
+func TestNewsletterItem(t *testing.T) {
+	feedID := uuid.New()
+	received := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	sent := received.Add(-time.Hour)
+	newsletter := &Newsletter{
+		Subject:   "Weekly",
+		From:      "Ann <ann@example.com>",
+		MessageID: "abc/1@example.com",
+		Date:      sql.NullTime{Time: sent, Valid: true},
+		Text:      "Hello",
+	}
+
+	item := newsletterItem(feedID, newsletter, received)
+	if want := "mid:abc%2F1@example.com#" + feedID.String(); item.Link != want {
+		t.Errorf("Link = %q, want %q", item.Link, want)
+	}
+	if published := parsePublishedAt(item.PubDate); !published.Valid || !published.Time.Equal(sent) {
+		t.Errorf("PubDate %q doesn't parse back to %v", item.PubDate, sent)
+	}
+	if item.Title != "Weekly" || item.Description != "Hello" || item.author() != "Ann <ann@example.com>" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	canonical := ProcessorChain{canonicalizeItems{}}.Run(ItemContext{}, []RSSItem{item})
+	if canonical[0].Link != item.Link {
+		t.Errorf("canonicalising changed the mid: link to %q", canonical[0].Link)
+	}
+
+	item = newsletterItem(feedID, &Newsletter{}, received)
+	if item.Title != "(no subject)" {
+		t.Errorf("Title = %q, want (no subject)", item.Title)
+	}
+	if published := parsePublishedAt(item.PubDate); !published.Time.Equal(received) {
+		t.Errorf("undated newsletter published at %v, want %v", published.Time, received)
+	}
+}
@@ -16,6 +16,9 @@ type NotificationType string
 const (
 	NotificationTypeNewPost NotificationType = "new_post"
 	NotificationTypeFeedStarred NotificationType = "feed_starred"
+	NotificationTypeFeedDeleted NotificationType = "feed_deleted"
+	NotificationTypeRuleMatched NotificationType = "rule_matched"
+	NotificationTypeFeedMadePrivate NotificationType = "feed_made_private"
 )
 type EmailConfig struct {
 	Host     string
@@ -128,6 +131,37 @@ func (ns *NotificationService) SendFeedStarredNotification(
 	
 	return ns.createNotification(ctx, user.ID, NotificationTypeFeedStarred, message, metadata)
 }
+// SendFeedDeletedNotification tells a follower that a feed they followed was
+// removed by its creator.
+func (ns *NotificationService) SendFeedDeletedNotification(
+	ctx context.Context,
+	userID uuid.UUID,
+	feed database.Feed,
+) error {
+	message := fmt.Sprintf("The feed %s has been deleted by its creator and removed from your feeds.", feed.Name)
+	metadata := map[string]interface{}{
+		"feed_id":   feed.ID.String(),
+		"feed_name": feed.Name,
+		"feed_url":  feed.Url,
+	}
+	return ns.createNotification(ctx, userID, NotificationTypeFeedDeleted, message, metadata)
+}
+
+// SendFeedMadePrivateNotification tells a follower that a feed they followed
+// or starred was made private by its creator.
+func (ns *NotificationService) SendFeedMadePrivateNotification(
+	ctx context.Context,
+	userID uuid.UUID,
+	feed database.Feed,
+) error {
+	message := fmt.Sprintf("The feed %s has been made private by its creator and removed from your feeds.", feed.Name)
+	metadata := map[string]interface{}{
+		"feed_id":   feed.ID.String(),
+		"feed_name": feed.Name,
+	}
+	return ns.createNotification(ctx, userID, NotificationTypeFeedMadePrivate, message, metadata)
+}
+
 func (ns *NotificationService) SendNewPostNotification(
 	ctx context.Context,
 	post database.Post,
@@ -0,0 +1,522 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	OPMLImportPending = "pending"
+	OPMLImportRunning = "running"
+	OPMLImportDone    = "done"
+
+	OPMLItemPending   = "pending"
+	OPMLItemSucceeded = "succeeded"
+	OPMLItemFailed    = "failed"
+)
+
+// opmlImportBatchSize is how many subscriptions are imported per tick. Each
+// new feed is fetched once to check it, so a large file takes a few ticks.
+// Batches take items from every running import in turn, so one large file
+// doesn't hold up everyone else's.
+const opmlImportBatchSize = 20
+
+// opmlImportConcurrency is how many of a batch's new feeds are fetched at once.
+const opmlImportConcurrency = 5
+
+var errNoSubscriptions = errors.New("OPML document has no feeds")
+
+// opmlOutline keeps every attribute so they can be matched without regard to
+// case: OPML 1.0 exporters write xmlUrl, xmlURL and xmlurl.
+type opmlOutline struct {
+	Attrs    []xml.Attr    `xml:",any,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+func (o opmlOutline) attr(name string) string {
+	for _, attr := range o.Attrs {
+		if strings.EqualFold(attr.Name.Local, name) {
+			return strings.TrimSpace(attr.Value)
+		}
+	}
+	return ""
+}
+
+// opmlSubscription is a feed found in an OPML document. Folder and Subfolder
+// name the outlines it was nested in; deeper outlines fold into the
+// subfolder, since folders nest one level. Starred is set by the
+// opmlStarredCategory category, and StarOnly, along with it, by
+// opmlStarOnlyCategory.
+type opmlSubscription struct {
+	URL       string
+	Title     string
+	Folder    string
+	Subfolder string
+	Starred   bool
+	StarOnly  bool
+}
+
+// opmlStarredCategory is the OPML 2.0 category the export puts starred feeds
+// in, and opmlStarOnlyCategory the one for starred feeds the user doesn't
+// follow, which the import stars without following. Categories are
+// comma-separated slash paths.
+const (
+	opmlStarredCategory  = "/starred"
+	opmlStarOnlyCategory = "/starred/unfollowed"
+)
+
+func hasOPMLCategory(categories, category string) bool {
+	for _, c := range strings.Split(categories, ",") {
+		if strings.EqualFold(strings.TrimSpace(c), category) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOPML lists the feeds in an OPML 1.0 or 2.0 document in document
+// order, keeping the first of any repeated URL.
+func parseOPML(data []byte) ([]opmlSubscription, error) {
+	var doc struct {
+		XMLName xml.Name `xml:"opml"`
+		Body    struct {
+			Outlines []opmlOutline `xml:"outline"`
+		} `xml:"body"`
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid OPML: %w", err)
+	}
+
+	var subscriptions []opmlSubscription
+	seen := map[string]bool{}
+	var walk func(outlines []opmlOutline, folders []string)
+	walk = func(outlines []opmlOutline, folders []string) {
+		for _, outline := range outlines {
+			title := outline.attr("title")
+			if title == "" {
+				title = outline.attr("text")
+			}
+			feedURL := outline.attr("xmlUrl")
+			if feedURL == "" {
+				if title != "" && len(folders) < 2 {
+					walk(outline.Outlines, append(folders, title))
+				} else {
+					walk(outline.Outlines, folders)
+				}
+				continue
+			}
+			if seen[feedURL] {
+				continue
+			}
+			seen[feedURL] = true
+			categories := outline.attr("category")
+			subscription := opmlSubscription{
+				URL:      feedURL,
+				Title:    title,
+				StarOnly: hasOPMLCategory(categories, opmlStarOnlyCategory),
+			}
+			subscription.Starred = subscription.StarOnly || hasOPMLCategory(categories, opmlStarredCategory)
+			if len(folders) > 0 {
+				subscription.Folder = folders[0]
+			}
+			if len(folders) > 1 {
+				subscription.Subfolder = folders[1]
+			}
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	walk(doc.Body.Outlines, nil)
+
+	if len(subscriptions) == 0 {
+		return nil, errNoSubscriptions
+	}
+	return subscriptions, nil
+}
+
+// startImportingOPML works through pending OPML import items, a few from each
+// import per tick. backfillMaxPages starts a backfill for the feeds it creates, as
+// creating them through the API does.
+func startImportingOPML(db *database.Queries, backfillMaxPages int, timeBetweenRuns time.Duration) {
+	log.Printf("Importing OPML subscriptions every %s...", timeBetweenRuns)
+	ticker := time.NewTicker(timeBetweenRuns)
+
+	for ; ; <-ticker.C {
+		items, err := db.GetPendingOPMLImportItems(context.Background(), opmlImportBatchSize)
+		if err != nil {
+			log.Println("Couldn't get pending OPML import items", err)
+			continue
+		}
+		fetched := prefetchOPMLFeeds(db, items)
+		for _, item := range items {
+			importOPMLItem(db, backfillMaxPages, item, fetched[item.ID])
+		}
+		if err := db.FinishOPMLImports(context.Background(), time.Now().UTC()); err != nil {
+			log.Println("Couldn't finish OPML imports", err)
+		}
+	}
+}
+
+// opmlFetch is the result of fetching an item's URL ahead of importing it.
+type opmlFetch struct {
+	feed *RSSFeed
+	err  error
+}
+
+// prefetchOPMLFeeds fetches the URLs in items that no feed has yet, a few at
+// a time. Creating feeds and follows stays sequential, so items sharing a
+// folder don't race to create it.
+func prefetchOPMLFeeds(db *database.Queries, items []database.GetPendingOPMLImportItemsRow) map[uuid.UUID]opmlFetch {
+	fetched := make(map[uuid.UUID]opmlFetch, len(items))
+	mu := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	slots := make(chan struct{}, opmlImportConcurrency)
+	for _, item := range items {
+		if !validImportURL(item.Url) {
+			continue
+		}
+		_, err := db.GetFeedByURL(context.Background(), item.Url)
+		if !errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(item database.GetPendingOPMLImportItemsRow) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			feed, err := fetchFeed(item.Url, nil)
+			mu.Lock()
+			fetched[item.ID] = opmlFetch{feed: feed, err: err}
+			mu.Unlock()
+		}(item)
+	}
+	wg.Wait()
+	return fetched
+}
+
+func validImportURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func importOPMLItem(db *database.Queries, backfillMaxPages int, item database.GetPendingOPMLImportItemsRow, fetched opmlFetch) {
+	ctx := context.Background()
+	if err := db.MarkOPMLImportRunning(ctx, database.MarkOPMLImportRunningParams{
+		ID:        item.ImportID,
+		UpdatedAt: time.Now().UTC(),
+	}); err != nil {
+		log.Printf("Couldn't mark OPML import %s running: %v", item.ImportID, err)
+	}
+
+	result := database.FinishOPMLImportItemParams{
+		ID:        item.ID,
+		Status:    OPMLItemSucceeded,
+		UpdatedAt: time.Now().UTC(),
+	}
+	feedID, err := importSubscription(ctx, db, backfillMaxPages, item, fetched)
+	if err != nil {
+		result.Status = OPMLItemFailed
+		result.Error = sql.NullString{String: err.Error(), Valid: true}
+	} else {
+		result.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	}
+	if err := db.FinishOPMLImportItem(ctx, result); err != nil {
+		log.Printf("Couldn't record OPML import item %s: %v", item.ID, err)
+	}
+}
+
+// importSubscription follows the item's feed for the importing user, creating
+// the feed from fetched if no one has added its URL yet, and stars it if the
+// item is starred. A star-only item is starred without being followed. A
+// follow that already exists is left where the user filed it.
+func importSubscription(ctx context.Context, db *database.Queries, backfillMaxPages int, item database.GetPendingOPMLImportItemsRow, fetched opmlFetch) (uuid.UUID, error) {
+	if !validImportURL(item.Url) {
+		return uuid.Nil, errors.New("not an http or https URL")
+	}
+
+	feed, err := db.GetFeedByURL(ctx, item.Url)
+	if errors.Is(err, sql.ErrNoRows) {
+		feed, err = createImportedFeed(ctx, db, backfillMaxPages, item, fetched)
+		if err != nil {
+			return uuid.Nil, err
+		}
+	} else if err != nil {
+		return uuid.Nil, errors.New("couldn't look up feed")
+	} else {
+		_, err = db.GetFeedVisibleToUser(ctx, database.GetFeedVisibleToUserParams{
+			ID:     feed.ID,
+			UserID: item.UserID,
+		})
+		if err != nil {
+			return uuid.Nil, errors.New("feed isn't available")
+		}
+	}
+
+	if item.Starred {
+		err = db.StarFeedIfUnstarred(ctx, database.StarFeedIfUnstarredParams{
+			ID:        uuid.New(),
+			UserID:    item.UserID,
+			FeedID:    feed.ID,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return uuid.Nil, errors.New("couldn't star feed")
+		}
+	}
+	if item.StarOnly {
+		return feed.ID, nil
+	}
+
+	_, err = db.GetFeedFollowForFeed(ctx, database.GetFeedFollowForFeedParams{
+		UserID: item.UserID,
+		FeedID: feed.ID,
+	})
+	if err == nil {
+		return feed.ID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, errors.New("couldn't look up feed follow")
+	}
+
+	folderID, err := importFolder(ctx, db, item)
+	if err != nil {
+		return uuid.Nil, errors.New("couldn't create folder")
+	}
+	follow, err := db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    item.UserID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		return uuid.Nil, errors.New("couldn't follow feed")
+	}
+	if !folderID.Valid && (item.Title == "" || item.Title == feed.Name) {
+		return feed.ID, nil
+	}
+	title := follow.Title
+	if item.Title != feed.Name {
+		title = nullStringFromString(item.Title)
+	}
+	_, err = db.UpdateFeedFollow(ctx, database.UpdateFeedFollowParams{
+		ID:               follow.ID,
+		FolderID:         folderID,
+		Position:         follow.Position,
+		Title:            title,
+		HideFromTimeline: follow.HideFromTimeline,
+		FullContent:      follow.FullContent,
+		SortOrder:        follow.SortOrder,
+		UpdatedAt:        time.Now().UTC(),
+	})
+	if err != nil {
+		return uuid.Nil, errors.New("couldn't file feed follow")
+	}
+	return feed.ID, nil
+}
+
+// createImportedFeed needs the item's URL to have fetched, so a dead or
+// non-feed URL fails the item instead of adding a feed that never collects
+// anything. The fetch error is only logged: it can describe hosts the user
+// shouldn't learn about.
+func createImportedFeed(ctx context.Context, db *database.Queries, backfillMaxPages int, item database.GetPendingOPMLImportItemsRow, fetched opmlFetch) (database.Feed, error) {
+	if fetched.feed == nil && fetched.err == nil {
+		// The URL had a feed when the batch was prefetched.
+		fetched.feed, fetched.err = fetchFeed(item.Url, nil)
+	}
+	if fetched.err != nil {
+		log.Printf("Couldn't fetch imported feed %s: %v", item.Url, fetched.err)
+		return database.Feed{}, errors.New("couldn't fetch feed")
+	}
+	name := item.Title
+	if name == "" {
+		name = strings.TrimSpace(fetched.feed.Channel.Title)
+	}
+	if name == "" {
+		name = item.Url
+	}
+
+	feed, err := db.CreateFeed(ctx, database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    item.UserID,
+		Name:      name,
+		Url:       item.Url,
+		Kind:      FeedKindRSS,
+	})
+	if err != nil {
+		return database.Feed{}, errors.New("couldn't create feed")
+	}
+	if backfillMaxPages > 0 {
+		_, err = db.StartFeedBackfill(ctx, database.StartFeedBackfillParams{
+			FeedID:    feed.ID,
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			MaxPages:  int32(backfillMaxPages),
+		})
+		if err != nil {
+			log.Printf("Couldn't start backfill for imported feed %s: %v", feed.Name, err)
+		}
+	}
+	return feed, nil
+}
+
+// importFolder finds or creates the folder an item was nested in, matching
+// the user's folders by name.
+func importFolder(ctx context.Context, db *database.Queries, item database.GetPendingOPMLImportItemsRow) (uuid.NullUUID, error) {
+	if !item.Folder.Valid {
+		return uuid.NullUUID{}, nil
+	}
+	folder, err := findOrCreateFolder(ctx, db, item.UserID, uuid.NullUUID{}, item.Folder.String)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	if item.Subfolder.Valid {
+		folder, err = findOrCreateFolder(ctx, db, item.UserID, uuid.NullUUID{UUID: folder.ID, Valid: true}, item.Subfolder.String)
+		if err != nil {
+			return uuid.NullUUID{}, err
+		}
+	}
+	return uuid.NullUUID{UUID: folder.ID, Valid: true}, nil
+}
+
+func findOrCreateFolder(ctx context.Context, db *database.Queries, userID uuid.UUID, parentID uuid.NullUUID, name string) (database.Folder, error) {
+	folder, err := db.GetFolderByName(ctx, database.GetFolderByNameParams{
+		UserID:   userID,
+		ParentID: parentID,
+		Name:     name,
+	})
+	if !errors.Is(err, sql.ErrNoRows) {
+		return folder, err
+	}
+	return db.CreateFolder(ctx, database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    userID,
+		ParentID:  parentID,
+		Name:      name,
+	})
+}
+
+// opmlExport is an OPML 2.0 document as written by the export.
+type opmlExport struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []opmlExportOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type opmlExportOutline struct {
+	Text     string              `xml:"text,attr"`
+	Title    string              `xml:"title,attr,omitempty"`
+	Type     string              `xml:"type,attr,omitempty"`
+	XMLURL   string              `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string              `xml:"htmlUrl,attr,omitempty"`
+	Category string              `xml:"category,attr,omitempty"`
+	Outlines []opmlExportOutline `xml:"outline"`
+}
+
+// exportableFeedKind reports whether another reader could subscribe to a
+// feed of this kind by URL. Newsletters have no URL, and scraped and
+// monitored pages are HTML that only this server turns into posts.
+func exportableFeedKind(kind string) bool {
+	return kind == FeedKindRSS
+}
+
+func feedOutline(title, feedURL string, siteURL sql.NullString, category string) opmlExportOutline {
+	outline := opmlExportOutline{Text: title, Title: title, Type: "rss", XMLURL: feedURL, Category: category}
+	if siteURL.Valid {
+		outline.HTMLURL = siteURL.String
+	}
+	return outline
+}
+
+// buildOPMLExport lays out followed feeds in their folders, each folder
+// holding its subfolders before its own feeds, followed by the unfiled
+// feeds. Only feeds of an exportable kind are written. starred feeds get the
+// opmlStarredCategory category; the ones the user doesn't follow are added
+// unfiled, with opmlStarOnlyCategory instead.
+func buildOPMLExport(title string, created time.Time, folders []database.Folder, follows []database.GetFollowedFeedsForExportRow, starred []database.Feed) opmlExport {
+	doc := opmlExport{Version: "2.0"}
+	doc.Head.Title = title
+	doc.Head.DateCreated = created.UTC().Format(time.RFC1123Z)
+
+	isStarred := make(map[uuid.UUID]bool, len(starred))
+	for _, feed := range starred {
+		isStarred[feed.ID] = true
+	}
+	followed := make(map[uuid.UUID]bool, len(follows))
+
+	feedsIn := map[uuid.UUID][]opmlExportOutline{}
+	var unfiled []opmlExportOutline
+	for _, follow := range follows {
+		followed[follow.FeedID] = true
+		if !exportableFeedKind(follow.Kind) {
+			continue
+		}
+		name := follow.Name
+		if follow.Title.Valid {
+			name = follow.Title.String
+		}
+		var category string
+		if isStarred[follow.FeedID] {
+			category = opmlStarredCategory
+		}
+		outline := feedOutline(name, follow.Url, follow.SiteUrl, category)
+		if follow.FolderID.Valid {
+			feedsIn[follow.FolderID.UUID] = append(feedsIn[follow.FolderID.UUID], outline)
+		} else {
+			unfiled = append(unfiled, outline)
+		}
+	}
+
+	folderOutline := func(folder database.Folder) opmlExportOutline {
+		return opmlExportOutline{Text: folder.Name, Title: folder.Name}
+	}
+	var top []opmlExportOutline
+	for _, folder := range folders {
+		if folder.ParentID.Valid {
+			continue
+		}
+		outline := folderOutline(folder)
+		for _, sub := range folders {
+			if sub.ParentID.Valid && sub.ParentID.UUID == folder.ID {
+				subOutline := folderOutline(sub)
+				subOutline.Outlines = feedsIn[sub.ID]
+				outline.Outlines = append(outline.Outlines, subOutline)
+			}
+		}
+		outline.Outlines = append(outline.Outlines, feedsIn[folder.ID]...)
+		top = append(top, outline)
+	}
+	for _, feed := range starred {
+		if followed[feed.ID] || !exportableFeedKind(feed.Kind) || feed.DeletedAt.Valid {
+			continue
+		}
+		unfiled = append(unfiled, feedOutline(feed.Name, feed.Url, feed.SiteUrl, opmlStarOnlyCategory))
+	}
+	doc.Body.Outlines = append(top, unfiled...)
+	return doc
+}
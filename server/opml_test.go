@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestParseOPML(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Loose" type="rss" xmlUrl="https://example.com/loose.xml" htmlUrl="https://example.com/"/>
+    <outline text="Tech" title="Tech">
+      <outline text="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+      <outline text="Databases">
+        <outline title="Postgres" text="ignored" xmlUrl="https://postgres.example/rss"/>
+        <outline text="Deeper">
+          <outline text="Deep" xmlUrl="https://deep.example/rss"/>
+        </outline>
+      </outline>
+    </outline>
+    <outline text="Dupe" xmlUrl="https://example.com/loose.xml"/>
+  </body>
+</opml>`
+	got, err := parseOPML([]byte(doc))
+	if err != nil {
+		t.Fatalf("parseOPML: %v", err)
+	}
+	want := []opmlSubscription{
+		{URL: "https://example.com/loose.xml", Title: "Loose"},
+		{URL: "https://go.dev/blog/feed.atom", Title: "Go Blog", Folder: "Tech"},
+		{URL: "https://postgres.example/rss", Title: "Postgres", Folder: "Tech", Subfolder: "Databases"},
+		{URL: "https://deep.example/rss", Title: "Deep", Folder: "Tech", Subfolder: "Databases"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseOPML =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseOPMLVersion1(t *testing.T) {
+	doc := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+		"<opml version=\"1.0\"><body><outline text=\"Caf\xe9\" xmlURL=\"http://cafe.example/rss\"/></body></opml>"
+	got, err := parseOPML([]byte(doc))
+	if err != nil {
+		t.Fatalf("parseOPML: %v", err)
+	}
+	want := []opmlSubscription{{URL: "http://cafe.example/rss", Title: "Café"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseOPML = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseOPMLErrors(t *testing.T) {
+	if _, err := parseOPML([]byte(`<opml version="2.0"><body><outline text="Empty"/></body></opml>`)); !errors.Is(err, errNoSubscriptions) {
+		t.Errorf("empty document: got %v, want errNoSubscriptions", err)
+	}
+	if _, err := parseOPML([]byte(`<rss><channel/></rss>`)); err == nil || errors.Is(err, errNoSubscriptions) {
+		t.Errorf("non-OPML document: got %v, want a parse error", err)
+	}
+}
+
+func TestOPMLExportRoundTrip(t *testing.T) {
+	tech := database.Folder{ID: uuid.New(), Name: "Tech & Science"}
+	databases := database.Folder{ID: uuid.New(), Name: "Databases", ParentID: uuid.NullUUID{UUID: tech.ID, Valid: true}}
+	inTech := uuid.NullUUID{UUID: tech.ID, Valid: true}
+	inDatabases := uuid.NullUUID{UUID: databases.ID, Valid: true}
+	goBlog := uuid.New()
+	follows := []database.GetFollowedFeedsForExportRow{
+		{FeedID: goBlog, Name: "Go Blog", Url: "https://go.dev/blog/feed.atom", SiteUrl: sql.NullString{String: "https://go.dev/blog", Valid: true}, Kind: FeedKindRSS, FolderID: inTech},
+		{FeedID: uuid.New(), Name: "PG", Title: sql.NullString{String: "Postgres <news>", Valid: true}, Url: "https://postgres.example/rss?a=1&b=2", Kind: FeedKindRSS, FolderID: inDatabases},
+		{FeedID: uuid.New(), Name: "Loose", Url: "https://example.com/feed", Kind: FeedKindRSS},
+		{FeedID: uuid.New(), Name: "Scraped", Url: "https://example.com/page", Kind: FeedKindScrapedPage},
+		{FeedID: uuid.New(), Name: "Watched", Url: "https://example.com/pricing", Kind: FeedKindMonitoredPage},
+		{FeedID: uuid.New(), Name: "Letters", Url: "newsletter:abc", Kind: FeedKindNewsletter},
+	}
+	starred := []database.Feed{
+		{ID: goBlog, Name: "Go Blog", Url: "https://go.dev/blog/feed.atom", Kind: FeedKindRSS},
+		{ID: uuid.New(), Name: "Starry", Url: "https://stars.example/rss", Kind: FeedKindRSS},
+		{ID: uuid.New(), Name: "Starred page", Url: "https://stars.example/page", Kind: FeedKindScrapedPage},
+	}
+
+	doc := buildOPMLExport("Subscriptions", time.Now(), []database.Folder{tech, databases}, follows, starred)
+	out, err := xml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("xml.Marshal: %v", err)
+	}
+
+	got, err := parseOPML(out)
+	if err != nil {
+		t.Fatalf("parseOPML: %v", err)
+	}
+	want := []opmlSubscription{
+		{URL: "https://postgres.example/rss?a=1&b=2", Title: "Postgres <news>", Folder: "Tech & Science", Subfolder: "Databases"},
+		{URL: "https://go.dev/blog/feed.atom", Title: "Go Blog", Folder: "Tech & Science", Starred: true},
+		{URL: "https://example.com/feed", Title: "Loose"},
+		{URL: "https://stars.example/rss", Title: "Starry", Starred: true, StarOnly: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestOPMLExportImportRoundTrip(t *testing.T) {
+	user := uuid.New()
+	loose := database.Feed{ID: uuid.New(), Name: "Loose", Url: "https://example.com/feed", Kind: FeedKindRSS}
+	followedStar := database.Feed{ID: uuid.New(), Name: "Followed star", Url: "https://example.com/star", Kind: FeedKindRSS}
+	starOnly := database.Feed{ID: uuid.New(), Name: "Starry", Url: "https://stars.example/rss", Kind: FeedKindRSS}
+	follows := []database.GetFollowedFeedsForExportRow{
+		{FeedID: loose.ID, Name: loose.Name, Url: loose.Url, Kind: FeedKindRSS},
+		{FeedID: followedStar.ID, Name: followedStar.Name, Url: followedStar.Url, Kind: FeedKindRSS},
+	}
+	out, err := xml.Marshal(buildOPMLExport("Subscriptions", time.Now(), nil, follows, []database.Feed{followedStar, starOnly}))
+	if err != nil {
+		t.Fatalf("xml.Marshal: %v", err)
+	}
+	subscriptions, err := parseOPML(out)
+	if err != nil {
+		t.Fatalf("parseOPML: %v", err)
+	}
+
+	byURL := map[string]database.Feed{loose.Url: loose, followedStar.Url: followedStar, starOnly.Url: starOnly}
+	followed := map[uuid.UUID]bool{}
+	starred := map[uuid.UUID]bool{}
+	for _, subscription := range subscriptions {
+		// The importing user has none of the feeds yet.
+		cfg, db := newTestConfig(t)
+		feed := byURL[subscription.URL]
+		db.returns("GetFeedByURL", feed)
+		db.returns("GetFeedVisibleToUser", feed)
+		db.affects("StarFeedIfUnstarred", 1)
+		db.returns("GetFeedFollowForFeed")
+		db.on("CreateFeedFollow", func(args []driver.Value) fakeResult {
+			return fakeResult{Rows: [][]driver.Value{fakeRow(database.FeedFollow{ID: argUUID(t, args[0]), UserID: user, FeedID: feed.ID})}}
+		})
+
+		item := database.GetPendingOPMLImportItemsRow{ID: uuid.New(), Url: subscription.URL, Title: subscription.Title, Starred: subscription.Starred, StarOnly: subscription.StarOnly, UserID: user}
+		if _, err := importSubscription(context.Background(), cfg.DB, 0, item, opmlFetch{}); err != nil {
+			t.Fatalf("%s: %v", subscription.URL, err)
+		}
+		followed[feed.ID] = len(db.called("CreateFeedFollow")) == 1
+		starred[feed.ID] = len(db.called("StarFeedIfUnstarred")) == 1
+	}
+
+	want := []struct {
+		feed              database.Feed
+		followed, starred bool
+	}{
+		{loose, true, false},
+		{followedStar, true, true},
+		{starOnly, false, true},
+	}
+	for _, w := range want {
+		if followed[w.feed.ID] != w.followed || starred[w.feed.ID] != w.starred {
+			t.Errorf("%s: followed %v, starred %v; want %v, %v", w.feed.Name, followed[w.feed.ID], starred[w.feed.ID], w.followed, w.starred)
+		}
+	}
+}
+
+func TestParseOPMLCategories(t *testing.T) {
+	doc := `<opml version="2.0"><body>
+		<outline text="A" xmlUrl="https://a.example/rss" category="/Tech, /starred"/>
+		<outline text="B" xmlUrl="https://b.example/rss" category="/Tech/starred-things"/>
+		<outline text="C" xmlUrl="https://c.example/rss" category="/starred/unfollowed"/>
+	</body></opml>`
+	got, err := parseOPML([]byte(doc))
+	if err != nil {
+		t.Fatalf("parseOPML: %v", err)
+	}
+	if len(got) != 3 || !got[0].Starred || got[0].StarOnly || got[1].Starred || !got[2].Starred || !got[2].StarOnly {
+		t.Errorf("unexpected starred flags: %+v", got)
+	}
+}
+
+func TestValidImportURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com/feed.xml": true,
+		"http://example.com/rss":       true,
+		"file:///etc/passwd":           false,
+		"gopher://example.com/":        false,
+		"https:///feed":                false,
+		"not a url":                    false,
+	}
+	for rawURL, want := range tests {
+		if got := validImportURL(rawURL); got != want {
+			t.Errorf("validImportURL(%q) = %v, want %v", rawURL, got, want)
+		}
+	}
+}
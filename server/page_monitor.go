@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+	"golang.org/x/net/html"
+)
+
+const maxDiffLines = 200
+
+// pageSnapshot is a monitored page's normalised text as of ChangedAt.
+type pageSnapshot struct {
+	Text      string
+	Hash      string
+	ChangedAt time.Time
+}
+
+// checkMonitoredPage compares the page's normalised text with the last
+// snapshot. When the text changed it returns the new snapshot, for the
+// caller to store once the change is saved, and one item describing the
+// change; there's no item for the first snapshot taken.
+func checkMonitoredPage(db *database.Queries, feed database.Feed, creds *FeedCredentials) ([]RSSItem, *pageSnapshot, error) {
+	monitor, err := db.GetPageMonitor(context.Background(), feed.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't load page monitor: %w", err)
+	}
+	page, err := fetchURL(feed.Url, creds)
+	if err != nil {
+		return nil, nil, err
+	}
+	text, err := extractMonitoredText(page, monitor.Selector.String)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sum := sha256.Sum256([]byte(text))
+	hash := hex.EncodeToString(sum[:])
+	if monitor.ContentHash.Valid && monitor.ContentHash.String == hash {
+		return nil, nil, nil
+	}
+
+	now := time.Now().UTC()
+	snapshot := &pageSnapshot{Text: text, Hash: hash, ChangedAt: now}
+	if !monitor.ContentHash.Valid {
+		return nil, snapshot, nil
+	}
+
+	diff := diffLines(strings.Split(monitor.Content.String, "\n"), strings.Split(text, "\n"))
+	added, removed := 0, 0
+	for _, line := range diff {
+		switch line.Op {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+
+	// Each change needs its own URL, since posts are unique on url.
+	link, err := url.Parse(feed.Url)
+	if err != nil {
+		return nil, nil, err
+	}
+	link.Fragment = "changed-" + now.Format("20060102T150405Z")
+
+	return []RSSItem{{
+		Title:       fmt.Sprintf("%s changed (+%d/-%d lines)", feed.Name, added, removed),
+		Link:        link.String(),
+		Description: formatDiff(diff, 1),
+		PubDate:     now.Format(time.RFC1123Z),
+	}}, snapshot, nil
+}
+
+func storePageSnapshot(db *database.Queries, feedID uuid.UUID, snapshot pageSnapshot) error {
+	return db.UpdatePageMonitorContent(context.Background(), database.UpdatePageMonitorContentParams{
+		FeedID:      feedID,
+		Content:     sql.NullString{String: snapshot.Text, Valid: true},
+		ContentHash: sql.NullString{String: snapshot.Hash, Valid: true},
+		ChangedAt:   sql.NullTime{Time: snapshot.ChangedAt, Valid: true},
+	})
+}
+
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// extractMonitoredText renders the page (or the part matched by selector) as
+// one line per block of text, so cosmetic markup changes don't count as edits.
+func extractMonitoredText(page []byte, selector string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return "", err
+	}
+	selection := doc.Find("body")
+	if selector != "" {
+		selection = doc.Find(selector)
+	}
+
+	var buf strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			// Only markup decides where lines break.
+			buf.WriteString(strings.NewReplacer("\r", " ", "\n", " ", "\t", " ").Replace(n.Data))
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript", "template":
+				return
+			}
+		}
+		block := n.Type == html.ElementNode && blockElements[n.Data]
+		if block {
+			buf.WriteString("\n")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if block {
+			buf.WriteString("\n")
+		}
+	}
+	for _, n := range selection.Nodes {
+		walk(n)
+		buf.WriteString("\n")
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line = collapseWhitespace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+type diffLine struct {
+	Op   byte // ' ', '+' or '-'
+	Text string
+}
+
+// diffLines is a plain longest-common-subsequence line diff. Very large pages
+// fall back to listing every old line as removed and every new one as added.
+func diffLines(before, after []string) []diffLine {
+	if len(before)*len(after) > 4_000_000 {
+		diff := make([]diffLine, 0, len(before)+len(after))
+		for _, line := range before {
+			diff = append(diff, diffLine{'-', line})
+		}
+		for _, line := range after {
+			diff = append(diff, diffLine{'+', line})
+		}
+		return diff
+	}
+
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := []diffLine{}
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			diff = append(diff, diffLine{' ', before[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, diffLine{'-', before[i]})
+			i++
+		default:
+			diff = append(diff, diffLine{'+', after[j]})
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		diff = append(diff, diffLine{'-', before[i]})
+	}
+	for ; j < len(after); j++ {
+		diff = append(diff, diffLine{'+', after[j]})
+	}
+	return diff
+}
+
+// formatDiff renders changed lines with a little surrounding context, eliding
+// unchanged runs with "...".
+func formatDiff(diff []diffLine, contextLines int) string {
+	keep := make([]bool, len(diff))
+	for i, line := range diff {
+		if line.Op == ' ' {
+			continue
+		}
+		for k := max(0, i-contextLines); k <= min(len(diff)-1, i+contextLines); k++ {
+			keep[k] = true
+		}
+	}
+
+	var buf strings.Builder
+	written, skipped := 0, false
+	for i, line := range diff {
+		if !keep[i] {
+			skipped = true
+			continue
+		}
+		if written == maxDiffLines {
+			buf.WriteString("... diff truncated\n")
+			break
+		}
+		if skipped && written > 0 {
+			buf.WriteString("...\n")
+		}
+		skipped = false
+		buf.WriteByte(line.Op)
+		buf.WriteByte(' ')
+		buf.WriteString(line.Text)
+		buf.WriteByte('\n')
+		written++
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestExtractMonitoredText(t *testing.T) {
+	page := []byte(`<html><body>
+		<nav>Home | Pricing</nav>
+		<div id="plans"><h2>Pro</h2><p>$10   per
+		month</p><script>track()</script></div>
+	</body></html>`)
+
+	text, err := extractMonitoredText(page, "#plans")
+	if err != nil {
+		t.Fatalf("extractMonitoredText: %v", err)
+	}
+	if text != "Pro\n$10 per month" {
+		t.Errorf("Unexpected normalised text: %q", text)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	diff := diffLines(
+		[]string{"a", "b", "c", "d"},
+		[]string{"a", "c", "d", "e"},
+	)
+	got := formatDiff(diff, 0)
+	want := "- b\n...\n+ e"
+	if got != want {
+		t.Errorf("Expected diff %q, got %q", want, got)
+	}
+}
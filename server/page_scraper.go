@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/andybalholm/cascadia"
+)
+
+// PageSelectors describe how to carve posts out of an HTML page that has no
+// feed. Title, link, date and summary are matched inside each item element.
+type PageSelectors struct {
+	Item    string `json:"item"`
+	Title   string `json:"title"`
+	Link    string `json:"link"`
+	Date    string `json:"date,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+func (s PageSelectors) validate() error {
+	if s.Item == "" || s.Title == "" || s.Link == "" {
+		return errors.New("item, title and link selectors are required")
+	}
+	for _, selector := range []string{s.Item, s.Title, s.Link, s.Date, s.Summary} {
+		if selector == "" {
+			continue
+		}
+		if _, err := cascadia.Compile(selector); err != nil {
+			return fmt.Errorf("invalid selector %q: %v", selector, err)
+		}
+	}
+	return nil
+}
+
+func databaseFeedSelectorToPageSelectors(stored database.FeedSelector) PageSelectors {
+	return PageSelectors{
+		Item:    stored.ItemSelector,
+		Title:   stored.TitleSelector,
+		Link:    stored.LinkSelector,
+		Date:    stored.DateSelector.String,
+		Summary: stored.SummarySelector.String,
+	}
+}
+
+func scrapePage(pageURL string, selectors PageSelectors, creds *FeedCredentials) ([]RSSItem, error) {
+	page, err := fetchURL(pageURL, creds)
+	if err != nil {
+		return nil, err
+	}
+	return extractPageItems(pageURL, page, selectors)
+}
+
+// extractPageItems skips elements without a title or link, since posts are
+// deduplicated on their URL, and links that aren't http or https, such as
+// javascript: and data: ones.
+func extractPageItems(pageURL string, page []byte, selectors PageSelectors) ([]RSSItem, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+
+	items := []RSSItem{}
+	doc.Find(selectors.Item).Each(func(_ int, element *goquery.Selection) {
+		title := collapseWhitespace(element.Find(selectors.Title).First().Text())
+		href := selectHref(element.Find(selectors.Link).First())
+		if title == "" || href == "" {
+			return
+		}
+		link, err := base.Parse(href)
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+			return
+		}
+
+		item := RSSItem{
+			Title: title,
+			Link:  link.String(),
+		}
+		if selectors.Date != "" {
+			item.PubDate = selectDate(element.Find(selectors.Date).First())
+		}
+		if selectors.Summary != "" {
+			item.Description = collapseWhitespace(element.Find(selectors.Summary).First().Text())
+		}
+		items = append(items, item)
+	})
+	return items, nil
+}
+
+// selectHref accepts either the anchor itself or an element wrapping one.
+func selectHref(selection *goquery.Selection) string {
+	if href, ok := selection.Attr("href"); ok {
+		return strings.TrimSpace(href)
+	}
+	href, _ := selection.Find("a[href]").First().Attr("href")
+	return strings.TrimSpace(href)
+}
+
+// selectDate prefers a machine-readable <time datetime="..."> attribute.
+func selectDate(selection *goquery.Selection) string {
+	if datetime, ok := selection.Attr("datetime"); ok {
+		return strings.TrimSpace(datetime)
+	}
+	return collapseWhitespace(selection.Text())
+}
+
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
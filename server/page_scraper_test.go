@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestExtractPageItems(t *testing.T) {
+	page := []byte(`
+		<html><body>
+			<article class="post">
+				<h2><a href="/blog/first">First   post</a></h2>
+				<time datetime="2024-03-01T10:00:00Z">March 1</time>
+				<p class="lede">Something happened.</p>
+			</article>
+			<article class="post">
+				<h2>No link here</h2>
+			</article>
+			<article class="post">
+				<h2><a href="https://other.example.com/second">Second post</a></h2>
+			</article>
+			<article class="post">
+				<h2><a href="javascript:alert(1)">Script</a></h2>
+			</article>
+			<article class="post">
+				<h2><a href=" DATA:text/html,hi">Data</a></h2>
+			</article>
+			<article class="post">
+				<h2><a href="mailto:me@example.com">Mail</a></h2>
+			</article>
+		</body></html>`)
+
+	items, err := extractPageItems("https://example.com/blog/", page, PageSelectors{
+		Item:    "article.post",
+		Title:   "h2",
+		Link:    "h2",
+		Date:    "time",
+		Summary: ".lede",
+	})
+	if err != nil {
+		t.Fatalf("extractPageItems: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	first := items[0]
+	if first.Title != "First post" {
+		t.Errorf("Expected collapsed title 'First post', got '%s'", first.Title)
+	}
+	if first.Link != "https://example.com/blog/first" {
+		t.Errorf("Expected relative link to be resolved, got '%s'", first.Link)
+	}
+	if !parsePublishedAt(first.PubDate).Valid {
+		t.Errorf("Expected datetime attribute to parse, got '%s'", first.PubDate)
+	}
+	if first.Description != "Something happened." {
+		t.Errorf("Expected summary text, got '%s'", first.Description)
+	}
+	if items[1].Link != "https://other.example.com/second" {
+		t.Errorf("Expected absolute link to be kept, got '%s'", items[1].Link)
+	}
+}
+
+func TestPageSelectorsValidate(t *testing.T) {
+	if err := (PageSelectors{Item: "li", Title: "a"}).validate(); err == nil {
+		t.Error("Expected missing link selector to be rejected")
+	}
+	if err := (PageSelectors{Item: "li[", Title: "a", Link: "a"}).validate(); err == nil {
+		t.Error("Expected invalid selector to be rejected")
+	}
+}
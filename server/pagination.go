@@ -0,0 +1,135 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// PostPage is one page of a post list. NextCursor is null on the last page.
+type PostPage[T any] struct {
+	Posts      []T     `json:"posts"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// PageParams are a list request's limit and, past the first page, the sort
+// key of the last item already seen.
+type PageParams struct {
+	Limit      int
+	CursorTime sql.NullTime
+	CursorID   uuid.NullUUID
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// parsePageParams reads the limit and cursor query parameters.
+func parsePageParams(r *http.Request) (PageParams, error) {
+	params := PageParams{Limit: parsePageSize(r)}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		sortTime, id, err := decodeTimeCursor(cursor)
+		if err != nil {
+			return PageParams{}, err
+		}
+		params.CursorTime = sql.NullTime{Time: sortTime, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	return params, nil
+}
+
+// parsePageSize reads the limit query parameter, capped at maxPageSize.
+func parsePageSize(r *http.Request) int {
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			return min(parsedLimit, maxPageSize)
+		}
+	}
+	return defaultPageSize
+}
+
+// queryLimit fetches one item more than the page holds, to tell whether
+// there is a next page.
+func (p PageParams) queryLimit() int32 {
+	return int32(p.Limit + 1)
+}
+
+// trimPage drops the extra post fetched by queryLimit and returns the cursor
+// for the following page, if there is one.
+func (p PageParams) trimPage(posts []database.Post) ([]database.Post, *string) {
+	return trimPage(posts, p.Limit, encodePostCursor)
+}
+
+// trimPage cuts items fetched with one extra down to limit, returning the
+// cursor of the last item kept when there is more to come.
+func trimPage[T any](items []T, limit int, cursorOf func(T) string) ([]T, *string) {
+	if len(items) <= limit {
+		return items, nil
+	}
+	items = items[:limit]
+	cursor := cursorOf(items[len(items)-1])
+	return items, &cursor
+}
+
+// postSortTime is the time lists sort on: published_at, or created_at for
+// posts the source didn't date.
+func postSortTime(post database.Post) time.Time {
+	if post.PublishedAt.Valid {
+		return post.PublishedAt.Time
+	}
+	return post.CreatedAt
+}
+
+func encodePostCursor(post database.Post) string {
+	return encodeTimeCursor(postSortTime(post), post.ID)
+}
+
+func encodeTimeCursor(t time.Time, id uuid.UUID) string {
+	return encodeCursor(strconv.FormatInt(t.UnixNano(), 10), id)
+}
+
+func decodeTimeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	key, id, err := decodeCursor(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	nanos, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.Nil, errInvalidCursor
+	}
+	return time.Unix(0, nanos).UTC(), id, nil
+}
+
+// encodeCursor makes an opaque cursor from a list's sort key and the id that
+// breaks ties on it.
+func encodeCursor(key string, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key + "." + id.String()))
+}
+
+func decodeCursor(cursor string) (string, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", uuid.Nil, errInvalidCursor
+	}
+	// Keys may contain dots themselves; ids never do.
+	sep := strings.LastIndex(string(raw), ".")
+	if sep < 0 {
+		return "", uuid.Nil, errInvalidCursor
+	}
+	key := string(raw[:sep])
+	id, err := uuid.Parse(string(raw[sep+1:]))
+	if err != nil {
+		return "", uuid.Nil, errInvalidCursor
+	}
+	return key, id, nil
+}
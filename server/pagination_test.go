@@ -0,0 +1,51 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestPostCursorRoundTrip(t *testing.T) {
+	post := database.Post{
+		ID:          uuid.New(),
+		CreatedAt:   time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+		PublishedAt: sql.NullTime{Time: time.Date(2024, 3, 1, 10, 30, 0, 123456000, time.UTC), Valid: true},
+	}
+
+	sortTime, id, err := decodeTimeCursor(encodePostCursor(post))
+	if err != nil {
+		t.Fatalf("decodeTimeCursor: %v", err)
+	}
+	if !sortTime.Equal(post.PublishedAt.Time) || id != post.ID {
+		t.Errorf("Expected (%v, %v), got (%v, %v)", post.PublishedAt.Time, post.ID, sortTime, id)
+	}
+
+	for _, cursor := range []string{"not base64!", "bm8tZG90", "MTIz.bm90LWEtdXVpZA"} {
+		if _, _, err := decodeTimeCursor(cursor); err == nil {
+			t.Errorf("Expected cursor %q to be rejected", cursor)
+		}
+	}
+}
+
+func TestTrimPage(t *testing.T) {
+	posts := make([]database.Post, 3)
+	for i := range posts {
+		posts[i] = database.Post{ID: uuid.New(), CreatedAt: time.Now()}
+	}
+
+	page, next := PageParams{Limit: 2}.trimPage(posts)
+	if len(page) != 2 || next == nil {
+		t.Fatalf("Expected a full page with a next cursor, got %d posts and %v", len(page), next)
+	}
+	if _, id, _ := decodeTimeCursor(*next); id != posts[1].ID {
+		t.Errorf("Expected the cursor to point at the last post on the page")
+	}
+
+	if _, next := (PageParams{Limit: 3}).trimPage(posts); next != nil {
+		t.Error("Expected no next cursor on the last page")
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+// ItemContext is what a processor may know about where an item came from.
+type ItemContext struct {
+	Feed           database.Feed
+	BaseURL        *url.URL // the channel link, for resolving relative links
+	TrackingParams []string
+	// IsStored reports whether the feed already has a post for a link. It is
+	// nil outside the scraper.
+	IsStored func(link string) bool
+}
+
+// ItemProcessor transforms items between parsing and CreatePost. It edits the
+// item in place and returns false to drop it.
+type ItemProcessor interface {
+	Process(ctx ItemContext, item *RSSItem) (bool, error)
+}
+
+// ProcessorChain runs processors in order. A processor that fails is skipped
+// for that item rather than losing the item.
+type ProcessorChain []ItemProcessor
+
+func (c ProcessorChain) Run(ctx ItemContext, items []RSSItem) []RSSItem {
+	kept := make([]RSSItem, 0, len(items))
+	for _, item := range items {
+		keep := true
+		for _, processor := range c {
+			processed := item
+			ok, err := processor.Process(ctx, &processed)
+			if err != nil {
+				log.Printf("Item processor %T failed on %q in feed %s: %v", processor, item.Link, ctx.Feed.Name, err)
+				continue
+			}
+			if !ok {
+				keep = false
+				break
+			}
+			item = processed
+		}
+		if keep {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// ProcessorConfig is how processors are configured, both globally and per
+// feed, e.g. {"type": "drop_matching", "options": {"field": "title", "pattern": "(?i)sponsored"}}.
+type ProcessorConfig struct {
+	Type    string          `json:"type"`
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+var itemProcessors = map[string]func(options json.RawMessage) (ItemProcessor, error){
+	"trim_whitespace":      newTrimWhitespaceProcessor,
+	"drop_matching":        newDropMatchingProcessor,
+	"rewrite_title":        newRewriteTitleProcessor,
+	"truncate_description": newTruncateDescriptionProcessor,
+	"full_content":         newFullContentProcessor,
+}
+
+func buildProcessorChain(configs []ProcessorConfig) (ProcessorChain, error) {
+	chain := make(ProcessorChain, 0, len(configs))
+	for i, config := range configs {
+		factory, ok := itemProcessors[config.Type]
+		if !ok {
+			return nil, fmt.Errorf("processor %d: unknown type %q", i, config.Type)
+		}
+		processor, err := factory(config.Options)
+		if err != nil {
+			return nil, fmt.Errorf("processor %d (%s): %v", i, config.Type, err)
+		}
+		chain = append(chain, processor)
+	}
+	return chain, nil
+}
+
+// parseProcessorConfigs reads a JSON array of processor configs and checks
+// that it builds.
+func parseProcessorConfigs(raw []byte) ([]ProcessorConfig, error) {
+	var configs []ProcessorConfig
+	if err := json.Unmarshal(raw, &configs); err != nil {
+		return nil, err
+	}
+	if _, err := buildProcessorChain(configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// ItemPipeline is the server-wide configuration of item processing.
+// Retention is the global retention policy, which feeds may override.
+type ItemPipeline struct {
+	Processors     []ProcessorConfig
+	TrackingParams []string
+	Retention      RetentionPolicy
+}
+
+// process drops items the feed's retention policy would prune, canonicalises
+// item links, then runs the global and the feed's own processors. baseURL is
+// the link of the channel the items came from.
+func (p ItemPipeline) process(db *database.Queries, feed database.Feed, baseURL string, items []RSSItem) ([]RSSItem, error) {
+	cutoff, err := retentionCutoff(context.Background(), db, p.Retention, feed.ID)
+	if err != nil {
+		return nil, err
+	}
+	items = dropItemsOlderThan(items, cutoff)
+
+	chain, err := loadProcessorChain(context.Background(), db, p.Processors, feed.ID)
+	if err != nil {
+		return nil, err
+	}
+	chain = append(ProcessorChain{canonicalizeItems{trackingParams: p.TrackingParams}}, chain...)
+
+	ctx := ItemContext{
+		Feed:           feed,
+		TrackingParams: p.TrackingParams,
+		IsStored: func(link string) bool {
+			exists, err := db.PostExistsForURL(context.Background(), database.PostExistsForURLParams{
+				FeedID: feed.ID,
+				Url:    link,
+			})
+			return err == nil && exists
+		},
+	}
+	if base, err := url.Parse(feed.Url); err == nil {
+		ctx.BaseURL = base
+		if channel, err := base.Parse(strings.TrimSpace(baseURL)); err == nil && channel.Host != "" {
+			ctx.BaseURL = channel
+		}
+	}
+	return chain.Run(ctx, items), nil
+}
+
+// loadProcessorChain returns the global processors followed by the feed's own.
+func loadProcessorChain(ctx context.Context, db *database.Queries, global []ProcessorConfig, feedID uuid.UUID) (ProcessorChain, error) {
+	configs := global
+	stored, err := db.GetFeedProcessors(ctx, feedID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil {
+		feedConfigs, err := parseProcessorConfigs(stored.Config)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(append([]ProcessorConfig{}, global...), feedConfigs...)
+	}
+	return buildProcessorChain(configs)
+}
+
+func decodeProcessorOptions(options json.RawMessage, v any) error {
+	if len(options) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(options)))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+type trimWhitespaceProcessor struct{}
+
+func newTrimWhitespaceProcessor(options json.RawMessage) (ItemProcessor, error) {
+	return trimWhitespaceProcessor{}, nil
+}
+
+func (trimWhitespaceProcessor) Process(ctx ItemContext, item *RSSItem) (bool, error) {
+	item.Title = collapseWhitespace(item.Title)
+	item.Link = strings.TrimSpace(item.Link)
+	item.Description = strings.TrimSpace(item.Description)
+	return true, nil
+}
+
+// dropMatchingProcessor drops items whose field matches a pattern, e.g.
+// sponsored posts.
+type dropMatchingProcessor struct {
+	field   string
+	pattern *regexp.Regexp
+}
+
+func newDropMatchingProcessor(options json.RawMessage) (ItemProcessor, error) {
+	var opts struct {
+		Field   string `json:"field"`
+		Pattern string `json:"pattern"`
+	}
+	if err := decodeProcessorOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Field == "" {
+		opts.Field = "title"
+	}
+	if _, err := itemField(&RSSItem{}, opts.Field); err != nil {
+		return nil, err
+	}
+	if opts.Pattern == "" {
+		return nil, errors.New("pattern is required")
+	}
+	pattern, err := regexp.Compile(opts.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	return dropMatchingProcessor{field: opts.Field, pattern: pattern}, nil
+}
+
+func (p dropMatchingProcessor) Process(ctx ItemContext, item *RSSItem) (bool, error) {
+	value, err := itemField(item, p.field)
+	if err != nil {
+		return true, err
+	}
+	return !p.pattern.MatchString(*value), nil
+}
+
+type rewriteTitleProcessor struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func newRewriteTitleProcessor(options json.RawMessage) (ItemProcessor, error) {
+	var opts struct {
+		Pattern     string `json:"pattern"`
+		Replacement string `json:"replacement"`
+	}
+	if err := decodeProcessorOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Pattern == "" {
+		return nil, errors.New("pattern is required")
+	}
+	pattern, err := regexp.Compile(opts.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	return rewriteTitleProcessor{pattern: pattern, replacement: opts.Replacement}, nil
+}
+
+func (p rewriteTitleProcessor) Process(ctx ItemContext, item *RSSItem) (bool, error) {
+	item.Title = strings.TrimSpace(p.pattern.ReplaceAllString(item.Title, p.replacement))
+	return true, nil
+}
+
+type truncateDescriptionProcessor struct {
+	maxLength int
+}
+
+func newTruncateDescriptionProcessor(options json.RawMessage) (ItemProcessor, error) {
+	var opts struct {
+		MaxLength int `json:"max_length"`
+	}
+	if err := decodeProcessorOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.MaxLength <= 0 {
+		return nil, errors.New("max_length must be positive")
+	}
+	return truncateDescriptionProcessor{maxLength: opts.MaxLength}, nil
+}
+
+func (p truncateDescriptionProcessor) Process(ctx ItemContext, item *RSSItem) (bool, error) {
+	if utf8.RuneCountInString(item.Description) <= p.maxLength {
+		return true, nil
+	}
+	runes := []rune(item.Description)
+	item.Description = string(runes[:p.maxLength]) + "…"
+	return true, nil
+}
+
+func itemField(item *RSSItem, field string) (*string, error) {
+	switch field {
+	case "title":
+		return &item.Title, nil
+	case "link":
+		return &item.Link, nil
+	case "description":
+		return &item.Description, nil
+	}
+	return nil, fmt.Errorf("unknown field %q", field)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+type failingProcessor struct{}
+
+func (failingProcessor) Process(ctx ItemContext, item *RSSItem) (bool, error) {
+	item.Title = "half-finished edit"
+	return true, errors.New("boom")
+}
+
+func TestProcessorChain(t *testing.T) {
+	chain, err := buildProcessorChain([]ProcessorConfig{
+		{Type: "trim_whitespace"},
+		{Type: "drop_matching", Options: []byte(`{"field": "title", "pattern": "(?i)^sponsored"}`)},
+		{Type: "rewrite_title", Options: []byte(`{"pattern": "\\s*\\|\\s*Example Blog$", "replacement": ""}`)},
+		{Type: "truncate_description", Options: []byte(`{"max_length": 5}`)},
+	})
+	if err != nil {
+		t.Fatalf("buildProcessorChain: %v", err)
+	}
+	chain = append(chain, failingProcessor{})
+
+	items := chain.Run(ItemContext{}, []RSSItem{
+		{Title: "  First   post | Example Blog ", Link: " https://example.com/1 ", Description: "Something happened"},
+		{Title: "Sponsored: buy things", Link: "https://example.com/2"},
+		{Title: "Third", Link: "https://example.com/3", Description: "Short"},
+	})
+
+	if len(items) != 2 {
+		t.Fatalf("Expected the sponsored item to be dropped, got %d items", len(items))
+	}
+	if items[0].Title != "First post" {
+		t.Errorf("Expected processors to run in order, got title '%s'", items[0].Title)
+	}
+	if items[0].Link != "https://example.com/1" {
+		t.Errorf("Expected trimmed link, got '%s'", items[0].Link)
+	}
+	if items[0].Description != "Somet…" {
+		t.Errorf("Expected truncated description, got '%s'", items[0].Description)
+	}
+	if items[1].Title != "Third" || items[1].Description != "Short" {
+		t.Errorf("Expected a failing processor's edits to be discarded, got %+v", items[1])
+	}
+}
+
+func TestBuildProcessorChainRejectsBadConfig(t *testing.T) {
+	configs := [][]ProcessorConfig{
+		{{Type: "no_such_processor"}},
+		{{Type: "drop_matching"}},
+		{{Type: "drop_matching", Options: []byte(`{"field": "author", "pattern": "x"}`)}},
+		{{Type: "rewrite_title", Options: []byte(`{"pattern": "("}`)}},
+		{{Type: "truncate_description", Options: []byte(`{"max_len": 10}`)}},
+	}
+	for _, config := range configs {
+		if _, err := buildProcessorChain(config); err == nil {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+}
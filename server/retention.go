@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	PruneReasonAge   = "age"
+	PruneReasonCount = "count"
+)
+
+// pruneBatchSize bounds each DELETE so pruning a large feed never holds long
+// locks on posts.
+const pruneBatchSize = 500
+
+// RetentionPolicy limits how long and how many posts a feed keeps. Zero means
+// no limit.
+type RetentionPolicy struct {
+	MaxAgeDays int `json:"max_age_days"`
+	MaxItems   int `json:"max_items"`
+}
+
+func (p RetentionPolicy) validate() error {
+	if p.MaxAgeDays < 0 || p.MaxItems < 0 {
+		return errors.New("retention limits can't be negative")
+	}
+	return nil
+}
+
+// withOverrides applies a feed's own settings on top of the global policy.
+// A NULL setting inherits, while 0 lifts the global limit for that feed.
+func (p RetentionPolicy) withOverrides(maxAgeDays, maxItems sql.NullInt32) RetentionPolicy {
+	if maxAgeDays.Valid {
+		p.MaxAgeDays = int(maxAgeDays.Int32)
+	}
+	if maxItems.Valid {
+		p.MaxItems = int(maxItems.Int32)
+	}
+	return p
+}
+
+// retentionCutoff is the publication time before which the feed's posts are
+// pruned for age, or the zero time if they aren't. Storing items older than
+// that would only bring pruned posts back, as new and unread, until the next
+// prune.
+func retentionCutoff(ctx context.Context, db *database.Queries, global RetentionPolicy, feedID uuid.UUID) (time.Time, error) {
+	policy := global
+	stored, err := db.GetFeedRetention(ctx, feedID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, err
+	}
+	if err == nil {
+		policy = global.withOverrides(stored.MaxAgeDays, stored.MaxItems)
+	}
+	if policy.MaxAgeDays <= 0 {
+		return time.Time{}, nil
+	}
+	// Starred feeds are never pruned.
+	starred, err := db.FeedHasStars(ctx, feedID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if starred {
+		return time.Time{}, nil
+	}
+	return time.Now().UTC().AddDate(0, 0, -policy.MaxAgeDays), nil
+}
+
+// dropItemsOlderThan drops the items published before cutoff. Items without
+// a date are kept, since they're stored as published when collected.
+func dropItemsOlderThan(items []RSSItem, cutoff time.Time) []RSSItem {
+	if cutoff.IsZero() {
+		return items
+	}
+	kept := items[:0:0]
+	for _, item := range items {
+		published := parsePublishedAt(item.PubDate)
+		if published.Valid && published.Time.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+func startPruning(db *database.Queries, global RetentionPolicy, timeBetweenRuns time.Duration) {
+	log.Printf("Pruning posts every %s (global retention: %v days, %v items)...", timeBetweenRuns, global.MaxAgeDays, global.MaxItems)
+	ticker := time.NewTicker(timeBetweenRuns)
+
+	for ; ; <-ticker.C {
+		feeds, err := db.GetFeedRetentionPolicies(context.Background())
+		if err != nil {
+			log.Println("Couldn't get feed retention policies", err)
+			continue
+		}
+		total := 0
+		for _, feed := range feeds {
+			policy := global.withOverrides(feed.MaxAgeDays, feed.MaxItems)
+			total += pruneFeed(db, feed.ID, feed.Name, policy)
+		}
+		log.Printf("Pruning done, %v posts removed from %v feeds", total, len(feeds))
+	}
+}
+
+// pruneFeed enforces policy on one feed and records what each rule removed.
+// Posts someone has saved, labelled or added to a collection, and all posts
+// in a feed someone has starred, are never pruned.
+func pruneFeed(db *database.Queries, feedID uuid.UUID, feedName string, policy RetentionPolicy) int {
+	removed := 0
+	if policy.MaxAgeDays > 0 {
+		cutoff := time.Now().UTC().AddDate(0, 0, -policy.MaxAgeDays)
+		n := pruneInBatches(feedName, func() (int64, error) {
+			return db.PruneFeedPostsOlderThan(context.Background(), database.PruneFeedPostsOlderThanParams{
+				FeedID:    feedID,
+				Cutoff:    cutoff,
+				BatchSize: pruneBatchSize,
+			})
+		})
+		recordPrune(db, feedID, feedName, PruneReasonAge, n)
+		removed += n
+	}
+	if policy.MaxItems > 0 {
+		n := pruneInBatches(feedName, func() (int64, error) {
+			return db.PruneFeedPostsBeyondCount(context.Background(), database.PruneFeedPostsBeyondCountParams{
+				FeedID:    feedID,
+				Keep:      int32(policy.MaxItems),
+				BatchSize: pruneBatchSize,
+			})
+		})
+		recordPrune(db, feedID, feedName, PruneReasonCount, n)
+		removed += n
+	}
+	return removed
+}
+
+func pruneInBatches(feedName string, prune func() (int64, error)) int {
+	removed := 0
+	for {
+		n, err := prune()
+		if err != nil {
+			log.Printf("Couldn't prune posts of feed %s: %v", feedName, err)
+			return removed
+		}
+		removed += int(n)
+		if n < pruneBatchSize {
+			return removed
+		}
+	}
+}
+
+func recordPrune(db *database.Queries, feedID uuid.UUID, feedName, reason string, removed int) {
+	if removed == 0 {
+		return
+	}
+	log.Printf("Pruned %v posts from feed %s (%s)", removed, feedName, reason)
+	err := db.CreatePostPrune(context.Background(), database.CreatePostPruneParams{
+		ID:           uuid.New(),
+		CreatedAt:    time.Now().UTC(),
+		FeedID:       feedID,
+		Reason:       reason,
+		PostsRemoved: int32(removed),
+	})
+	if err != nil {
+		log.Printf("Couldn't record prune of feed %s: %v", feedName, err)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicyWithOverrides(t *testing.T) {
+	global := RetentionPolicy{MaxAgeDays: 90, MaxItems: 500}
+
+	if got := global.withOverrides(sql.NullInt32{}, sql.NullInt32{}); got != global {
+		t.Errorf("Expected NULL overrides to inherit %+v, got %+v", global, got)
+	}
+
+	got := global.withOverrides(sql.NullInt32{Int32: 0, Valid: true}, sql.NullInt32{Int32: 50, Valid: true})
+	if got.MaxAgeDays != 0 || got.MaxItems != 50 {
+		t.Errorf("Expected 0 to lift the age limit and 50 to replace the item limit, got %+v", got)
+	}
+
+	if err := (RetentionPolicy{MaxItems: -1}).validate(); err == nil {
+		t.Error("Expected a negative limit to be rejected")
+	}
+}
+
+func TestDropItemsOlderThan(t *testing.T) {
+	cutoff := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	items := []RSSItem{
+		{Title: "old", PubDate: cutoff.Add(-time.Hour).Format(time.RFC1123Z)},
+		{Title: "at cutoff", PubDate: cutoff.Format(time.RFC1123Z)},
+		{Title: "new", PubDate: cutoff.AddDate(0, 0, 1).Format(time.RFC3339)},
+		{Title: "undated"},
+		{Title: "unparseable", PubDate: "last Tuesday"},
+	}
+
+	tests := []struct {
+		name   string
+		cutoff time.Time
+		want   []string
+	}{
+		{"no cutoff", time.Time{}, []string{"old", "at cutoff", "new", "undated", "unparseable"}},
+		{"cutoff", cutoff, []string{"at cutoff", "new", "undated", "unparseable"}},
+		{"everything dated is old", cutoff.AddDate(1, 0, 0), []string{"undated", "unparseable"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, item := range dropItemsOlderThan(items, tt.cutoff) {
+			got = append(got, item.Title)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+	if len(items) != 5 || items[0].Title != "old" {
+		t.Errorf("dropItemsOlderThan changed its input: %v", items)
+	}
+}
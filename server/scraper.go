@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/xml"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -12,10 +13,18 @@ import (
 	"time"
 
 	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/Sreenesh123/rssagg/internal/secrets"
 	"github.com/google/uuid"
 )
 
-func startScraping(db *database.Queries, concurrency int, timeBetweenRequest time.Duration) {
+const (
+	FeedKindRSS           = "rss"
+	FeedKindScrapedPage   = "scraped_page"
+	FeedKindMonitoredPage = "monitored_page"
+	FeedKindNewsletter    = "newsletter"
+)
+
+func startScraping(db *database.Queries, credentials *secrets.Box, pipeline ItemPipeline, concurrency int, timeBetweenRequest time.Duration) {
 	log.Printf("Collecting feeds every %s on %v goroutines...", timeBetweenRequest, concurrency)
 	ticker := time.NewTicker(timeBetweenRequest)
 
@@ -30,13 +39,13 @@ func startScraping(db *database.Queries, concurrency int, timeBetweenRequest tim
 		wg := &sync.WaitGroup{}
 		for _, feed := range feeds {
 			wg.Add(1)
-			go scrapeFeed(db, wg, feed)
+			go scrapeFeed(db, credentials, pipeline, wg, feed)
 		}
 		wg.Wait()
 	}
 }
 
-func scrapeFeed(db *database.Queries, wg *sync.WaitGroup, feed database.Feed) {
+func scrapeFeed(db *database.Queries, credentials *secrets.Box, pipeline ItemPipeline, wg *sync.WaitGroup, feed database.Feed) {
 	defer wg.Done()
 	_, err := db.MarkFeedFetched(context.Background(), feed.ID)
 	if err != nil {
@@ -44,21 +53,50 @@ func scrapeFeed(db *database.Queries, wg *sync.WaitGroup, feed database.Feed) {
 		return
 	}
 
-	feedData, err := fetchFeed(feed.Url)
+	creds, err := loadFeedCredentials(context.Background(), db, credentials, feed.ID)
+	if err != nil {
+		log.Printf("Couldn't load credentials for feed %s: %v", feed.Name, err)
+		return
+	}
+
+	items, channelLink, snapshot, err := fetchFeedItems(db, feed, creds)
 	if err != nil {
 		log.Printf("Couldn't collect feed %s: %v", feed.Name, err)
 		return
 	}
-	for _, item := range feedData.Channel.Item {
-		publishedAt := sql.NullTime{}
-		if t, err := time.Parse(time.RFC1123Z, item.PubDate); err == nil {
-			publishedAt = sql.NullTime{
-				Time:  t,
-				Valid: true,
-			}
+	err = db.MarkFeedFetchSucceeded(context.Background(), database.MarkFeedFetchSucceededParams{
+		ID:      feed.ID,
+		SiteUrl: nullStringFromString(strings.TrimSpace(channelLink)),
+	})
+	if err != nil {
+		log.Printf("Couldn't mark feed %s fetch succeeded: %v", feed.Name, err)
+	}
+	found := len(items)
+	items, err = pipeline.process(db, feed, channelLink, items)
+	if err != nil {
+		log.Printf("Couldn't process items of feed %s: %v", feed.Name, err)
+		return
+	}
+	saved, err := savePosts(db, feed, items, true)
+	log.Printf("Feed %s collected, %v posts found, %v kept, %v new", feed.Name, found, len(items), saved)
+	// A monitored page's snapshot moves on only once its change is stored,
+	// so a failed save is retried from the same baseline next time.
+	if snapshot != nil && err == nil {
+		if err := storePageSnapshot(db, feed.ID, *snapshot); err != nil {
+			log.Printf("Couldn't store snapshot of page %s: %v", feed.Name, err)
 		}
+	}
+}
 
-		_, err = db.CreatePost(context.Background(), database.CreatePostParams{
+// savePosts stores items as posts of feed, skipping ones already stored, and
+// returns how many were new. It carries on past posts it couldn't store and
+// returns the first such error. New posts go through their followers' filter
+// rules; notify is passed on to them.
+func savePosts(db *database.Queries, feed database.Feed, items []RSSItem, notify bool) (int, error) {
+	var saved []database.Post
+	var firstErr error
+	for _, item := range items {
+		post, err := db.CreatePost(context.Background(), database.CreatePostParams{
 			ID:        uuid.New(),
 			CreatedAt: time.Now().UTC(),
 			UpdatedAt: time.Now().UTC(),
@@ -69,47 +107,144 @@ func scrapeFeed(db *database.Queries, wg *sync.WaitGroup, feed database.Feed) {
 				Valid:  true,
 			},
 			Url:         item.Link,
-			PublishedAt: publishedAt,
+			PublishedAt: parsePublishedAt(item.PubDate),
+			OriginalUrl: nullStringFromString(item.OriginalLink),
+			Author:      nullStringFromString(item.author()),
+			Categories:  item.categories(),
 		})
 		if err != nil {
 			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
 				continue
 			}
 			log.Printf("Couldn't create post: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
 			continue
 		}
+		saved = append(saved, post)
+	}
+	applyFilterRules(context.Background(), db, feed.ID, saved, notify)
+	return len(saved), firstErr
+}
+
+// fetchFeedItems turns any kind of feed source into RSS-shaped items so they
+// can share the post pipeline. It also returns the channel link, if the
+// source has one, for resolving relative item links, and for a monitored
+// page whose text changed, the snapshot to store once the items are saved.
+func fetchFeedItems(db *database.Queries, feed database.Feed, creds *FeedCredentials) ([]RSSItem, string, *pageSnapshot, error) {
+	switch feed.Kind {
+	case FeedKindScrapedPage:
+		stored, err := db.GetFeedSelectors(context.Background(), feed.ID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("couldn't load selectors: %w", err)
+		}
+		items, err := scrapePage(feed.Url, databaseFeedSelectorToPageSelectors(stored), creds)
+		return items, "", nil, err
+	case FeedKindMonitoredPage:
+		items, snapshot, err := checkMonitoredPage(db, feed, creds)
+		return items, "", snapshot, err
+	default:
+		feedData, err := fetchFeed(feed.Url, creds)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return feedData.Channel.Item, feedData.Channel.Link, nil, nil
 	}
-	log.Printf("Feed %s collected, %v posts found", feed.Name, len(feedData.Channel.Item))
+}
+
+var publishedAtLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+func parsePublishedAt(value string) sql.NullTime {
+	value = strings.TrimSpace(value)
+	for _, layout := range publishedAtLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return sql.NullTime{
+				Time:  t,
+				Valid: true,
+			}
+		}
+	}
+	return sql.NullTime{}
 }
 
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"` 
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Language    string    `xml:"language"`
-		Item        []RSSItem `xml:"item"`
+		Title       string     `xml:"title"`
+		AtomLinks   []AtomLink `xml:"http://www.w3.org/2005/Atom link"` // before Link so <atom:link> doesn't land there
+		Link        string     `xml:"link"`
+		Description string     `xml:"description"`
+		Language    string     `xml:"language"`
+		Item        []RSSItem  `xml:"item"`
 	} `xml:"channel"`
 }
 
+// AtomLink is an <atom:link> in an RSS channel, used for RFC 5005 paging.
+type AtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// linkWithRel returns the href of the first atom:link with one of rels.
+func (f *RSSFeed) linkWithRel(rels ...string) string {
+	for _, rel := range rels {
+		for _, link := range f.Channel.AtomLinks {
+			if link.Rel == rel && link.Href != "" {
+				return link.Href
+			}
+		}
+	}
+	return ""
+}
+
 type RSSItem struct {
 	Title       string `xml:"title"`
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
 	PubDate     string `xml:"pubDate"`
+	Author      string `xml:"author"`
+	// Creator is <dc:creator>, which most feeds use instead of <author>.
+	Creator    string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories []string `xml:"category"`
+	// OriginalLink is Link as the source gave it, before canonicalisation.
+	OriginalLink string `xml:"-"`
 }
 
-func fetchFeed(feedURL string) (*RSSFeed, error) {
-	httpClient := http.Client{
-		Timeout: 10 * time.Second,
+func (item RSSItem) author() string {
+	if author := strings.TrimSpace(item.Creator); author != "" {
+		return author
 	}
-	resp, err := httpClient.Get(feedURL)
-	if err != nil {
-		return nil, err
+	return strings.TrimSpace(item.Author)
+}
+
+// categories trims the item's categories and drops empty and repeated ones.
+func (item RSSItem) categories() []string {
+	categories := []string{}
+	seen := map[string]bool{}
+	for _, category := range item.Categories {
+		category = strings.TrimSpace(category)
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		categories = append(categories, category)
 	}
-	defer resp.Body.Close()
+	return categories
+}
 
-	dat, err := io.ReadAll(resp.Body)
+func fetchFeed(feedURL string, creds *FeedCredentials) (*RSSFeed, error) {
+	dat, err := fetchURL(feedURL, creds)
 	if err != nil {
 		return nil, err
 	}
@@ -121,4 +256,24 @@ func fetchFeed(feedURL string) (*RSSFeed, error) {
 	}
 
 	return &rssFeed, nil
-}
\ No newline at end of file
+}
+
+func fetchURL(rawURL string, creds *FeedCredentials) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if creds != nil {
+		creds.apply(req)
+	}
+	resp, err := fetchClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching %s: %s", rawURL, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// highlightPolicy keeps the <mark> tags ts_headline wraps matches in and
+// escapes everything else.
+var highlightPolicy = bluemonday.NewPolicy().AllowElements("mark")
+
+type SearchResult struct {
+	Post
+	FeedName string  `json:"feed_name"`
+	Rank     float32 `json:"rank"`
+	// TitleHighlight and Snippet are HTML, with matches in <mark>.
+	TitleHighlight string `json:"title_highlight"`
+	Snippet        string `json:"snippet"`
+}
+
+var errEmptySearch = errors.New("search query needs at least one word to look for")
+
+// buildTSQuery turns a search box query into to_tsquery syntax. Words must
+// all match, "quoted phrases" must match in order, a trailing * matches a
+// prefix, a leading - excludes a word or phrase, and OR between two terms
+// matches either. Only letters and digits reach the tsquery, so user input
+// can't inject operators.
+func buildTSQuery(query string) (string, error) {
+	var out strings.Builder
+	positive, or := false, false
+	for query = strings.TrimSpace(query); query != ""; query = strings.TrimSpace(query) {
+		negate := strings.HasPrefix(query, "-")
+		if negate {
+			query = query[1:]
+		}
+
+		var term string
+		if strings.HasPrefix(query, `"`) {
+			if end := strings.Index(query[1:], `"`); end >= 0 {
+				term, query = query[1:end+1], query[end+2:]
+			} else {
+				term, query = query[1:], ""
+			}
+		} else {
+			end := strings.IndexFunc(query, unicode.IsSpace)
+			if end < 0 {
+				end = len(query)
+			}
+			term, query = query[:end], query[end:]
+			if term == "OR" && !negate {
+				or = out.Len() > 0
+				continue
+			}
+		}
+
+		lexemes := searchLexemes(term)
+		if len(lexemes) == 0 {
+			continue
+		}
+		if strings.HasSuffix(strings.TrimSpace(term), "*") {
+			lexemes[len(lexemes)-1] += ":*"
+		}
+		expr := strings.Join(lexemes, " <-> ")
+		if negate {
+			expr = "!(" + expr + ")"
+		} else {
+			positive = true
+		}
+
+		if out.Len() > 0 {
+			if or {
+				out.WriteString(" | ")
+			} else {
+				out.WriteString(" & ")
+			}
+		}
+		or = false
+		out.WriteString(expr)
+	}
+	if !positive {
+		return "", errEmptySearch
+	}
+	return out.String(), nil
+}
+
+func searchLexemes(term string) []string {
+	return strings.FieldsFunc(strings.ToLower(term), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Search results are ordered by rank, so their cursors carry the rank rather
+// than a time.
+func encodeSearchCursor(rank float32, id uuid.UUID) string {
+	return encodeCursor(strconv.FormatFloat(float64(rank), 'g', -1, 32), id)
+}
+
+func decodeSearchCursor(cursor string) (float32, uuid.UUID, error) {
+	key, id, err := decodeCursor(cursor)
+	if err != nil {
+		return 0, uuid.Nil, err
+	}
+	rank, err := strconv.ParseFloat(key, 32)
+	if err != nil {
+		return 0, uuid.Nil, errInvalidCursor
+	}
+	return float32(rank), id, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"rust async", "rust & async"},
+		{`"machine learning" go`, "machine <-> learning & go"},
+		{"kube*", "kube:*"},
+		{`"large language mod*"`, "large <-> language <-> mod:*"},
+		{"golang -generics", "golang & !(generics)"},
+		{`postgres -"full text"`, "postgres & !(full <-> text)"},
+		{"rust OR go wasm", "rust | go & wasm"},
+		{"OR c++ & !x", "c & x"},
+		{"e-mail's", "e <-> mail <-> s"},
+	}
+	for _, tt := range tests {
+		got, err := buildTSQuery(tt.query)
+		if err != nil {
+			t.Errorf("buildTSQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("buildTSQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	for _, query := range []string{"", "   ", "-spam", `"" !!`} {
+		if _, err := buildTSQuery(query); err == nil {
+			t.Errorf("Expected %q to be rejected", query)
+		}
+	}
+}
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	id := uuid.New()
+	rank := float32(0.1) / 3
+
+	gotRank, gotID, err := decodeSearchCursor(encodeSearchCursor(rank, id))
+	if err != nil {
+		t.Fatalf("decodeSearchCursor: %v", err)
+	}
+	if gotRank != rank || gotID != id {
+		t.Errorf("Expected (%v, %v), got (%v, %v)", rank, id, gotRank, gotID)
+	}
+}
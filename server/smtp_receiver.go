@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+)
+
+const (
+	smtpMaxMessageBytes = 10 << 20
+	smtpMaxRecipients   = 50
+	smtpSessionTimeout  = 5 * time.Minute
+)
+
+// SMTPReceiver is a receive-only SMTP server that turns mail sent to a user's
+// inbound addresses into posts. It never relays.
+type SMTPReceiver struct {
+	db       *database.Queries
+	domain   string
+	pipeline ItemPipeline
+}
+
+func NewSMTPReceiver(db *database.Queries, domain string, pipeline ItemPipeline) *SMTPReceiver {
+	return &SMTPReceiver{
+		db:       db,
+		domain:   domain,
+		pipeline: pipeline,
+	}
+}
+
+func (s *SMTPReceiver) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	log.Printf("SMTP receiver for @%s listening on %s", s.domain, addr)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("SMTP accept error: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *SMTPReceiver) handleConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(smtpSessionTimeout))
+	tp := textproto.NewConn(conn)
+
+	reply := func(code int, msg string) {
+		tp.PrintfLine("%d %s", code, msg)
+	}
+
+	var recipients []database.InboundAddress
+	mailFrom := false
+	reply(220, s.domain+" ESMTP FeedLyst")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			reply(250, s.domain)
+		case "EHLO":
+			tp.PrintfLine("250-%s", s.domain)
+			tp.PrintfLine("250-SIZE %d", smtpMaxMessageBytes)
+			tp.PrintfLine("250 8BITMIME")
+		case "MAIL":
+			mailFrom = true
+			recipients = nil
+			reply(250, "OK")
+		case "RCPT":
+			if !mailFrom {
+				reply(503, "Need MAIL before RCPT")
+				continue
+			}
+			if len(recipients) >= smtpMaxRecipients {
+				reply(452, "Too many recipients")
+				continue
+			}
+			address, err := s.lookupRecipient(arg)
+			if err != nil {
+				reply(550, "No such mailbox")
+				continue
+			}
+			recipients = append(recipients, address)
+			reply(250, "OK")
+		case "DATA":
+			if len(recipients) == 0 {
+				reply(503, "Need RCPT before DATA")
+				continue
+			}
+			reply(354, "End data with <CR><LF>.<CR><LF>")
+			code, msg := s.receive(tp.DotReader(), recipients)
+			reply(code, msg)
+			mailFrom = false
+			recipients = nil
+		case "RSET":
+			mailFrom = false
+			recipients = nil
+			reply(250, "OK")
+		case "NOOP":
+			reply(250, "OK")
+		case "QUIT":
+			reply(221, "Bye")
+			return
+		default:
+			reply(502, "Command not implemented")
+		}
+	}
+}
+
+// lookupRecipient parses a RCPT argument such as "TO:<u-abc@host>".
+func (s *SMTPReceiver) lookupRecipient(arg string) (database.InboundAddress, error) {
+	path := strings.TrimSpace(arg)
+	if len(path) < 3 || !strings.EqualFold(path[:3], "TO:") {
+		return database.InboundAddress{}, fmt.Errorf("malformed RCPT argument")
+	}
+	path = strings.TrimSpace(path[3:])
+	if end := strings.Index(path, ">"); end != -1 {
+		path = path[:end]
+	}
+	token := inboundToken(strings.TrimPrefix(path, "<"), s.domain)
+	if token == "" {
+		return database.InboundAddress{}, fmt.Errorf("unknown recipient")
+	}
+	return s.db.GetInboundAddressByToken(context.Background(), token)
+}
+
+func (s *SMTPReceiver) receive(data io.Reader, recipients []database.InboundAddress) (int, string) {
+	raw, err := io.ReadAll(io.LimitReader(data, smtpMaxMessageBytes+1))
+	if err != nil {
+		return 451, "Error reading message"
+	}
+	if len(raw) > smtpMaxMessageBytes {
+		io.Copy(io.Discard, data)
+		return 552, "Message too large"
+	}
+
+	newsletter, err := parseNewsletter(bytes.NewReader(raw))
+	if err != nil {
+		log.Printf("Couldn't parse inbound message: %v", err)
+		return 554, "Malformed message"
+	}
+	for _, address := range recipients {
+		if err := deliverNewsletter(context.Background(), s.db, s.pipeline, address, newsletter); err != nil {
+			log.Printf("Couldn't store newsletter for feed %s: %v", address.FeedID, err)
+			return 451, "Temporary failure storing message"
+		}
+	}
+	log.Printf("Stored newsletter %q for %d inbound address(es)", newsletter.Subject, len(recipients))
+	return 250, "OK"
+}
@@ -0,0 +1,114 @@
+-- name: CreateCollection :one
+INSERT INTO collections (id, created_at, updated_at, user_id, slug, name, description, kind, visibility)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetCollectionsForUser :many
+SELECT * FROM collections
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetCollectionForUser :one
+SELECT * FROM collections
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetCollectionBySlug :one
+SELECT collections.id, collections.created_at, collections.updated_at, collections.user_id, collections.slug, collections.name, collections.description, collections.kind, collections.visibility, users.name AS owner_name
+FROM collections
+JOIN users ON users.id = collections.user_id
+WHERE collections.slug = $1;
+
+-- name: GetPublicCollections :many
+SELECT collections.id, collections.created_at, collections.updated_at, collections.user_id, collections.slug, collections.name, collections.description, collections.kind, collections.visibility, users.name AS owner_name
+FROM collections
+JOIN users ON users.id = collections.user_id
+WHERE collections.visibility = 'public'
+AND (
+    sqlc.narg(cursor_time)::TIMESTAMP IS NULL
+    OR (collections.updated_at, collections.id) < (sqlc.narg(cursor_time)::TIMESTAMP, sqlc.narg(cursor_id)::UUID)
+)
+ORDER BY collections.updated_at DESC, collections.id DESC
+LIMIT $1;
+
+-- name: UpdateCollection :one
+UPDATE collections
+SET slug = $2, name = $3, description = $4, visibility = $5, updated_at = $6
+WHERE id = $1
+RETURNING *;
+
+-- name: TouchCollection :exec
+UPDATE collections
+SET updated_at = $2
+WHERE id = $1;
+
+-- name: DeleteCollection :exec
+DELETE FROM collections
+WHERE id = $1;
+
+-- name: AddCollectionPost :exec
+INSERT INTO collection_posts (collection_id, post_id, note, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (collection_id, post_id) DO UPDATE
+SET note = EXCLUDED.note;
+
+-- name: RemoveCollectionPost :execrows
+DELETE FROM collection_posts
+WHERE collection_id = $1 AND post_id = $2;
+
+-- name: AddCollectionFeed :exec
+INSERT INTO collection_feeds (collection_id, feed_id, note, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (collection_id, feed_id) DO UPDATE
+SET note = EXCLUDED.note;
+
+-- name: RemoveCollectionFeed :execrows
+DELETE FROM collection_feeds
+WHERE collection_id = $1 AND feed_id = $2;
+
+-- name: GetCollectionPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories,
+    feeds.name AS feed_name, feeds.site_url AS feed_site_url,
+    collection_posts.note, collection_posts.created_at AS added_at
+FROM collection_posts
+JOIN posts ON posts.id = collection_posts.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE collection_posts.collection_id = $1
+AND feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+AND (
+    sqlc.narg(cursor_time)::TIMESTAMP IS NULL
+    OR (collection_posts.created_at, posts.id) < (sqlc.narg(cursor_time)::TIMESTAMP, sqlc.narg(cursor_id)::UUID)
+)
+ORDER BY collection_posts.created_at DESC, posts.id DESC
+LIMIT $2;
+
+-- name: GetCollectionFeeds :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.kind, feeds.last_fetch_succeeded_at, feeds.deleted_at, feeds.site_url,
+    collection_feeds.note, collection_feeds.created_at AS added_at
+FROM collection_feeds
+JOIN feeds ON feeds.id = collection_feeds.feed_id
+WHERE collection_feeds.collection_id = $1
+AND feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+ORDER BY collection_feeds.created_at DESC, feeds.id DESC;
+
+-- name: GetRecentPostsInCollectionFeeds :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories,
+    feeds.name AS feed_name, feeds.site_url AS feed_site_url
+FROM collection_feeds
+JOIN feeds ON feeds.id = collection_feeds.feed_id
+JOIN posts ON posts.feed_id = feeds.id
+WHERE collection_feeds.collection_id = $1
+AND feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
+LIMIT $2;
@@ -0,0 +1,34 @@
+-- name: StartFeedBackfill :one
+INSERT INTO feed_backfills (feed_id, created_at, updated_at, status, max_pages)
+VALUES ($1, $2, $3, 'pending', $4)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+status = 'pending',
+next_url = NULL,
+pages_fetched = 0,
+max_pages = EXCLUDED.max_pages,
+items_found = 0,
+error = NULL
+RETURNING *;
+
+-- name: GetFeedBackfill :one
+SELECT * FROM feed_backfills
+WHERE feed_id = $1;
+
+-- name: GetActiveFeedBackfills :many
+SELECT feed_backfills.* FROM feed_backfills
+JOIN feeds ON feeds.id = feed_backfills.feed_id
+WHERE feed_backfills.status IN ('pending', 'running')
+AND feeds.deleted_at IS NULL
+ORDER BY feed_backfills.updated_at ASC
+LIMIT $1;
+
+-- name: UpdateFeedBackfillProgress :exec
+UPDATE feed_backfills
+SET status = $2,
+next_url = $3,
+pages_fetched = $4,
+items_found = $5,
+error = $6,
+updated_at = NOW()
+WHERE feed_id = $1;
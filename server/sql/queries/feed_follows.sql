@@ -1,5 +1,6 @@
 -- name: GetFeedFollowsForUser :many
-SELECT * FROM feed_follows WHERE user_id = $1;
+SELECT * FROM feed_follows WHERE user_id = $1
+ORDER BY position, created_at;
 --
 
 -- name: CreateFeedFollow :one
@@ -10,4 +11,36 @@ RETURNING *;
 
 -- name: DeleteFeedFollow :exec
 DELETE FROM feed_follows WHERE id = $1 and user_id = $2;
---
\ No newline at end of file
+--
+
+-- name: DeleteFeedFollowsForFeed :many
+DELETE FROM feed_follows
+WHERE feed_id = $1
+RETURNING user_id;
+
+-- name: GetFeedFollowForUser :one
+SELECT * FROM feed_follows
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateFeedFollow :one
+UPDATE feed_follows
+SET folder_id = $2, position = $3, title = $4, hide_from_timeline = $5, full_content = $6, sort_order = $7, updated_at = $8
+WHERE id = $1
+RETURNING *;
+
+-- name: GetFeedFollowForFeed :one
+SELECT * FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: GetFollowedFeedsForExport :many
+SELECT feed_follows.feed_id, feed_follows.folder_id, feed_follows.title, feeds.name, feeds.url, feeds.site_url, feeds.kind
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1
+AND feeds.deleted_at IS NULL
+ORDER BY feed_follows.position, feed_follows.created_at;
+
+-- name: DeleteOtherFeedFollowsForFeed :many
+DELETE FROM feed_follows
+WHERE feed_id = $1 AND user_id <> $2
+RETURNING user_id;
@@ -0,0 +1,15 @@
+-- name: UpsertFeedProcessors :one
+INSERT INTO feed_processors (feed_id, created_at, updated_at, config)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+config = EXCLUDED.config
+RETURNING *;
+
+-- name: GetFeedProcessors :one
+SELECT * FROM feed_processors
+WHERE feed_id = $1;
+
+-- name: DeleteFeedProcessors :exec
+DELETE FROM feed_processors
+WHERE feed_id = $1;
@@ -0,0 +1,15 @@
+-- name: UpsertFeedSelectors :one
+INSERT INTO feed_selectors (feed_id, created_at, updated_at, item_selector, title_selector, link_selector, date_selector, summary_selector)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+item_selector = EXCLUDED.item_selector,
+title_selector = EXCLUDED.title_selector,
+link_selector = EXCLUDED.link_selector,
+date_selector = EXCLUDED.date_selector,
+summary_selector = EXCLUDED.summary_selector
+RETURNING *;
+
+-- name: GetFeedSelectors :one
+SELECT * FROM feed_selectors
+WHERE feed_id = $1;
@@ -0,0 +1,50 @@
+-- name: GetFeedPostCountsByDay :many
+SELECT date_trunc('day', COALESCE(published_at, created_at))::TIMESTAMP AS day, COUNT(*) AS posts
+FROM posts
+WHERE feed_id = $1
+AND COALESCE(published_at, created_at) >= sqlc.arg(since)
+GROUP BY day
+ORDER BY day;
+
+-- name: GetFeedPostCountsByWeek :many
+SELECT date_trunc('week', COALESCE(published_at, created_at))::TIMESTAMP AS week, COUNT(*) AS posts
+FROM posts
+WHERE feed_id = $1
+AND COALESCE(published_at, created_at) >= sqlc.arg(since)
+GROUP BY week
+ORDER BY week;
+
+-- name: GetFeedPostingHours :many
+SELECT EXTRACT(HOUR FROM published_at)::INTEGER AS hour, COUNT(*) AS posts
+FROM posts
+WHERE feed_id = $1
+AND published_at IS NOT NULL
+GROUP BY hour;
+
+-- name: GetFeedPostingWeekdays :many
+SELECT EXTRACT(DOW FROM published_at)::INTEGER AS weekday, COUNT(*) AS posts
+FROM posts
+WHERE feed_id = $1
+AND published_at IS NOT NULL
+GROUP BY weekday;
+
+-- name: GetFeedPostTotals :one
+SELECT totals.total_posts, totals.average_length, latest.latest_post_at
+FROM (
+    SELECT COUNT(*) AS total_posts,
+    COALESCE(AVG(LENGTH(COALESCE(description, ''))), 0)::FLOAT8 AS average_length
+    FROM posts
+    WHERE feed_id = $1
+) totals
+LEFT JOIN LATERAL (
+    SELECT COALESCE(published_at, created_at) AS latest_post_at
+    FROM posts
+    WHERE feed_id = $1
+    ORDER BY COALESCE(published_at, created_at) DESC
+    LIMIT 1
+) latest ON TRUE;
+
+-- name: GetFeedAudienceCounts :one
+SELECT
+(SELECT COUNT(*) FROM feed_follows WHERE feed_follows.feed_id = $1) AS followers,
+(SELECT COUNT(*) FROM starred_feeds WHERE starred_feeds.feed_id = $1) AS starrers;
@@ -1,13 +1,16 @@
 -- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, kind)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetFeeds :many
-SELECT * FROM feeds;
+SELECT * FROM feeds
+WHERE deleted_at IS NULL;
 
 -- name: GetNextFeedsToFetch :many
 SELECT * FROM feeds
+WHERE kind <> 'newsletter'
+AND deleted_at IS NULL
 ORDER BY last_fetched_at ASC NULLS FIRST
 LIMIT $1;
 
@@ -16,4 +19,73 @@ UPDATE feeds
 SET last_fetched_at = NOW(),
 updated_at = NOW()
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: GetPublicFeeds :many
+SELECT * FROM feeds
+WHERE kind <> 'newsletter'
+AND deleted_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+);
+
+-- name: GetFeedsVisibleToUser :many
+SELECT * FROM feeds
+WHERE deleted_at IS NULL
+AND (
+    user_id = $1
+    OR (
+        kind <> 'newsletter'
+        AND NOT EXISTS (
+            SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+        )
+    )
+);
+
+-- name: GetFeedVisibleToUser :one
+SELECT * FROM feeds
+WHERE id = $1
+AND deleted_at IS NULL
+AND (
+    user_id = $2
+    OR (
+        kind <> 'newsletter'
+        AND NOT EXISTS (
+            SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+        )
+    )
+);
+
+-- name: GetFeed :one
+SELECT * FROM feeds
+WHERE id = $1;
+
+-- name: MarkFeedFetchSucceeded :exec
+UPDATE feeds
+SET last_fetch_succeeded_at = NOW(),
+site_url = COALESCE(sqlc.narg(site_url), site_url)
+WHERE id = $1;
+
+-- name: UpdateFeed :one
+UPDATE feeds
+SET name = $2,
+url = $3,
+last_fetched_at = CASE WHEN url = $3 THEN last_fetched_at END,
+updated_at = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: FeedURLInUse :one
+SELECT EXISTS (
+    SELECT 1 FROM feeds
+    WHERE url = $1 AND id <> $2 AND deleted_at IS NULL
+);
+
+-- name: SoftDeleteFeed :exec
+UPDATE feeds
+SET deleted_at = $2, updated_at = $2
+WHERE id = $1;
+
+-- name: GetFeedByURL :one
+SELECT * FROM feeds
+WHERE url = $1 AND deleted_at IS NULL;
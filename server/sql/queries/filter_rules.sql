@@ -0,0 +1,39 @@
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, created_at, updated_at, user_id, name, enabled, match_any, conditions, actions)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetFilterRulesForUser :many
+SELECT * FROM filter_rules
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetFilterRuleForUser :one
+SELECT * FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateFilterRule :one
+UPDATE filter_rules
+SET name = $2, enabled = $3, match_any = $4, conditions = $5, actions = $6, updated_at = $7
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteFilterRule :exec
+DELETE FROM filter_rules
+WHERE id = $1;
+
+-- name: GetEnabledFilterRulesForFeed :many
+SELECT filter_rules.* FROM filter_rules
+JOIN feed_follows ON feed_follows.user_id = filter_rules.user_id
+WHERE feed_follows.feed_id = $1
+AND filter_rules.enabled
+ORDER BY filter_rules.user_id, filter_rules.created_at;
+
+-- name: HidePost :exec
+INSERT INTO hidden_posts (user_id, post_id, rule_id, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, post_id, rule_id) DO NOTHING;
+
+-- name: UnhidePostsForRule :exec
+DELETE FROM hidden_posts
+WHERE rule_id = $1;
@@ -0,0 +1,37 @@
+-- name: CreateFolder :one
+INSERT INTO folders (id, created_at, updated_at, user_id, parent_id, name, position)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetFoldersForUser :many
+SELECT * FROM folders
+WHERE user_id = $1
+ORDER BY position, lower(name);
+
+-- name: GetFolderForUser :one
+SELECT * FROM folders
+WHERE id = $1 AND user_id = $2;
+
+-- name: FolderHasSubfolders :one
+SELECT EXISTS (
+    SELECT 1 FROM folders
+    WHERE parent_id = $1
+);
+
+-- name: UpdateFolder :one
+UPDATE folders
+SET parent_id = $2, name = $3, position = $4, updated_at = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteFolder :exec
+DELETE FROM folders
+WHERE id = $1;
+
+-- name: GetFolderByName :one
+SELECT * FROM folders
+WHERE user_id = $1
+AND parent_id IS NOT DISTINCT FROM $2
+AND lower(name) = lower($3)
+ORDER BY created_at
+LIMIT 1;
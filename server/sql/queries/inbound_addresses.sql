@@ -0,0 +1,21 @@
+-- name: CreateInboundAddress :one
+INSERT INTO inbound_addresses (id, created_at, updated_at, user_id, feed_id, token)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetInboundAddressesForUser :many
+SELECT * FROM inbound_addresses
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetInboundAddressByToken :one
+SELECT * FROM inbound_addresses
+WHERE token = $1;
+
+-- name: DeleteInboundAddress :exec
+DELETE FROM inbound_addresses
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteInboundAddressesForFeed :exec
+DELETE FROM inbound_addresses
+WHERE feed_id = $1;
@@ -0,0 +1,57 @@
+-- name: CreateLabel :one
+INSERT INTO labels (id, created_at, updated_at, user_id, name, color)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetLabelsForUser :many
+SELECT labels.*, COUNT(post_labels.post_id) AS post_count
+FROM labels
+LEFT JOIN post_labels ON post_labels.label_id = labels.id
+WHERE labels.user_id = $1
+GROUP BY labels.id
+ORDER BY lower(labels.name);
+
+-- name: GetLabelForUser :one
+SELECT labels.*, (
+    SELECT COUNT(*) FROM post_labels WHERE post_labels.label_id = labels.id
+) AS post_count
+FROM labels
+WHERE labels.id = $1 AND labels.user_id = $2;
+
+-- name: GetLabelByName :one
+SELECT * FROM labels
+WHERE user_id = $1 AND lower(name) = lower(sqlc.arg(name));
+
+-- name: UpdateLabel :exec
+UPDATE labels
+SET name = $2, color = $3, updated_at = $4
+WHERE id = $1;
+
+-- name: DeleteLabel :exec
+DELETE FROM labels
+WHERE id = $1;
+
+-- name: CopyPostLabels :exec
+INSERT INTO post_labels (label_id, post_id, created_at)
+SELECT sqlc.arg(to_label_id)::UUID, post_labels.post_id, post_labels.created_at
+FROM post_labels
+WHERE post_labels.label_id = sqlc.arg(from_label_id)
+ON CONFLICT (label_id, post_id) DO NOTHING;
+
+-- name: AddPostLabel :exec
+INSERT INTO post_labels (label_id, post_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (label_id, post_id) DO NOTHING;
+
+-- name: RemovePostLabel :execrows
+DELETE FROM post_labels
+WHERE label_id = $1 AND post_id = $2;
+
+-- name: GetLabelsForPost :many
+SELECT labels.*, (
+    SELECT COUNT(*) FROM post_labels counted WHERE counted.label_id = labels.id
+) AS post_count
+FROM labels
+JOIN post_labels ON post_labels.label_id = labels.id
+WHERE labels.user_id = $1 AND post_labels.post_id = $2
+ORDER BY lower(labels.name);
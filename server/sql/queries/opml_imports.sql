@@ -0,0 +1,46 @@
+-- name: CreateOPMLImport :one
+INSERT INTO opml_imports (id, created_at, updated_at, user_id, status)
+VALUES ($1, $2, $3, $4, 'pending')
+RETURNING *;
+
+-- name: CreateOPMLImportItem :exec
+INSERT INTO opml_import_items (id, import_id, position, url, title, folder, subfolder, starred, star_only, status, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'pending', $10);
+
+-- name: GetOPMLImportForUser :one
+SELECT * FROM opml_imports
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetOPMLImportItems :many
+SELECT * FROM opml_import_items
+WHERE import_id = $1
+ORDER BY position;
+
+-- name: GetPendingOPMLImportItems :many
+SELECT opml_import_items.id, opml_import_items.import_id, opml_import_items.url, opml_import_items.title,
+    opml_import_items.folder, opml_import_items.subfolder, opml_import_items.starred, opml_import_items.star_only, opml_imports.user_id
+FROM opml_import_items
+JOIN opml_imports ON opml_imports.id = opml_import_items.import_id
+WHERE opml_import_items.status = 'pending'
+ORDER BY ROW_NUMBER() OVER (PARTITION BY opml_import_items.import_id ORDER BY opml_import_items.position),
+    opml_imports.created_at
+LIMIT $1;
+
+-- name: MarkOPMLImportRunning :exec
+UPDATE opml_imports
+SET status = 'running', updated_at = $2
+WHERE id = $1 AND status = 'pending';
+
+-- name: FinishOPMLImportItem :exec
+UPDATE opml_import_items
+SET status = $2, feed_id = $3, error = $4, updated_at = $5
+WHERE id = $1;
+
+-- name: FinishOPMLImports :exec
+UPDATE opml_imports
+SET status = 'done', updated_at = $1
+WHERE status <> 'done'
+AND NOT EXISTS (
+    SELECT 1 FROM opml_import_items
+    WHERE opml_import_items.import_id = opml_imports.id AND opml_import_items.status = 'pending'
+);
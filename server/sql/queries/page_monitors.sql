@@ -0,0 +1,16 @@
+-- name: CreatePageMonitor :one
+INSERT INTO page_monitors (feed_id, created_at, updated_at, selector)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetPageMonitor :one
+SELECT * FROM page_monitors
+WHERE feed_id = $1;
+
+-- name: UpdatePageMonitorContent :exec
+UPDATE page_monitors
+SET content = $2,
+content_hash = $3,
+changed_at = $4,
+updated_at = NOW()
+WHERE feed_id = $1;
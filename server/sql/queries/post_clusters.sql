@@ -0,0 +1,50 @@
+-- name: GetUnclusteredPosts :many
+SELECT posts.* FROM posts
+LEFT JOIN post_clusters ON post_clusters.post_id = posts.id
+WHERE post_clusters.post_id IS NULL
+AND (
+    sqlc.narg(after_time)::TIMESTAMP IS NULL
+    OR (posts.created_at, posts.id) > (sqlc.narg(after_time)::TIMESTAMP, sqlc.narg(after_id)::UUID)
+)
+ORDER BY posts.created_at ASC, posts.id ASC
+LIMIT $1;
+
+-- name: GetClusterCandidates :many
+SELECT post_clusters.post_id, post_clusters.cluster_id, post_clusters.url_key, post_clusters.title_shingles
+FROM post_clusters
+JOIN posts ON posts.id = post_clusters.post_id
+WHERE posts.feed_id <> $1
+AND COALESCE(posts.published_at, posts.created_at) BETWEEN sqlc.arg(window_start) AND sqlc.arg(window_end)
+AND (
+    post_clusters.url_key = sqlc.arg(url_key)
+    OR post_clusters.title_shingles && sqlc.arg(title_shingles)::BIGINT[]
+)
+ORDER BY post_clusters.created_at DESC
+LIMIT 200;
+
+-- name: CreatePostCluster :exec
+INSERT INTO post_clusters (post_id, cluster_id, created_at, url_key, title_shingles)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (post_id) DO NOTHING;
+
+-- name: GetPostClusters :many
+SELECT post_id, cluster_id FROM post_clusters
+WHERE post_id = ANY(sqlc.arg(post_ids)::UUID[]);
+
+-- name: GetClusterMembersVisibleToUser :many
+SELECT post_clusters.cluster_id, posts.id AS post_id, posts.url, feeds.id AS feed_id, feeds.name AS feed_name
+FROM post_clusters
+JOIN posts ON posts.id = post_clusters.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE post_clusters.cluster_id = ANY(sqlc.arg(cluster_ids)::UUID[])
+AND feeds.deleted_at IS NULL
+AND (
+    feeds.user_id = sqlc.arg(user_id)
+    OR (
+        feeds.kind <> 'newsletter'
+        AND NOT EXISTS (
+            SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+        )
+    )
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at) ASC;
@@ -0,0 +1,55 @@
+-- name: UpsertFeedRetention :one
+INSERT INTO feed_retention (feed_id, created_at, updated_at, max_age_days, max_items)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+max_age_days = EXCLUDED.max_age_days,
+max_items = EXCLUDED.max_items
+RETURNING *;
+
+-- name: GetFeedRetention :one
+SELECT * FROM feed_retention
+WHERE feed_id = $1;
+
+-- name: GetFeedRetentionPolicies :many
+SELECT feeds.id, feeds.name, feed_retention.max_age_days, feed_retention.max_items
+FROM feeds
+LEFT JOIN feed_retention ON feed_retention.feed_id = feeds.id
+ORDER BY feeds.created_at;
+
+-- name: PruneFeedPostsOlderThan :execrows
+DELETE FROM posts
+WHERE id IN (
+    SELECT p.id FROM posts p
+    WHERE p.feed_id = $1
+    AND COALESCE(p.published_at, p.created_at) < sqlc.arg(cutoff)
+    AND NOT EXISTS (SELECT 1 FROM starred_feeds sf WHERE sf.feed_id = p.feed_id)
+    AND NOT EXISTS (SELECT 1 FROM saved_posts sp WHERE sp.post_id = p.id)
+    AND NOT EXISTS (SELECT 1 FROM post_labels pl WHERE pl.post_id = p.id)
+    AND NOT EXISTS (SELECT 1 FROM collection_posts cp WHERE cp.post_id = p.id)
+    LIMIT sqlc.arg(batch_size)
+);
+
+-- name: PruneFeedPostsBeyondCount :execrows
+DELETE FROM posts
+WHERE id IN (
+    SELECT p.id FROM posts p
+    WHERE p.feed_id = $1
+    AND NOT EXISTS (SELECT 1 FROM starred_feeds sf WHERE sf.feed_id = p.feed_id)
+    AND NOT EXISTS (SELECT 1 FROM saved_posts sp WHERE sp.post_id = p.id)
+    AND NOT EXISTS (SELECT 1 FROM post_labels pl WHERE pl.post_id = p.id)
+    AND NOT EXISTS (SELECT 1 FROM collection_posts cp WHERE cp.post_id = p.id)
+    ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+    OFFSET sqlc.arg(keep)
+    LIMIT sqlc.arg(batch_size)
+);
+
+-- name: CreatePostPrune :exec
+INSERT INTO post_prunes (id, created_at, feed_id, reason, posts_removed)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetRecentPostPrunesForFeed :many
+SELECT * FROM post_prunes
+WHERE feed_id = $1
+ORDER BY created_at DESC
+LIMIT 20;
@@ -1,20 +1,105 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, original_url, author, categories)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *;
 
 -- name: GetPostsForUser :many
 SELECT posts.* FROM posts
 JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 WHERE feed_follows.user_id = $1
-ORDER BY posts.published_at DESC
+AND (
+    sqlc.narg(folder_id)::UUID IS NULL
+    OR feed_follows.folder_id IN (
+        SELECT folders.id FROM folders
+        WHERE folders.id = sqlc.narg(folder_id)::UUID OR folders.parent_id = sqlc.narg(folder_id)::UUID
+    )
+)
+AND (
+    sqlc.narg(cursor_time)::TIMESTAMP IS NULL
+    OR (COALESCE(posts.published_at, posts.created_at), posts.id) < (sqlc.narg(cursor_time)::TIMESTAMP, sqlc.narg(cursor_id)::UUID)
+)
+AND (
+    NOT sqlc.arg(unread_only)::BOOLEAN
+    OR NOT COALESCE(
+        (
+            SELECT post_read_states.read FROM post_read_states
+            WHERE post_read_states.user_id = $1 AND post_read_states.post_id = posts.id
+        ),
+        COALESCE(posts.published_at, posts.created_at) <= (
+            SELECT feed_read_marks.read_until FROM feed_read_marks
+            WHERE feed_read_marks.user_id = $1 AND feed_read_marks.feed_id = posts.feed_id
+        ),
+        FALSE
+    )
+)
+AND (
+    sqlc.narg(label_id)::UUID IS NULL
+    OR EXISTS (
+        SELECT 1 FROM post_labels
+        WHERE post_labels.post_id = posts.id AND post_labels.label_id = sqlc.narg(label_id)::UUID
+    )
+)
+AND (
+    NOT feed_follows.hide_from_timeline
+    OR sqlc.narg(folder_id)::UUID IS NOT NULL
+    OR sqlc.narg(label_id)::UUID IS NOT NULL
+)
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
 LIMIT $2;
 
 -- name: GetPostsByFeedID :many
 SELECT * FROM posts
 WHERE feed_id = $1
-ORDER BY published_at DESC
-LIMIT $2 OFFSET $3;
+AND (
+    sqlc.narg(cursor_time)::TIMESTAMP IS NULL
+    OR (
+        NOT sqlc.arg(oldest_first)::BOOLEAN
+        AND (COALESCE(published_at, created_at), id) < (sqlc.narg(cursor_time)::TIMESTAMP, sqlc.narg(cursor_id)::UUID)
+    )
+    OR (
+        sqlc.arg(oldest_first)::BOOLEAN
+        AND (COALESCE(published_at, created_at), id) > (sqlc.narg(cursor_time)::TIMESTAMP, sqlc.narg(cursor_id)::UUID)
+    )
+)
+AND (
+    NOT sqlc.arg(unread_only)::BOOLEAN
+    OR NOT COALESCE(
+        (
+            SELECT post_read_states.read FROM post_read_states
+            WHERE post_read_states.user_id = sqlc.arg(user_id) AND post_read_states.post_id = posts.id
+        ),
+        COALESCE(posts.published_at, posts.created_at) <= (
+            SELECT feed_read_marks.read_until FROM feed_read_marks
+            WHERE feed_read_marks.user_id = sqlc.arg(user_id) AND feed_read_marks.feed_id = posts.feed_id
+        ),
+        FALSE
+    )
+)
+AND (
+    sqlc.narg(label_id)::UUID IS NULL
+    OR EXISTS (
+        SELECT 1 FROM post_labels
+        WHERE post_labels.post_id = posts.id AND post_labels.label_id = sqlc.narg(label_id)::UUID
+    )
+)
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = posts.id
+)
+ORDER BY
+    CASE WHEN sqlc.arg(oldest_first)::BOOLEAN THEN COALESCE(published_at, created_at) END,
+    CASE WHEN sqlc.arg(oldest_first)::BOOLEAN THEN id END,
+    COALESCE(published_at, created_at) DESC,
+    id DESC
+LIMIT $2;
+
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE id = $1;
 
 -- name: GetRecentPosts :many
 SELECT * FROM posts
@@ -26,4 +111,99 @@ SELECT p.*, f.name as feed_name FROM posts p
 JOIN feeds f ON p.feed_id = f.id
 JOIN starred_feeds sf ON f.id = sf.feed_id
 WHERE p.created_at > $1
-ORDER BY p.created_at DESC;
\ No newline at end of file
+ORDER BY p.created_at DESC;
+
+-- name: PostExistsForURL :one
+SELECT EXISTS (
+    SELECT 1 FROM posts
+    WHERE feed_id = $1
+    AND (original_url = sqlc.arg(url) OR url = sqlc.arg(url))
+);
+
+-- name: GetPostsInStarredFeedsForUser :many
+SELECT posts.* FROM posts
+JOIN starred_feeds ON starred_feeds.feed_id = posts.feed_id
+WHERE starred_feeds.user_id = $1
+AND COALESCE(posts.published_at, posts.created_at) > sqlc.arg(since)
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = $1 AND hidden_posts.post_id = posts.id
+)
+AND (
+    sqlc.narg(cursor_time)::TIMESTAMP IS NULL
+    OR (COALESCE(posts.published_at, posts.created_at), posts.id) < (sqlc.narg(cursor_time)::TIMESTAMP, sqlc.narg(cursor_id)::UUID)
+)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
+LIMIT $2;
+
+-- name: GetPostNeighbours :one
+SELECT
+    previous_in_feed.id AS previous_in_feed,
+    next_in_feed.id AS next_in_feed,
+    previous_in_timeline.id AS previous_in_timeline,
+    next_in_timeline.id AS next_in_timeline
+FROM posts post
+LEFT JOIN LATERAL (
+    SELECT newer.id FROM posts newer
+    WHERE newer.feed_id = post.feed_id
+    AND (COALESCE(newer.published_at, newer.created_at), newer.id) > (COALESCE(post.published_at, post.created_at), post.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = newer.id
+    )
+    ORDER BY COALESCE(newer.published_at, newer.created_at), newer.id
+    LIMIT 1
+) previous_in_feed ON TRUE
+LEFT JOIN LATERAL (
+    SELECT older.id FROM posts older
+    WHERE older.feed_id = post.feed_id
+    AND (COALESCE(older.published_at, older.created_at), older.id) < (COALESCE(post.published_at, post.created_at), post.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = older.id
+    )
+    ORDER BY COALESCE(older.published_at, older.created_at) DESC, older.id DESC
+    LIMIT 1
+) next_in_feed ON TRUE
+LEFT JOIN LATERAL (
+    SELECT newer.id FROM posts newer
+    JOIN feed_follows ON feed_follows.feed_id = newer.feed_id
+    WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND NOT feed_follows.hide_from_timeline
+    AND EXISTS (
+        SELECT 1 FROM feed_follows own
+        WHERE own.user_id = sqlc.arg(user_id) AND own.feed_id = post.feed_id
+    )
+    AND (COALESCE(newer.published_at, newer.created_at), newer.id) > (COALESCE(post.published_at, post.created_at), post.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = newer.id
+    )
+    ORDER BY COALESCE(newer.published_at, newer.created_at), newer.id
+    LIMIT 1
+) previous_in_timeline ON TRUE
+LEFT JOIN LATERAL (
+    SELECT older.id FROM posts older
+    JOIN feed_follows ON feed_follows.feed_id = older.feed_id
+    WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND NOT feed_follows.hide_from_timeline
+    AND EXISTS (
+        SELECT 1 FROM feed_follows own
+        WHERE own.user_id = sqlc.arg(user_id) AND own.feed_id = post.feed_id
+    )
+    AND (COALESCE(older.published_at, older.created_at), older.id) < (COALESCE(post.published_at, post.created_at), post.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = older.id
+    )
+    ORDER BY COALESCE(older.published_at, older.created_at) DESC, older.id DESC
+    LIMIT 1
+) next_in_timeline ON TRUE
+WHERE post.id = sqlc.arg(post_id);
+
+-- name: GetRecentPostsForUser :many
+SELECT posts.* FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
+LIMIT $2;
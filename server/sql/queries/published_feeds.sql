@@ -0,0 +1,17 @@
+-- name: CreatePublishedFeed :one
+INSERT INTO published_feeds (id, created_at, updated_at, user_id, token, title, source, folder_id, label_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetPublishedFeedsForUser :many
+SELECT * FROM published_feeds
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetPublishedFeedByToken :one
+SELECT * FROM published_feeds
+WHERE token = $1;
+
+-- name: DeletePublishedFeed :execrows
+DELETE FROM published_feeds
+WHERE id = $1 AND user_id = $2;
@@ -0,0 +1,73 @@
+-- name: SetPostReadState :exec
+INSERT INTO post_read_states (user_id, post_id, read, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read = EXCLUDED.read, updated_at = EXCLUDED.updated_at;
+
+-- name: MarkFeedReadUntil :exec
+INSERT INTO feed_read_marks (user_id, feed_id, read_until, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, feed_id) DO UPDATE
+SET read_until = GREATEST(feed_read_marks.read_until, EXCLUDED.read_until), updated_at = EXCLUDED.updated_at;
+
+-- name: MarkFollowedFeedsReadUntil :exec
+INSERT INTO feed_read_marks (user_id, feed_id, read_until, updated_at)
+SELECT feed_follows.user_id, feed_follows.feed_id, sqlc.arg(read_until)::TIMESTAMP, sqlc.arg(updated_at)::TIMESTAMP
+FROM feed_follows
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+ON CONFLICT (user_id, feed_id) DO UPDATE
+SET read_until = GREATEST(feed_read_marks.read_until, EXCLUDED.read_until), updated_at = EXCLUDED.updated_at;
+
+-- name: ClearPostReadStatesUntil :exec
+DELETE FROM post_read_states
+USING posts, feed_read_marks
+WHERE post_read_states.post_id = posts.id
+AND feed_read_marks.user_id = post_read_states.user_id
+AND feed_read_marks.feed_id = posts.feed_id
+AND post_read_states.user_id = sqlc.arg(user_id)
+AND COALESCE(posts.published_at, posts.created_at) <= sqlc.arg(read_until)::TIMESTAMP
+AND (sqlc.narg(feed_id)::UUID IS NULL OR posts.feed_id = sqlc.narg(feed_id)::UUID);
+
+-- name: GetUnreadCountsForUser :many
+SELECT feed_follows.feed_id, feed_follows.folder_id, feed_follows.hide_from_timeline, COUNT(posts.id) FILTER (
+    WHERE NOT COALESCE(
+        post_read_states.read,
+        COALESCE(posts.published_at, posts.created_at) <= feed_read_marks.read_until,
+        FALSE
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = feed_follows.user_id AND hidden_posts.post_id = posts.id
+    )
+) AS unread
+FROM feed_follows
+LEFT JOIN posts ON posts.feed_id = feed_follows.feed_id
+LEFT JOIN feed_read_marks ON feed_read_marks.user_id = feed_follows.user_id AND feed_read_marks.feed_id = feed_follows.feed_id
+LEFT JOIN post_read_states ON post_read_states.user_id = feed_follows.user_id AND post_read_states.post_id = posts.id
+WHERE feed_follows.user_id = $1
+GROUP BY feed_follows.feed_id, feed_follows.folder_id, feed_follows.hide_from_timeline;
+
+-- name: GetPostReadForUser :one
+SELECT COALESCE(
+    (
+        SELECT post_read_states.read FROM post_read_states
+        WHERE post_read_states.user_id = $1 AND post_read_states.post_id = posts.id
+    ),
+    COALESCE(posts.published_at, posts.created_at) <= (
+        SELECT feed_read_marks.read_until FROM feed_read_marks
+        WHERE feed_read_marks.user_id = $1 AND feed_read_marks.feed_id = posts.feed_id
+    ),
+    FALSE
+)::BOOLEAN AS read
+FROM posts
+WHERE posts.id = $2;
+
+-- name: MarkPostReadIfUnset :exec
+INSERT INTO post_read_states (user_id, post_id, read, updated_at)
+VALUES ($1, $2, TRUE, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: RecordPostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
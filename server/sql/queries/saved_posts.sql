@@ -0,0 +1,45 @@
+-- name: SavePost :one
+INSERT INTO saved_posts (id, created_at, updated_at, user_id, post_id, feed_id, feed_name, title, url, description, published_at, note)
+SELECT $1, $2, $2, $3, posts.id, posts.feed_id, feeds.name, posts.title, posts.url, posts.description, posts.published_at, sqlc.narg(note)
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.id = sqlc.arg(post_id)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    note = CASE WHEN sqlc.arg(keep_note)::BOOLEAN THEN saved_posts.note ELSE EXCLUDED.note END
+RETURNING *;
+
+-- name: DeleteSavedPost :execrows
+DELETE FROM saved_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetSavedPostsForUser :many
+SELECT * FROM saved_posts
+WHERE user_id = $1
+AND (
+    sqlc.narg(query)::TEXT IS NULL
+    OR to_tsvector('english', title || ' ' || COALESCE(description, '') || ' ' || COALESCE(note, ''))
+        @@ to_tsquery('english', sqlc.narg(query)::TEXT)
+)
+AND (
+    sqlc.narg(cursor_time)::TIMESTAMP IS NULL
+    OR (created_at, id) < (sqlc.narg(cursor_time)::TIMESTAMP, sqlc.narg(cursor_id)::UUID)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+-- name: GetSavedPostForUser :one
+SELECT * FROM saved_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: SavePostIfUnsaved :exec
+INSERT INTO saved_posts (id, created_at, updated_at, user_id, post_id, feed_id, feed_name, title, url, description, published_at)
+SELECT $1, $2, $2, $3, posts.id, posts.feed_id, feeds.name, posts.title, posts.url, posts.description, posts.published_at
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.id = $4
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: DeleteSavedPostByID :execrows
+DELETE FROM saved_posts
+WHERE id = $1 AND user_id = $2;
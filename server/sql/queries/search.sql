@@ -0,0 +1,47 @@
+-- name: SearchPosts :many
+SELECT posts.*, feeds.name AS feed_name, results.rank,
+    ts_headline('english', posts.title, to_tsquery('english', sqlc.arg(query)),
+        'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS title_highlight,
+    ts_headline('english', regexp_replace(COALESCE(posts.description, ''), '<[^>]*>', ' ', 'g'), to_tsquery('english', sqlc.arg(query)),
+        'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2') AS snippet
+FROM (
+    SELECT posts.id, ts_rank_cd(post_search.search_vector, to_tsquery('english', sqlc.arg(query))) AS rank
+    FROM posts
+    JOIN post_search ON post_search.post_id = posts.id
+    JOIN feeds ON feeds.id = posts.feed_id
+    WHERE post_search.search_vector @@ to_tsquery('english', sqlc.arg(query))
+    AND feeds.deleted_at IS NULL
+    AND (sqlc.narg(feed_id)::UUID IS NULL OR posts.feed_id = sqlc.narg(feed_id)::UUID)
+    AND (sqlc.narg(since)::TIMESTAMP IS NULL OR COALESCE(posts.published_at, posts.created_at) >= sqlc.narg(since)::TIMESTAMP)
+    AND (sqlc.narg(until)::TIMESTAMP IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg(until)::TIMESTAMP)
+    AND (
+        EXISTS (
+            SELECT 1 FROM feed_follows
+            WHERE feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+        )
+        OR (
+            NOT sqlc.arg(followed_only)::BOOLEAN
+            AND (
+                feeds.user_id = sqlc.arg(user_id)
+                OR (
+                    feeds.kind <> 'newsletter'
+                    AND NOT EXISTS (
+                        SELECT 1 FROM feed_credentials WHERE feed_credentials.feed_id = feeds.id
+                    )
+                )
+            )
+        )
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM hidden_posts
+        WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = posts.id
+    )
+) results
+JOIN posts ON posts.id = results.id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE (
+    sqlc.narg(cursor_rank)::REAL IS NULL
+    OR (results.rank, results.id) < (sqlc.narg(cursor_rank)::REAL, sqlc.narg(cursor_id)::UUID)
+)
+ORDER BY results.rank DESC, results.id DESC
+LIMIT $1;
@@ -29,4 +29,24 @@ SELECT u.*
 FROM users u
 JOIN starred_feeds sf ON u.id = sf.user_id
 WHERE sf.feed_id = $1
-ORDER BY u.created_at;
\ No newline at end of file
+ORDER BY u.created_at;
+
+-- name: DeleteStarredFeedsForFeed :exec
+DELETE FROM starred_feeds
+WHERE feed_id = $1;
+
+-- name: StarFeedIfUnstarred :exec
+INSERT INTO starred_feeds (id, user_id, feed_id, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $4)
+ON CONFLICT (user_id, feed_id) DO NOTHING;
+
+-- name: DeleteOtherStarredFeedsForFeed :many
+DELETE FROM starred_feeds
+WHERE feed_id = $1 AND user_id <> $2
+RETURNING user_id;
+
+-- name: FeedHasStars :one
+SELECT EXISTS(
+    SELECT 1 FROM starred_feeds
+    WHERE feed_id = $1
+) AS has_stars;
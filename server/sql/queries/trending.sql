@@ -0,0 +1,53 @@
+-- name: GetTrendingCandidates :many
+SELECT posts.id, posts.feed_id,
+    COALESCE(post_clusters.cluster_id, posts.id)::UUID AS cluster_id,
+    COALESCE(posts.published_at, posts.created_at)::TIMESTAMP AS posted_at,
+    (SELECT COUNT(*) FROM post_reads pr WHERE pr.post_id = posts.id) AS reads,
+    (SELECT COUNT(*) FROM saved_posts sp WHERE sp.post_id = posts.id) AS saves,
+    (SELECT COUNT(*) FROM starred_feeds sf WHERE sf.feed_id = posts.feed_id) AS stars
+FROM posts
+JOIN feeds ON feeds.id = posts.feed_id
+LEFT JOIN post_clusters ON post_clusters.post_id = posts.id
+WHERE COALESCE(posts.published_at, posts.created_at) > $1
+AND feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+);
+
+-- name: UpsertTrendingPost :exec
+INSERT INTO trending_posts (post_id, score, reads, saves, stars, coverage, computed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (post_id) DO UPDATE
+SET score = EXCLUDED.score, reads = EXCLUDED.reads, saves = EXCLUDED.saves,
+    stars = EXCLUDED.stars, coverage = EXCLUDED.coverage, computed_at = EXCLUDED.computed_at;
+
+-- name: DeleteStaleTrendingPosts :exec
+DELETE FROM trending_posts
+WHERE computed_at < $1;
+
+-- name: GetTrendingPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.original_url, posts.author, posts.categories,
+    feeds.name AS feed_name,
+    trending_posts.score, trending_posts.reads, trending_posts.saves, trending_posts.stars, trending_posts.coverage
+FROM trending_posts
+JOIN posts ON posts.id = trending_posts.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE feeds.deleted_at IS NULL
+AND feeds.kind <> 'newsletter'
+AND NOT EXISTS (
+    SELECT 1 FROM feed_credentials fc WHERE fc.feed_id = feeds.id
+)
+AND NOT EXISTS (
+    SELECT 1 FROM hidden_posts
+    WHERE hidden_posts.user_id = sqlc.arg(user_id) AND hidden_posts.post_id = posts.id
+)
+AND (
+    sqlc.narg(category)::TEXT IS NULL
+    OR EXISTS (
+        SELECT 1 FROM unnest(posts.categories) AS category
+        WHERE lower(category) = lower(sqlc.narg(category)::TEXT)
+    )
+)
+ORDER BY trending_posts.score DESC, posts.id
+LIMIT $1;
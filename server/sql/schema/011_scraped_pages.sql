@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN kind TEXT NOT NULL DEFAULT 'rss';
+
+CREATE TABLE feed_selectors (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    item_selector TEXT NOT NULL,
+    title_selector TEXT NOT NULL,
+    link_selector TEXT NOT NULL,
+    date_selector TEXT,
+    summary_selector TEXT
+);
+
+-- +goose Down
+DROP TABLE feed_selectors;
+ALTER TABLE feeds DROP COLUMN kind;
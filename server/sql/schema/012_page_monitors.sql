@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE page_monitors (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    selector TEXT,
+    content TEXT,
+    content_hash TEXT,
+    changed_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE page_monitors;
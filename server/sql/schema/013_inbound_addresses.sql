@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE inbound_addresses (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    token TEXT NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_inbound_addresses_user_id ON inbound_addresses(user_id);
+
+-- +goose Down
+DROP TABLE inbound_addresses;
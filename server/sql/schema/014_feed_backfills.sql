@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE feed_backfills (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL,
+    next_url TEXT,
+    pages_fetched INTEGER NOT NULL DEFAULT 0,
+    max_pages INTEGER NOT NULL,
+    items_found INTEGER NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX idx_feed_backfills_status ON feed_backfills(status);
+
+-- +goose Down
+DROP TABLE feed_backfills;
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE feed_retention (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    max_age_days INTEGER,
+    max_items INTEGER
+);
+
+CREATE TABLE post_prunes (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    posts_removed INTEGER NOT NULL
+);
+
+CREATE INDEX idx_post_prunes_feed_id ON post_prunes(feed_id, created_at);
+
+-- Pruning walks a feed's posts newest first
+CREATE INDEX idx_posts_feed_id_published_at ON posts(feed_id, published_at DESC);
+
+-- +goose Down
+DROP INDEX idx_posts_feed_id_published_at;
+DROP TABLE post_prunes;
+DROP TABLE feed_retention;
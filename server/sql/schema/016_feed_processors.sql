@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE feed_processors (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    config JSONB NOT NULL
+);
+
+-- +goose Down
+DROP TABLE feed_processors;
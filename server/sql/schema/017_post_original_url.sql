@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN original_url TEXT;
+
+CREATE INDEX idx_posts_original_url ON posts(original_url);
+
+-- +goose Down
+DROP INDEX idx_posts_original_url;
+ALTER TABLE posts DROP COLUMN original_url;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE post_clusters (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    cluster_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    url_key TEXT NOT NULL,
+    title_shingles BIGINT[] NOT NULL
+);
+
+CREATE INDEX idx_post_clusters_cluster_id ON post_clusters(cluster_id);
+CREATE INDEX idx_post_clusters_url_key ON post_clusters(url_key);
+CREATE INDEX idx_post_clusters_title_shingles ON post_clusters USING GIN (title_shingles);
+
+-- +goose Down
+DROP TABLE post_clusters;
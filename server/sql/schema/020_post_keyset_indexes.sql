@@ -0,0 +1,11 @@
+-- +goose Up
+-- Timelines page on (published_at, id), falling back to created_at for
+-- posts without a publish date.
+CREATE INDEX idx_posts_sort_key ON posts ((COALESCE(published_at, created_at)) DESC, id DESC);
+CREATE INDEX idx_posts_feed_id_sort_key ON posts (feed_id, (COALESCE(published_at, created_at)) DESC, id DESC);
+DROP INDEX idx_posts_feed_id_published_at;
+
+-- +goose Down
+CREATE INDEX idx_posts_feed_id_published_at ON posts(feed_id, published_at DESC);
+DROP INDEX idx_posts_feed_id_sort_key;
+DROP INDEX idx_posts_sort_key;
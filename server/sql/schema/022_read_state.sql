@@ -0,0 +1,25 @@
+-- +goose Up
+-- A post is read when the user marked it so, or when it is no newer than its
+-- feed's read_until mark and not marked unread since. Marking a feed read
+-- moves its mark and drops the per-post rows it makes redundant, so
+-- post_read_states stays small.
+CREATE TABLE feed_read_marks (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    read_until TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, feed_id)
+);
+
+CREATE TABLE post_read_states (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    read BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+CREATE INDEX idx_post_read_states_post_id ON post_read_states(post_id);
+
+-- +goose Down
+DROP TABLE post_read_states;
+DROP TABLE feed_read_marks;
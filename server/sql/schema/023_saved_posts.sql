@@ -0,0 +1,23 @@
+-- +goose Up
+-- Saved posts copy what the reader needs from the post, so they outlive the
+-- post and its feed; post_id and feed_id go null when those are deleted.
+CREATE TABLE saved_posts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID REFERENCES posts(id) ON DELETE SET NULL,
+    feed_id UUID REFERENCES feeds(id) ON DELETE SET NULL,
+    feed_name TEXT NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT,
+    published_at TIMESTAMP,
+    note TEXT,
+    UNIQUE (user_id, post_id)
+);
+CREATE INDEX idx_saved_posts_user_id_created_at ON saved_posts(user_id, created_at DESC, id DESC);
+CREATE INDEX idx_saved_posts_post_id ON saved_posts(post_id);
+
+-- +goose Down
+DROP TABLE saved_posts;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE labels (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    color TEXT NOT NULL
+);
+CREATE UNIQUE INDEX idx_labels_user_id_name ON labels(user_id, lower(name));
+
+CREATE TABLE post_labels (
+    label_id UUID NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (label_id, post_id)
+);
+CREATE INDEX idx_post_labels_post_id ON post_labels(post_id);
+
+-- +goose Down
+DROP TABLE post_labels;
+DROP TABLE labels;
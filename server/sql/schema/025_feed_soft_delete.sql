@@ -0,0 +1,12 @@
+-- +goose Up
+-- Deleted feeds stay in the table, hidden from every listing, and their URL
+-- can be added again as a new feed.
+ALTER TABLE feeds ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE feeds DROP CONSTRAINT feeds_url_key;
+CREATE UNIQUE INDEX idx_feeds_url ON feeds(url) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX idx_feeds_url;
+DELETE FROM feeds WHERE deleted_at IS NOT NULL;
+ALTER TABLE feeds ADD CONSTRAINT feeds_url_key UNIQUE (url);
+ALTER TABLE feeds DROP COLUMN deleted_at;
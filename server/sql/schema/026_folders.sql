@@ -0,0 +1,25 @@
+-- +goose Up
+-- Folders nest one level: a folder's parent is always a top-level folder.
+-- Deleting a folder deletes its subfolders and moves their follows back to
+-- the top level.
+CREATE TABLE folders (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES folders(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_folders_user_id ON folders(user_id);
+CREATE INDEX idx_folders_parent_id ON folders(parent_id);
+
+ALTER TABLE feed_follows ADD COLUMN folder_id UUID REFERENCES folders(id) ON DELETE SET NULL;
+ALTER TABLE feed_follows ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_feed_follows_folder_id ON feed_follows(folder_id);
+
+-- +goose Down
+DROP INDEX idx_feed_follows_folder_id;
+ALTER TABLE feed_follows DROP COLUMN position;
+ALTER TABLE feed_follows DROP COLUMN folder_id;
+DROP TABLE folders;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Per-follow settings. title replaces the shared feed name for the follower;
+-- full_content off shows plain-text excerpts instead of the stored
+-- descriptions; sort_order applies when reading the feed on its own.
+ALTER TABLE feed_follows ADD COLUMN title TEXT;
+ALTER TABLE feed_follows ADD COLUMN hide_from_timeline BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE feed_follows ADD COLUMN full_content BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE feed_follows ADD COLUMN sort_order TEXT NOT NULL DEFAULT 'newest'
+    CHECK (sort_order IN ('newest', 'oldest'));
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN sort_order;
+ALTER TABLE feed_follows DROP COLUMN full_content;
+ALTER TABLE feed_follows DROP COLUMN hide_from_timeline;
+ALTER TABLE feed_follows DROP COLUMN title;
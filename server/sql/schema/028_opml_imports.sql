@@ -0,0 +1,32 @@
+-- +goose Up
+-- An OPML import is parsed up front into one item per subscription, which a
+-- background worker then creates or reuses and follows. folder and subfolder
+-- name the outlines an item was nested in.
+CREATE TABLE opml_imports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL
+);
+CREATE INDEX idx_opml_imports_user_id ON opml_imports(user_id);
+
+CREATE TABLE opml_import_items (
+    id UUID PRIMARY KEY,
+    import_id UUID NOT NULL REFERENCES opml_imports(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    title TEXT NOT NULL,
+    folder TEXT,
+    subfolder TEXT,
+    status TEXT NOT NULL,
+    feed_id UUID REFERENCES feeds(id) ON DELETE SET NULL,
+    error TEXT,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_opml_import_items_import_id ON opml_import_items(import_id, position);
+CREATE INDEX idx_opml_import_items_pending ON opml_import_items(status) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE opml_import_items;
+DROP TABLE opml_imports;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- The site a feed belongs to, from the channel link of its last successful
+-- fetch.
+ALTER TABLE feeds ADD COLUMN site_url TEXT;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN site_url;
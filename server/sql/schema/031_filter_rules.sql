@@ -0,0 +1,30 @@
+-- +goose Up
+-- conditions and actions are JSON arrays, see filter_rules.go. match_any
+-- makes a rule match when any condition does instead of all of them.
+CREATE TABLE filter_rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    match_any BOOLEAN NOT NULL DEFAULT FALSE,
+    conditions JSONB NOT NULL,
+    actions JSONB NOT NULL
+);
+CREATE INDEX idx_filter_rules_user_id ON filter_rules(user_id);
+
+-- Posts hidden from a user's timelines by a rule. Changing or deleting the
+-- rule brings them back.
+CREATE TABLE hidden_posts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    rule_id UUID NOT NULL REFERENCES filter_rules(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id, rule_id)
+);
+CREATE INDEX idx_hidden_posts_rule_id ON hidden_posts(rule_id);
+
+-- +goose Down
+DROP TABLE hidden_posts;
+DROP TABLE filter_rules;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- A published feed serves one of a user's post lists as RSS, Atom or JSON
+-- Feed to anyone holding its token. Deleting the row revokes the token.
+CREATE TABLE published_feeds (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('timeline', 'folder', 'label', 'saved')),
+    folder_id UUID REFERENCES folders(id) ON DELETE CASCADE,
+    label_id UUID REFERENCES labels(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_published_feeds_user_id ON published_feeds(user_id);
+
+-- +goose Down
+DROP TABLE published_feeds;
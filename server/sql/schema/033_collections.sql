@@ -0,0 +1,40 @@
+-- +goose Up
+-- A collection is a named list of posts or of feeds that its owner can share
+-- by slug. Public collections are listed, unlisted ones are reachable only
+-- through the slug and private ones only by the owner. Changing the slug
+-- revokes the old link. Shared views leave out items whose feed isn't public,
+-- so a collection can't leak a private or paywalled feed.
+CREATE TABLE collections (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    description TEXT,
+    kind TEXT NOT NULL CHECK (kind IN ('posts', 'feeds')),
+    visibility TEXT NOT NULL DEFAULT 'private' CHECK (visibility IN ('public', 'unlisted', 'private'))
+);
+CREATE INDEX idx_collections_user_id ON collections(user_id);
+CREATE INDEX idx_collections_public ON collections(updated_at DESC) WHERE visibility = 'public';
+
+CREATE TABLE collection_posts (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (collection_id, post_id)
+);
+
+CREATE TABLE collection_feeds (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (collection_id, feed_id)
+);
+
+-- +goose Down
+DROP TABLE collection_feeds;
+DROP TABLE collection_posts;
+DROP TABLE collections;
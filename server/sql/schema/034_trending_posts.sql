@@ -0,0 +1,16 @@
+-- +goose Up
+-- Scores written by the trending job, one row per story; see trending.go.
+-- Each run refreshes computed_at on the rows it keeps and drops the rest.
+CREATE TABLE trending_posts (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    reads INTEGER NOT NULL,
+    saves INTEGER NOT NULL,
+    stars INTEGER NOT NULL,
+    coverage INTEGER NOT NULL,
+    computed_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_trending_posts_score ON trending_posts(score DESC);
+
+-- +goose Down
+DROP TABLE trending_posts;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Exports mark starred feeds with category="/starred", and starred feeds the
+-- user doesn't follow with "/starred/unfollowed"; the import stars them again,
+-- following only the first.
+ALTER TABLE opml_import_items ADD COLUMN starred BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE opml_import_items ADD COLUMN star_only BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE opml_import_items DROP COLUMN star_only;
+ALTER TABLE opml_import_items DROP COLUMN starred;
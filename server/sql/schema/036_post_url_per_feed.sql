@@ -0,0 +1,14 @@
+-- +goose Up
+-- A post's URL is unique within its feed rather than across all feeds.
+-- Deleted feeds keep their posts, so a globally unique URL left a re-added
+-- feed unable to store anything its deleted predecessor had.
+ALTER TABLE posts DROP CONSTRAINT posts_url_key;
+ALTER TABLE posts ADD CONSTRAINT posts_feed_id_url_key UNIQUE (feed_id, url);
+
+-- +goose Down
+DELETE FROM posts
+USING posts earlier
+WHERE posts.url = earlier.url
+AND (posts.created_at, posts.id) > (earlier.created_at, earlier.id);
+ALTER TABLE posts DROP CONSTRAINT posts_feed_id_url_key;
+ALTER TABLE posts ADD CONSTRAINT posts_url_key UNIQUE (url);
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Reads a user asked for, one row per post the first time they marked it
+-- read. Trending counts these: post_read_states can't tell them from posts a
+-- filter rule marked read, and mark-all-read deletes its rows. Nothing is
+-- backfilled; trending only looks at recent posts, so it catches up within
+-- its window.
+CREATE TABLE post_reads (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    read_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+CREATE INDEX idx_post_reads_post_id ON post_reads(post_id);
+
+-- +goose Down
+DROP TABLE post_reads;
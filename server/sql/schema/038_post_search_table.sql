@@ -0,0 +1,43 @@
+-- +goose Up
+-- The search vector moves off posts into its own table, so the queries that
+-- read posts.* don't fetch it. A trigger keeps it in step with the title and
+-- description, as the generated column did.
+CREATE TABLE post_search (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    search_vector TSVECTOR NOT NULL
+);
+INSERT INTO post_search (post_id, search_vector)
+SELECT id, search_vector FROM posts;
+CREATE INDEX idx_post_search_vector ON post_search USING GIN (search_vector);
+
+-- +goose StatementBegin
+CREATE FUNCTION post_search_update() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO post_search (post_id, search_vector)
+    VALUES (
+        NEW.id,
+        setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A')
+        || setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'B')
+    )
+    ON CONFLICT (post_id) DO UPDATE SET search_vector = EXCLUDED.search_vector;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER posts_search_update
+AFTER INSERT OR UPDATE OF title, description ON posts
+FOR EACH ROW EXECUTE FUNCTION post_search_update();
+
+DROP INDEX idx_posts_search_vector;
+ALTER TABLE posts DROP COLUMN search_vector;
+
+-- +goose Down
+ALTER TABLE posts ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A')
+    || setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+CREATE INDEX idx_posts_search_vector ON posts USING GIN (search_vector);
+DROP TRIGGER posts_search_update ON posts;
+DROP FUNCTION post_search_update();
+DROP TABLE post_search;
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// trendingWindow is how old a post can be and still trend.
+	trendingWindow = 72 * time.Hour
+	// trendingSize is how many stories each run keeps.
+	trendingSize = 1000
+	// trendingGravity sets how fast scores decay with age, as in
+	// score = weight / (hours + 2)^gravity.
+	trendingGravity = 1.5
+)
+
+// Signal weights. A save says more than a read, and only reads users marked
+// themselves count; a star is on the whole feed, so it only lifts stories
+// that already have some interest.
+const (
+	trendingReadWeight     = 1.0
+	trendingSaveWeight     = 3.0
+	trendingStarWeight     = 0.5
+	trendingCoverageWeight = 2.0
+)
+
+// trendingStory is a story's score and the signals behind it. PostID is the
+// post that stands for the story, the most read and saved one.
+type trendingStory struct {
+	PostID   uuid.UUID
+	Score    float64
+	Reads    int
+	Saves    int
+	Stars    int
+	Coverage int
+}
+
+// startTrending periodically ranks recent posts in public feeds, so
+// GET /v1/trending only has to read the result.
+func startTrending(db *database.Queries, timeBetweenRuns time.Duration) {
+	log.Printf("Ranking trending posts every %s...", timeBetweenRuns)
+	ticker := time.NewTicker(timeBetweenRuns)
+
+	for ; ; <-ticker.C {
+		now := time.Now().UTC()
+		candidates, err := db.GetTrendingCandidates(context.Background(), now.Add(-trendingWindow))
+		if err != nil {
+			log.Println("Couldn't get trending candidates", err)
+			continue
+		}
+		stories := rankTrending(candidates, now)
+		if len(stories) > trendingSize {
+			stories = stories[:trendingSize]
+		}
+		failed := false
+		for _, story := range stories {
+			err := db.UpsertTrendingPost(context.Background(), database.UpsertTrendingPostParams{
+				PostID:     story.PostID,
+				Score:      story.Score,
+				Reads:      int32(story.Reads),
+				Saves:      int32(story.Saves),
+				Stars:      int32(story.Stars),
+				Coverage:   int32(story.Coverage),
+				ComputedAt: now,
+			})
+			if err != nil {
+				log.Printf("Couldn't store trending post %s: %v", story.PostID, err)
+				failed = true
+			}
+		}
+		// Keep the previous ranking around if this one didn't fully land.
+		if failed {
+			continue
+		}
+		if err := db.DeleteStaleTrendingPosts(context.Background(), now); err != nil {
+			log.Println("Couldn't drop stale trending posts", err)
+		}
+	}
+}
+
+// rankTrending groups candidates into stories by cluster and returns the
+// stories with any reads, saves or cross-feed coverage, best first. Reads and
+// saves add up across a story's posts; coverage counts its distinct feeds.
+func rankTrending(candidates []database.GetTrendingCandidatesRow, now time.Time) []trendingStory {
+	type story struct {
+		trendingStory
+		postedAt  time.Time
+		bestPosts int64
+		feeds     map[uuid.UUID]bool
+		feedStars map[uuid.UUID]int
+	}
+	stories := map[uuid.UUID]*story{}
+	var order []uuid.UUID
+	for _, c := range candidates {
+		s, ok := stories[c.ClusterID]
+		if !ok {
+			s = &story{
+				trendingStory: trendingStory{PostID: c.ID},
+				postedAt:      c.PostedAt,
+				bestPosts:     -1,
+				feeds:         map[uuid.UUID]bool{},
+				feedStars:     map[uuid.UUID]int{},
+			}
+			stories[c.ClusterID] = s
+			order = append(order, c.ClusterID)
+		}
+		s.Reads += int(c.Reads)
+		s.Saves += int(c.Saves)
+		s.feeds[c.FeedID] = true
+		s.feedStars[c.FeedID] = int(c.Stars)
+		if c.PostedAt.Before(s.postedAt) {
+			s.postedAt = c.PostedAt
+		}
+		if interest := c.Reads + c.Saves; interest > s.bestPosts {
+			s.bestPosts = interest
+			s.PostID = c.ID
+		}
+	}
+
+	var ranked []trendingStory
+	for _, id := range order {
+		s := stories[id]
+		s.Coverage = len(s.feeds)
+		for _, stars := range s.feedStars {
+			s.Stars = max(s.Stars, stars)
+		}
+		if s.Reads == 0 && s.Saves == 0 && s.Coverage < 2 {
+			continue
+		}
+		s.Score = trendingScore(s.Reads, s.Saves, s.Stars, s.Coverage, now.Sub(s.postedAt))
+		ranked = append(ranked, s.trendingStory)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked
+}
+
+// trendingScore weighs a story's signals and decays them with its age.
+func trendingScore(reads, saves, stars, coverage int, age time.Duration) float64 {
+	weight := trendingReadWeight*float64(reads) +
+		trendingSaveWeight*float64(saves) +
+		trendingStarWeight*float64(stars) +
+		trendingCoverageWeight*float64(coverage-1)
+	hours := math.Max(age.Hours(), 0)
+	return weight / math.Pow(hours+2, trendingGravity)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sreenesh123/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestTrendingScoreDecays(t *testing.T) {
+	fresh := trendingScore(10, 2, 0, 1, time.Hour)
+	old := trendingScore(10, 2, 0, 1, 24*time.Hour)
+	if fresh <= old {
+		t.Errorf("expected a fresh story to outscore an old one, got %v <= %v", fresh, old)
+	}
+	if saved, read := trendingScore(0, 1, 0, 1, time.Hour), trendingScore(1, 0, 0, 1, time.Hour); saved <= read {
+		t.Errorf("expected a save to weigh more than a read, got %v <= %v", saved, read)
+	}
+}
+
+func TestRankTrending(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	story, quiet, popular := uuid.New(), uuid.New(), uuid.New()
+	feedA, feedB := uuid.New(), uuid.New()
+	storyPostA, storyPostB := uuid.New(), uuid.New()
+
+	candidates := []database.GetTrendingCandidatesRow{
+		// One story carried by two feeds; the second copy was read more.
+		{ID: storyPostA, FeedID: feedA, ClusterID: story, PostedAt: now.Add(-3 * time.Hour), Reads: 1, Stars: 4},
+		{ID: storyPostB, FeedID: feedB, ClusterID: story, PostedAt: now.Add(-2 * time.Hour), Reads: 3, Saves: 1},
+		// Nobody read this one, and stars on its feed alone don't make it trend.
+		{ID: quiet, FeedID: feedA, ClusterID: quiet, PostedAt: now.Add(-time.Hour), Stars: 4},
+		{ID: popular, FeedID: feedB, ClusterID: popular, PostedAt: now.Add(-time.Hour), Reads: 1},
+	}
+
+	ranked := rankTrending(candidates, now)
+	if len(ranked) != 2 {
+		t.Fatalf("expected 2 stories, got %d: %+v", len(ranked), ranked)
+	}
+	top := ranked[0]
+	if top.PostID != storyPostB {
+		t.Errorf("expected the most read copy to stand for the story, got %s", top.PostID)
+	}
+	if top.Reads != 4 || top.Saves != 1 || top.Coverage != 2 || top.Stars != 4 {
+		t.Errorf("unexpected signals %+v", top)
+	}
+	want := trendingScore(4, 1, 4, 2, 3*time.Hour)
+	if top.Score != want {
+		t.Errorf("expected the story to be aged from its first post: got %v, want %v", top.Score, want)
+	}
+	if ranked[1].PostID != popular {
+		t.Errorf("expected %s second, got %s", popular, ranked[1].PostID)
+	}
+}
+
+func TestHandlerTrendingGetLeavesOutHiddenPosts(t *testing.T) {
+	user := database.User{ID: uuid.New()}
+	cfg, db := newTestConfig(t)
+	db.returns("GetTrendingPosts")
+	db.returns("GetFeedFollowsForUser")
+	rec := serveAuthed(cfg.handlerTrendingGet, user, http.MethodGet, "/?category=go", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	calls := db.called("GetTrendingPosts")
+	if len(calls) != 1 || calls[0].Args[1] != "go" || argUUID(t, calls[0].Args[2]) != user.ID {
+		t.Fatalf("expected the list for %s, got %+v", user.ID, calls)
+	}
+	if !strings.Contains(calls[0].Query, "hidden_posts") {
+		t.Error("expected posts the user's filter rules hid to be left out")
+	}
+}